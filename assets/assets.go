@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"embed"
 	"image"
+	"io/fs"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -21,6 +22,10 @@ var (
 	BlockBreakSound = loadAudio("audio/breakblock.mp3")
 	SwooshSound     = loadAudio("audio/swoosh.mp3")
 	BackgroundMusic = loadAudio("audio/background_music.mp3")
+
+	// Puzzles holds the built-in puzzle levels, one JSON file each, named
+	// so they sort in the order they are meant to be played.
+	Puzzles = subdir("puzzles")
 )
 
 func loadImage(filePath string) *ebiten.Image {
@@ -45,3 +50,11 @@ func loadAudio(filePath string) []byte {
 	}
 	return data
 }
+
+func subdir(dir string) fs.FS {
+	sub, err := fs.Sub(assets, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
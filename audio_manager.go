@@ -9,6 +9,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
 )
 
+// The volumes are defaults; the settings replace them through SetVolumes.
 const (
 	SampleRate            = 44100
 	BackgroundMusicVolume = 0.1
@@ -21,14 +22,22 @@ type AudioManager struct {
 	swooshPlayer          *audio.Player
 	backgroundMusicPlayer *audio.Player
 	musicPaused           bool
+	musicLoopRunning      bool
+	musicVolume           float64
+	effectsVolume         float64
 }
 
 func NewAudioManager() *AudioManager {
-	audioContext := audio.NewContext(SampleRate)
-	
+	// Ebiten allows only one audio context per process.
+	audioContext := audio.CurrentContext()
+	if audioContext == nil {
+		audioContext = audio.NewContext(SampleRate)
+	}
 
 	return &AudioManager{
-		audioContext: audioContext,
+		audioContext:  audioContext,
+		musicVolume:   BackgroundMusicVolume,
+		effectsVolume: SoundEffectVolume,
 	}
 }
 
@@ -66,11 +75,27 @@ func (am *AudioManager) Initialize() error {
 	}
 	am.backgroundMusicPlayer.SetBufferSize(100)
 
-	am.backgroundMusicPlayer.SetVolume(BackgroundMusicVolume)
+	am.SetVolumes(am.musicVolume, am.effectsVolume)
 
 	return nil
 }
 
+// SetVolumes sets the music and sound effect volumes, from 0 to 1, taking
+// effect at once on anything already playing.
+func (am *AudioManager) SetVolumes(music, effects float64) {
+	am.musicVolume = music
+	am.effectsVolume = effects
+	if am.backgroundMusicPlayer != nil {
+		am.backgroundMusicPlayer.SetVolume(music)
+	}
+	if am.blockBreakPlayer != nil {
+		am.blockBreakPlayer.SetVolume(effects)
+	}
+	if am.swooshPlayer != nil {
+		am.swooshPlayer.SetVolume(effects)
+	}
+}
+
 func (am *AudioManager) PlayBlockBreak() {
 	if am.blockBreakPlayer == nil {
 		return
@@ -91,6 +116,7 @@ func (am *AudioManager) CreateBlockBreakPlayer() *audio.Player {
 		return nil
 	}
 	player.SetBufferSize(100)
+	player.SetVolume(am.effectsVolume)
 
 	return player
 }
@@ -123,6 +149,11 @@ func (am *AudioManager) StartBackgroundMusic() {
 	am.musicPaused = false
 	am.backgroundMusicPlayer.Play()
 
+	if am.musicLoopRunning {
+		return
+	}
+	am.musicLoopRunning = true
+
 	go func() {
 		for {
 			if !am.backgroundMusicPlayer.IsPlaying() && !am.musicPaused {
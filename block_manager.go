@@ -2,40 +2,53 @@ package main
 
 import (
 	"math"
-	"math/rand"
 	"union/assets"
+	"union/engine"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-const (
-	WobbleDuration   = 0.8
-	WobbleIntensity  = 2.0
-	WobbleFrequency  = 8.0
-	StormDuration    = 1.2
-	StormIntensity   = 3.0
-	StormFrequency   = 12.0
-	SparkFrequency   = 15.0
-	FallSpeed        = 4.0
-	ArcSpeed         = 4.0
-	ArcHeight        = 3.0
-	MinArcScale      = 0.1
-	MaxRotation      = 360.0
-	WarningDuration  = 1.0
-	WarningIntensity = 3.0
-	WarningFrequency = 20.0
-)
+// BlockTuning sets how hard blocks shake while they wobble, while they are
+// caught in a storm and while a storm is about to throw. It only changes
+// looks; how long those animations last is part of the rules, in
+// engine.Timing.
+type BlockTuning struct {
+	WobbleIntensity  float64 `json:"wobbleIntensity"`
+	StormIntensity   float64 `json:"stormIntensity"`
+	WarningIntensity float64 `json:"warningIntensity"`
+	WarningFrequency float64 `json:"warningFrequency"`
+}
+
+func DefaultBlockTuning() BlockTuning {
+	return BlockTuning{
+		WobbleIntensity:  2.0,
+		StormIntensity:   3.0,
+		WarningIntensity: 3.0,
+		WarningFrequency: 20.0,
+	}
+}
 
 type BlockManager struct {
 	blockSize float64
+	// tuning is shared with the settings so changes show straight away.
+	tuning *BlockTuning
+	// timing is the game's, for drawing a wobble's progress.
+	timing engine.Timing
 }
 
-func NewBlockManager() *BlockManager {
+func NewBlockManager(tuning *BlockTuning) *BlockManager {
 	return &BlockManager{
 		blockSize: 16.0,
+		tuning:    tuning,
+		timing:    engine.DefaultTiming(),
 	}
 }
 
+// SetTiming matches the drawing to the timing the game is played with.
+func (bm *BlockManager) SetTiming(timing engine.Timing) {
+	bm.timing = timing
+}
+
 func (bm *BlockManager) GetScaledBlockSize(gameboardWidth, gameboardHeight int) float64 {
 	baseBlocksWide := 12.0
 	baseBlocksTall := 20.0
@@ -47,16 +60,6 @@ func (bm *BlockManager) GetScaledBlockSize(gameboardWidth, gameboardHeight int)
 	return blockSizeFromHeight
 }
 
-func (bm *BlockManager) GenerateRandomBlockType() BlockType {
-	r := rand.Float64()
-	if r < 0.4 {
-		return PositiveBlock
-	} else if r < 0.8 {
-		return NegativeBlock
-	}
-	return NeutralBlock
-}
-
 func (bm *BlockManager) GetBlockSprite(blockType BlockType) *ebiten.Image {
 	switch blockType {
 	case PositiveBlock:
@@ -80,8 +83,8 @@ func (bm *BlockManager) DrawBlock(screen *ebiten.Image, block Block, worldX, wor
 	op.GeoM.Scale(scaleX, scaleY)
 
 	if block.IsInStorm {
-		stormX := math.Sin(block.StormPhase) * StormIntensity
-		stormY := math.Cos(block.StormPhase*1.7) * StormIntensity * 0.3
+		stormX := math.Sin(block.StormPhase) * bm.tuning.StormIntensity
+		stormY := math.Cos(block.StormPhase*1.7) * bm.tuning.StormIntensity * 0.3
 
 		sparkOffset := math.Sin(block.SparkPhase) * 1.0
 		stormX += sparkOffset
@@ -99,12 +102,12 @@ func (bm *BlockManager) DrawBlock(screen *ebiten.Image, block Block, worldX, wor
 		}
 
 	} else if block.IsWobbling {
-		wobbleX := math.Sin(block.WobblePhase) * WobbleIntensity
-		wobbleY := math.Cos(block.WobblePhase*1.3) * WobbleIntensity * 0.5
+		wobbleX := math.Sin(block.WobblePhase) * bm.tuning.WobbleIntensity
+		wobbleY := math.Cos(block.WobblePhase*1.3) * bm.tuning.WobbleIntensity * 0.5
 
 		op.GeoM.Translate(worldX+wobbleX, worldY+wobbleY)
 
-		wobbleProgress := block.WobbleTime / WobbleDuration
+		wobbleProgress := float64(block.WobbleTicks) / float64(bm.timing.WobbleTicks)
 		alpha := 1.0 - wobbleProgress*0.3
 		op.ColorScale.Scale(1, 1, 1, float32(alpha))
 	} else {
@@ -164,8 +167,8 @@ func (bm *BlockManager) DrawBlockTransformed(screen *ebiten.Image, block Block,
 	op.GeoM.Scale(scaleX, scaleY)
 
 	if block.IsInStorm {
-		stormX := math.Sin(block.StormPhase) * StormIntensity
-		stormY := math.Cos(block.StormPhase*1.7) * StormIntensity * 0.3
+		stormX := math.Sin(block.StormPhase) * bm.tuning.StormIntensity
+		stormY := math.Cos(block.StormPhase*1.7) * bm.tuning.StormIntensity * 0.3
 
 		sparkOffset := math.Sin(block.SparkPhase) * 1.0
 		stormX += sparkOffset
@@ -181,12 +184,12 @@ func (bm *BlockManager) DrawBlockTransformed(screen *ebiten.Image, block Block,
 			op.ColorScale.Scale(float32(1.0-flickerIntensity*0.3), float32(1.0+flickerIntensity*0.5), float32(1.0+flickerIntensity), 1.0)
 		}
 	} else if block.IsWobbling {
-		wobbleX := math.Sin(block.WobblePhase) * WobbleIntensity
-		wobbleY := math.Cos(block.WobblePhase*1.3) * WobbleIntensity * 0.5
+		wobbleX := math.Sin(block.WobblePhase) * bm.tuning.WobbleIntensity
+		wobbleY := math.Cos(block.WobblePhase*1.3) * bm.tuning.WobbleIntensity * 0.5
 
 		op.GeoM.Translate(worldX+wobbleX+(blockSize*scale)/2, worldY+wobbleY+(blockSize*scale)/2)
 
-		wobbleProgress := block.WobbleTime / WobbleDuration
+		wobbleProgress := float64(block.WobbleTicks) / float64(bm.timing.WobbleTicks)
 		alpha := 1.0 - wobbleProgress*0.3
 		op.ColorScale.Scale(1, 1, 1, float32(alpha))
 	} else {
@@ -212,13 +215,13 @@ func (bm *BlockManager) DrawWarningSprite(screen *ebiten.Image, worldX, worldY,
 	scaleY := blockSize / float64(sprite.Bounds().Dy())
 	op.GeoM.Scale(scaleX, scaleY)
 
-	shakePhase := warningTime * WarningFrequency * 2 * math.Pi
-	shakeX := math.Sin(shakePhase) * WarningIntensity
-	shakeY := math.Cos(shakePhase*1.3) * WarningIntensity * 0.7
+	shakePhase := warningTime * bm.tuning.WarningFrequency * 2 * math.Pi
+	shakeX := math.Sin(shakePhase) * bm.tuning.WarningIntensity
+	shakeY := math.Cos(shakePhase*1.3) * bm.tuning.WarningIntensity * 0.7
 
-	wobblePhase := warningTime * WarningFrequency * 1.5 * math.Pi
-	wobbleX := math.Sin(wobblePhase) * WarningIntensity * 0.5
-	wobbleY := math.Cos(wobblePhase*0.8) * WarningIntensity * 0.3
+	wobblePhase := warningTime * bm.tuning.WarningFrequency * 1.5 * math.Pi
+	wobbleX := math.Sin(wobblePhase) * bm.tuning.WarningIntensity * 0.5
+	wobbleY := math.Cos(wobblePhase*0.8) * bm.tuning.WarningIntensity * 0.3
 
 	gameboardWidthInBlocks := int(float64(gameboardWidth) / blockSize)
 	boardCenter := gameboardWidthInBlocks / 2
@@ -249,8 +252,8 @@ func (bm *BlockManager) DrawPowSprite(screen *ebiten.Image, worldX, worldY, wobb
 	scaleX := blockSize / float64(sprite.Bounds().Dx())
 	scaleY := blockSize / float64(sprite.Bounds().Dy())
 
-	wobbleX := math.Sin(wobblePhase) * WobbleIntensity
-	wobbleY := math.Cos(wobblePhase*1.3) * WobbleIntensity * 0.5
+	wobbleX := math.Sin(wobblePhase) * bm.tuning.WobbleIntensity
+	wobbleY := math.Cos(wobblePhase*1.3) * bm.tuning.WobbleIntensity * 0.5
 
 	spriteWidth := float64(sprite.Bounds().Dx()) * scaleX
 	spriteHeight := float64(sprite.Bounds().Dy()) * scaleY
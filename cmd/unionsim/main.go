@@ -0,0 +1,197 @@
+// Command unionsim plays batches of Un-ion games with the reference bot, as
+// fast as they will run and without a window, and reports how they went.
+// It is for judging balance changes, such as the charge odds or the storm
+// timing, without playing hundreds of games by hand.
+//
+// Each game is played from its own seed, counting up from -seed, so any
+// game in a batch can be played again on its own. Per-game results go to
+// the output as CSV or JSON; a summary goes to standard error.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"union/engine"
+)
+
+func main() {
+	games := flag.Int("games", 100, "how many games to play")
+	seed := flag.Int64("seed", 1, "seed of the first game; later games count up from it")
+	minutes := flag.Float64("minutes", 30, "stop any game still going after this many minutes of play")
+	randomizer := flag.String("randomizer", string(engine.SevenBag), fmt.Sprintf("how pieces are dealt: one of %v", engine.RandomizerKinds))
+	charges := flag.String("charges", string(engine.IndependentCharges), fmt.Sprintf("how piece charges are rolled: one of %v", engine.ChargeKinds))
+	mode := flag.String("mode", string(engine.ClassicMode), fmt.Sprintf("what the games are played for: one of %v", engine.ModeKinds))
+	preview := flag.Int("preview", engine.DefaultConfig().Preview, fmt.Sprintf("how many upcoming pieces are dealt ahead (%d-%d)", engine.MinPreview, engine.MaxPreview))
+	weightsPath := flag.String("weights", "", "JSON file of bot weights to use in place of the defaults")
+	pace := flag.Int("pace", 1, "ticks the bot waits between presses")
+	format := flag.String("format", "csv", "output format: csv or json")
+	outPath := flag.String("out", "", "write results to this file instead of standard output")
+	workers := flag.Int("workers", runtime.NumCPU(), "how many games to play at once")
+	flag.Parse()
+
+	config := engine.DefaultConfig()
+	var err error
+	if config.Randomizer, err = engine.ParseRandomizer(*randomizer); err != nil {
+		log.Fatal(err)
+	}
+	if config.Charges, err = engine.ParseCharges(*charges); err != nil {
+		log.Fatal(err)
+	}
+	if config.Mode, err = engine.ParseMode(*mode); err != nil {
+		log.Fatal(err)
+	}
+	if *preview < engine.MinPreview || *preview > engine.MaxPreview {
+		log.Fatalf("preview must be between %d and %d", engine.MinPreview, engine.MaxPreview)
+	}
+	config.Preview = *preview
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("unknown format %q (want csv or json)", *format)
+	}
+	if *games < 0 || *pace < 0 || *minutes < 0 {
+		log.Fatalf("games, pace and minutes can't be negative")
+	}
+
+	weights := engine.DefaultBotWeights()
+	if *weightsPath != "" {
+		if weights, err = loadWeights(*weightsPath); err != nil {
+			log.Fatalf("Could not load weights %s: %v", *weightsPath, err)
+		}
+	}
+
+	maxTicks := int(*minutes * 60 * engine.TicksPerSecond)
+	results := simulate(*games, *seed, *workers, func(seed int64) engine.SimResult {
+		return engine.Simulate(seed, config, engine.NewBot(weights, *pace), maxTicks)
+	})
+	summary := engine.Summarize(results)
+
+	out := io.Writer(os.Stdout)
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if *format == "json" {
+		err = writeJSON(out, report{Config: config, Weights: weights, Summary: summary, Games: results})
+	} else {
+		err = writeCSV(out, results)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	printSummary(os.Stderr, summary)
+}
+
+// simulate plays games games from consecutive seeds across workers and
+// returns the results in seed order.
+func simulate(games int, seed int64, workers int, play func(seed int64) engine.SimResult) []engine.SimResult {
+	results := make([]engine.SimResult, games)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = play(seed + int64(i))
+			}
+		}()
+	}
+	for i := range games {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+func loadWeights(path string) (engine.BotWeights, error) {
+	weights := engine.DefaultBotWeights()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return weights, err
+	}
+	err = json.Unmarshal(data, &weights)
+	return weights, err
+}
+
+// report is the JSON output: what was run and how it went.
+type report struct {
+	Config  engine.Config      `json:"config"`
+	Weights engine.BotWeights  `json:"weights"`
+	Summary engine.SimSummary  `json:"summary"`
+	Games   []engine.SimResult `json:"games"`
+}
+
+func writeJSON(w io.Writer, r report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func writeCSV(w io.Writer, results []engine.SimResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"seed", "score", "level", "ticks", "minutes", "pieces", "reactions", "reactions_per_piece",
+		"cleared", "max_chain", "storms", "neutrals", "end",
+	})
+	for _, r := range results {
+		cw.Write([]string{
+			strconv.FormatInt(r.Seed, 10),
+			strconv.Itoa(r.Score),
+			strconv.Itoa(r.Level),
+			strconv.Itoa(r.Ticks),
+			strconv.FormatFloat(r.Minutes(), 'f', 2, 64),
+			strconv.Itoa(r.Pieces),
+			strconv.Itoa(r.Reactions),
+			strconv.FormatFloat(r.ReactionsPerPiece(), 'f', 3, 64),
+			strconv.Itoa(r.Cleared),
+			strconv.Itoa(r.MaxChain),
+			strconv.Itoa(r.Storms),
+			strconv.Itoa(r.Neutrals),
+			string(r.End),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func printSummary(w io.Writer, s engine.SimSummary) {
+	fmt.Fprintf(w, "%d games\n", s.Games)
+	fmt.Fprintf(w, "%-20s %10s %10s %10s %10s %10s %10s %10s\n", "", "min", "p25", "median", "p75", "p90", "max", "mean")
+	for _, row := range []struct {
+		label string
+		d     engine.Distribution
+	}{
+		{"score", s.Score},
+		{"minutes", s.Minutes},
+		{"pieces", s.Pieces},
+		{"reactions/piece", s.ReactionsPerPiece},
+	} {
+		d := row.d
+		fmt.Fprintf(w, "%-20s %10.2f %10.2f %10.2f %10.2f %10.2f %10.2f %10.2f\n",
+			row.label, d.Min, d.P25, d.Median, d.P75, d.P90, d.Max, d.Mean)
+	}
+	fmt.Fprintf(w, "storms per minute    %.2f\n", s.StormsPerMinute)
+	fmt.Fprintf(w, "neutrals per minute  %.2f\n", s.NeutralsPerMinute)
+
+	causes := make([]engine.EndCause, 0, len(s.Ends))
+	for cause := range s.Ends {
+		causes = append(causes, cause)
+	}
+	slices.Sort(causes)
+	for _, cause := range causes {
+		fmt.Fprintf(w, "ended by %-11s %d\n", cause, s.Ends[cause])
+	}
+}
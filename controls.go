@@ -0,0 +1,375 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is something the player can do, whatever key or button it is
+// bound to.
+type Action string
+
+const (
+	ActionMoveLeft  Action = "moveLeft"
+	ActionMoveRight Action = "moveRight"
+	ActionSoftDrop  Action = "softDrop"
+	ActionHardDrop  Action = "hardDrop"
+	ActionRotateCW  Action = "rotateCW"
+	ActionRotateCCW Action = "rotateCCW"
+	ActionRotate180 Action = "rotate180"
+	ActionHold      Action = "hold"
+	ActionPause     Action = "pause"
+	// ActionResign ends a paused game.
+	ActionResign Action = "resign"
+)
+
+// Actions lists every action in the order menus show them.
+var Actions = []Action{
+	ActionMoveLeft, ActionMoveRight, ActionSoftDrop, ActionHardDrop,
+	ActionRotateCW, ActionRotateCCW, ActionRotate180, ActionHold, ActionPause,
+	ActionResign,
+}
+
+var actionLabels = map[Action]string{
+	ActionMoveLeft:  "Move left",
+	ActionMoveRight: "Move right",
+	ActionSoftDrop:  "Soft drop",
+	ActionHardDrop:  "Hard drop",
+	ActionRotateCW:  "Rotate clockwise",
+	ActionRotateCCW: "Rotate counter-clockwise",
+	ActionRotate180: "Rotate 180",
+	ActionHold:      "Hold piece",
+	ActionPause:     "Pause",
+	ActionResign:    "Resign (while paused)",
+}
+
+func (a Action) Label() string {
+	return actionLabels[a]
+}
+
+// MaxBindings is how many bindings an action may have.
+const MaxBindings = 4
+
+// axisThreshold is how far a stick must be pushed to count as pressed.
+const axisThreshold = 0.5
+
+type BindingKind string
+
+const (
+	BindKey    BindingKind = "key"
+	BindButton BindingKind = "button"
+	BindAxis   BindingKind = "axis"
+)
+
+// Binding is a keyboard key, a standard gamepad button, or one direction of
+// a standard gamepad axis. Gamepad bindings answer to any connected pad
+// with the standard layout.
+type Binding struct {
+	Kind   BindingKind                  `json:"kind"`
+	Key    ebiten.Key                   `json:"key"`
+	Button ebiten.StandardGamepadButton `json:"button"`
+	Axis   ebiten.StandardGamepadAxis   `json:"axis"`
+	// Direction is which way along Axis counts: 1 or -1.
+	Direction int `json:"direction"`
+}
+
+func KeyBinding(key ebiten.Key) Binding {
+	return Binding{Kind: BindKey, Key: key}
+}
+
+func ButtonBinding(button ebiten.StandardGamepadButton) Binding {
+	return Binding{Kind: BindButton, Button: button}
+}
+
+func AxisBinding(axis ebiten.StandardGamepadAxis, direction int) Binding {
+	return Binding{Kind: BindAxis, Axis: axis, Direction: direction}
+}
+
+// Pressed reports whether the binding is held on the keyboard or any of
+// pads.
+func (b Binding) Pressed(pads []ebiten.GamepadID) bool {
+	switch b.Kind {
+	case BindKey:
+		return ebiten.IsKeyPressed(b.Key)
+	case BindButton:
+		for _, id := range pads {
+			if ebiten.IsStandardGamepadButtonPressed(id, b.Button) {
+				return true
+			}
+		}
+	case BindAxis:
+		for _, id := range pads {
+			if ebiten.StandardGamepadAxisValue(id, b.Axis)*float64(b.Direction) > axisThreshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// JustPressed reports whether the binding went down this tick. Axes have no
+// edge of their own and never report it.
+func (b Binding) JustPressed(pads []ebiten.GamepadID) bool {
+	switch b.Kind {
+	case BindKey:
+		return inpututil.IsKeyJustPressed(b.Key)
+	case BindButton:
+		for _, id := range pads {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, b.Button) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var buttonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "Pad A",
+	ebiten.StandardGamepadButtonRightRight:       "Pad B",
+	ebiten.StandardGamepadButtonRightLeft:        "Pad X",
+	ebiten.StandardGamepadButtonRightTop:         "Pad Y",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "Pad LB",
+	ebiten.StandardGamepadButtonFrontTopRight:    "Pad RB",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "Pad LT",
+	ebiten.StandardGamepadButtonFrontBottomRight: "Pad RT",
+	ebiten.StandardGamepadButtonCenterLeft:       "Pad Back",
+	ebiten.StandardGamepadButtonCenterRight:      "Pad Start",
+	ebiten.StandardGamepadButtonLeftStick:        "Left Stick Click",
+	ebiten.StandardGamepadButtonRightStick:       "Right Stick Click",
+	ebiten.StandardGamepadButtonLeftTop:          "D-Pad Up",
+	ebiten.StandardGamepadButtonLeftBottom:       "D-Pad Down",
+	ebiten.StandardGamepadButtonLeftLeft:         "D-Pad Left",
+	ebiten.StandardGamepadButtonLeftRight:        "D-Pad Right",
+	ebiten.StandardGamepadButtonCenterCenter:     "Pad Home",
+}
+
+var axisNames = map[ebiten.StandardGamepadAxis][2]string{
+	ebiten.StandardGamepadAxisLeftStickHorizontal:  {"Left Stick Left", "Left Stick Right"},
+	ebiten.StandardGamepadAxisLeftStickVertical:    {"Left Stick Up", "Left Stick Down"},
+	ebiten.StandardGamepadAxisRightStickHorizontal: {"Right Stick Left", "Right Stick Right"},
+	ebiten.StandardGamepadAxisRightStickVertical:   {"Right Stick Up", "Right Stick Down"},
+}
+
+func (b Binding) String() string {
+	switch b.Kind {
+	case BindKey:
+		return b.Key.String()
+	case BindButton:
+		if name, ok := buttonNames[b.Button]; ok {
+			return name
+		}
+		return fmt.Sprintf("Pad %d", b.Button)
+	case BindAxis:
+		names, ok := axisNames[b.Axis]
+		if !ok {
+			return fmt.Sprintf("Axis %d", b.Axis)
+		}
+		if b.Direction < 0 {
+			return names[0]
+		}
+		return names[1]
+	}
+	return "?"
+}
+
+// Controls maps each action to the bindings that trigger it.
+type Controls map[Action][]Binding
+
+func DefaultControls() Controls {
+	return Controls{
+		ActionMoveLeft: {
+			KeyBinding(ebiten.KeyA), KeyBinding(ebiten.KeyArrowLeft),
+			ButtonBinding(ebiten.StandardGamepadButtonLeftLeft),
+			AxisBinding(ebiten.StandardGamepadAxisLeftStickHorizontal, -1),
+		},
+		ActionMoveRight: {
+			KeyBinding(ebiten.KeyD), KeyBinding(ebiten.KeyArrowRight),
+			ButtonBinding(ebiten.StandardGamepadButtonLeftRight),
+			AxisBinding(ebiten.StandardGamepadAxisLeftStickHorizontal, 1),
+		},
+		ActionSoftDrop: {
+			KeyBinding(ebiten.KeyS), KeyBinding(ebiten.KeyArrowDown),
+			ButtonBinding(ebiten.StandardGamepadButtonLeftBottom),
+			AxisBinding(ebiten.StandardGamepadAxisLeftStickVertical, 1),
+		},
+		ActionHardDrop: {
+			KeyBinding(ebiten.KeyW), KeyBinding(ebiten.KeyArrowUp),
+			ButtonBinding(ebiten.StandardGamepadButtonLeftTop),
+		},
+		ActionRotateCW: {
+			KeyBinding(ebiten.KeySpace), KeyBinding(ebiten.KeyX),
+			ButtonBinding(ebiten.StandardGamepadButtonRightBottom),
+		},
+		ActionRotateCCW: {
+			KeyBinding(ebiten.KeyZ),
+			ButtonBinding(ebiten.StandardGamepadButtonRightRight),
+		},
+		ActionRotate180: {
+			KeyBinding(ebiten.KeyQ),
+			ButtonBinding(ebiten.StandardGamepadButtonRightTop),
+		},
+		ActionHold: {
+			KeyBinding(ebiten.KeyC), KeyBinding(ebiten.KeyShiftLeft),
+			ButtonBinding(ebiten.StandardGamepadButtonFrontTopLeft),
+			ButtonBinding(ebiten.StandardGamepadButtonFrontTopRight),
+		},
+		ActionPause: {
+			KeyBinding(ebiten.KeyP),
+			ButtonBinding(ebiten.StandardGamepadButtonCenterRight),
+		},
+		ActionResign: {
+			KeyBinding(ebiten.KeyEscape),
+			ButtonBinding(ebiten.StandardGamepadButtonCenterLeft),
+		},
+	}
+}
+
+// DefaultVersusControls returns the bindings for two players sharing a
+// keyboard: the left player on WASD with E, Q and R to rotate, the right
+// on the arrows with the keys by the right shift. Each keeps the usual
+// gamepad bindings, read only from their own pad.
+func DefaultVersusControls() [2]Controls {
+	keys := [2]map[Action][]ebiten.Key{
+		{
+			ActionMoveLeft:  {ebiten.KeyA},
+			ActionMoveRight: {ebiten.KeyD},
+			ActionSoftDrop:  {ebiten.KeyS},
+			ActionHardDrop:  {ebiten.KeyW},
+			ActionRotateCW:  {ebiten.KeyE},
+			ActionRotateCCW: {ebiten.KeyQ},
+			ActionRotate180: {ebiten.KeyR},
+			ActionHold:      {ebiten.KeyShiftLeft},
+			ActionPause:     {ebiten.KeyP},
+		},
+		{
+			ActionMoveLeft:  {ebiten.KeyArrowLeft},
+			ActionMoveRight: {ebiten.KeyArrowRight},
+			ActionSoftDrop:  {ebiten.KeyArrowDown},
+			ActionHardDrop:  {ebiten.KeyArrowUp},
+			ActionRotateCW:  {ebiten.KeyPeriod},
+			ActionRotateCCW: {ebiten.KeyComma},
+			ActionRotate180: {ebiten.KeySlash},
+			ActionHold:      {ebiten.KeyShiftRight},
+			ActionPause:     {ebiten.KeyP},
+		},
+	}
+	defaults := DefaultControls()
+	var sets [2]Controls
+	for player := range sets {
+		sets[player] = make(Controls)
+		for _, action := range Actions {
+			var bindings []Binding
+			for _, key := range keys[player][action] {
+				bindings = append(bindings, KeyBinding(key))
+			}
+			for _, b := range defaults[action] {
+				if b.Kind != BindKey {
+					bindings = append(bindings, b)
+				}
+			}
+			sets[player][action] = bindings
+		}
+	}
+	return sets
+}
+
+// Pressed reports whether any binding for action is held.
+func (c Controls) Pressed(action Action) bool {
+	return c.PressedOn(action, standardGamepads())
+}
+
+// PressedOn reports whether any binding for action is held, reading
+// gamepad bindings only from pads.
+func (c Controls) PressedOn(action Action, pads []ebiten.GamepadID) bool {
+	for _, b := range c[action] {
+		if b.Pressed(pads) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnyJustPressed reports whether a binding for any action went down this
+// tick, for menus that start on any key or button.
+func (c Controls) AnyJustPressed() bool {
+	pads := standardGamepads()
+	for _, bindings := range c {
+		for _, b := range bindings {
+			if b.JustPressed(pads) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Bind adds b to action, dropping the oldest binding if the action already
+// has MaxBindings. Binding something already bound does nothing.
+func (c Controls) Bind(action Action, b Binding) {
+	bindings := c[action]
+	if slices.Contains(bindings, b) {
+		return
+	}
+	if len(bindings) >= MaxBindings {
+		bindings = bindings[1:]
+	}
+	c[action] = append(slices.Clone(bindings), b)
+}
+
+// Describe lists action's bindings for menus and help text, keyboard first.
+func (c Controls) Describe(action Action) string {
+	var keys, pads []string
+	for _, b := range c[action] {
+		if b.Kind == BindKey {
+			keys = append(keys, b.String())
+		} else {
+			pads = append(pads, b.String())
+		}
+	}
+	names := append(keys, pads...)
+	if len(names) == 0 {
+		return "Unbound"
+	}
+	return strings.Join(names, " / ")
+}
+
+// DescribeKeys lists only the keyboard bindings for action.
+func (c Controls) DescribeKeys(action Action) string {
+	var keys []string
+	for _, b := range c[action] {
+		if b.Kind == BindKey {
+			keys = append(keys, b.String())
+		}
+	}
+	if len(keys) == 0 {
+		return "Unbound"
+	}
+	return strings.Join(keys, "/")
+}
+
+// standardGamepads returns the connected gamepads that have the standard
+// layout, the only ones bindings are defined for.
+func standardGamepads() []ebiten.GamepadID {
+	var pads []ebiten.GamepadID
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			pads = append(pads, id)
+		}
+	}
+	return pads
+}
+
+// playerGamepad returns the standard gamepad that belongs to a versus
+// player, the first connected for the left player and the second for the
+// right, or none if there aren't that many.
+func playerGamepad(player int) []ebiten.GamepadID {
+	pads := standardGamepads()
+	if player >= len(pads) {
+		return nil
+	}
+	return pads[player : player+1]
+}
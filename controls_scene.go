@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// ControlsScene lists every action with its bindings and lets the player
+// add and clear them. It is reached from the settings scene.
+type ControlsScene struct {
+	sceneManager *SceneManager
+	titleFont    *text.GoTextFace
+	itemFont     *text.GoTextFace
+	helpFont     *text.GoTextFace
+	// selected is an index into Actions, or one of the two rows after them.
+	selected int
+	// capturing is set while waiting for the key or button to bind.
+	capturing bool
+	// set is which bindings are being edited: an index into
+	// controlSetNames.
+	set int
+}
+
+// controlSetNames are the binding sets the scene edits, switched with Tab:
+// the single-player controls, then each versus player's.
+var controlSetNames = []string{"SOLO", "VERSUS PLAYER 1", "VERSUS PLAYER 2"}
+
+// controls returns the set being edited.
+func (cs *ControlsScene) controls() Controls {
+	settings := cs.sceneManager.settings
+	if cs.set == 0 {
+		return settings.Controls
+	}
+	return settings.VersusControls[cs.set-1]
+}
+
+// resetControls puts the set being edited back to its defaults.
+func (cs *ControlsScene) resetControls() {
+	settings := cs.sceneManager.settings
+	if cs.set == 0 {
+		settings.Controls = DefaultControls()
+		return
+	}
+	settings.VersusControls[cs.set-1] = DefaultVersusControls()[cs.set-1]
+}
+
+// The rows after the actions.
+const (
+	controlsRowReset = iota
+	controlsRowBack
+	controlsExtraRows
+)
+
+func (cs *ControlsScene) Update() error {
+	controls := cs.controls()
+
+	if cs.capturing {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			cs.capturing = false
+			return nil
+		}
+		if b, ok := captureBinding(); ok {
+			controls.Bind(Actions[cs.selected], b)
+			cs.capturing = false
+		}
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		cs.leave()
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		cs.set = (cs.set + 1) % len(controlSetNames)
+		return nil
+	}
+
+	rows := len(Actions) + controlsExtraRows
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		cs.selected = (cs.selected + rows - 1) % rows
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		cs.selected = (cs.selected + 1) % rows
+	}
+
+	action, isAction := cs.selectedAction()
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		switch {
+		case isAction:
+			cs.capturing = true
+		case cs.selected-len(Actions) == controlsRowReset:
+			cs.resetControls()
+		default:
+			cs.leave()
+		}
+	}
+	if isAction && (inpututil.IsKeyJustPressed(ebiten.KeyBackspace) || inpututil.IsKeyJustPressed(ebiten.KeyDelete)) {
+		// Kept as an empty entry, so the cleared action isn't given its
+		// defaults back when the settings are next loaded.
+		controls[action] = nil
+	}
+	return nil
+}
+
+func (cs *ControlsScene) selectedAction() (Action, bool) {
+	if cs.selected < len(Actions) {
+		return Actions[cs.selected], true
+	}
+	return "", false
+}
+
+// leave saves the bindings and returns to the settings scene.
+func (cs *ControlsScene) leave() {
+	if err := saveSettings(cs.sceneManager.settings); err != nil {
+		log.Printf("Warning: Could not save settings: %v", err)
+	}
+	cs.sceneManager.TransitionTo(SceneSettings)
+}
+
+// captureBinding returns the first key, gamepad button or stick direction
+// pressed this tick.
+func captureBinding() (Binding, bool) {
+	if keys := inpututil.AppendJustPressedKeys(nil); len(keys) > 0 {
+		return KeyBinding(keys[0]), true
+	}
+	for _, id := range standardGamepads() {
+		if buttons := inpututil.AppendJustPressedStandardGamepadButtons(id, nil); len(buttons) > 0 {
+			return ButtonBinding(buttons[0]), true
+		}
+		for axis := ebiten.StandardGamepadAxis(0); axis <= ebiten.StandardGamepadAxisMax; axis++ {
+			value := ebiten.StandardGamepadAxisValue(id, axis)
+			if math.Abs(value) > axisThreshold {
+				direction := 1
+				if value < 0 {
+					direction = -1
+				}
+				return AxisBinding(axis, direction), true
+			}
+		}
+	}
+	return Binding{}, false
+}
+
+func (cs *ControlsScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{10, 15, 25, 255})
+	w := screen.Bounds().Dx()
+	controls := cs.controls()
+
+	cs.drawCentred(screen, "CONTROLS", cs.titleFont, 40, color.RGBA{220, 220, 255, 255})
+	cs.drawCentred(screen, controlSetNames[cs.set], cs.helpFont, 85, color.RGBA{150, 150, 170, 255})
+
+	labelX := float64(w)/2 - 330
+	valueX := float64(w)/2 - 80
+	y := 120.0
+	normal := color.RGBA{180, 180, 200, 255}
+	highlight := color.RGBA{255, 255, 100, 255}
+
+	for i, action := range Actions {
+		clr := normal
+		if i == cs.selected {
+			clr = highlight
+			cs.drawText(screen, ">", cs.itemFont, labelX-20, y, clr)
+		}
+		cs.drawText(screen, action.Label(), cs.itemFont, labelX, y, clr)
+		bindings := controls.Describe(action)
+		if cs.capturing && i == cs.selected {
+			bindings = "Press a key or button..."
+		}
+		cs.drawText(screen, bindings, cs.helpFont, valueX, y+3, clr)
+		y += 30
+	}
+
+	y += 10
+	for i, label := range []string{"Reset to defaults", "Back"} {
+		clr := normal
+		if len(Actions)+i == cs.selected {
+			clr = highlight
+			cs.drawText(screen, ">", cs.itemFont, labelX-20, y, clr)
+		}
+		cs.drawText(screen, label, cs.itemFont, labelX, y, clr)
+		y += 30
+	}
+
+	hint := "Up/Down: choose   Enter: add binding   Backspace: clear   Tab: switch player   Escape: back"
+	if cs.capturing {
+		hint = "Escape: cancel"
+	}
+	cs.drawCentred(screen, hint, cs.helpFont, y+20, color.RGBA{150, 150, 170, 255})
+}
+
+func (cs *ControlsScene) drawCentred(screen *ebiten.Image, s string, font *text.GoTextFace, y float64, clr color.Color) {
+	bounds, _ := text.Measure(s, font, 0)
+	cs.drawText(screen, s, font, float64(screen.Bounds().Dx()-int(bounds))/2, y, clr)
+}
+
+func (cs *ControlsScene) drawText(screen *ebiten.Image, s string, font *text.GoTextFace, x, y float64, clr color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, font, op)
+}
+
+func (cs *ControlsScene) Layout(outerWidth, outerHeight int) (int, int) {
+	return outerWidth, outerHeight
+}
+
+func NewControlsScene(sm *SceneManager) *ControlsScene {
+	titleFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	titleFont := &text.GoTextFace{
+		Source: titleFontSource,
+		Size:   36,
+	}
+
+	itemFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	itemFont := &text.GoTextFace{
+		Source: itemFontSource,
+		Size:   18,
+	}
+
+	helpFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	helpFont := &text.GoTextFace{
+		Source: helpFontSource,
+		Size:   14,
+	}
+
+	return &ControlsScene{
+		sceneManager: sm,
+		titleFont:    titleFont,
+		itemFont:     itemFont,
+		helpFont:     helpFont,
+	}
+}
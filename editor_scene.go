@@ -0,0 +1,523 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"path/filepath"
+	"slices"
+	"unicode"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// The editor's layout: a strip for the level's name above the board, and
+// room for the controls below it.
+const (
+	editorHeaderHeight = 70
+	editorFooterHeight = 90
+)
+
+// hiddenRows are the rows at the top of the board a puzzle can't start
+// blocks in, as the pieces spawn there.
+const hiddenRows = 2
+
+// maxPuzzleNameLength keeps names short enough for the puzzle list.
+const maxPuzzleNameLength = 24
+
+// editorBrush is what painting a cell leaves there.
+type editorBrush struct {
+	name  string
+	block BlockType
+	erase bool
+}
+
+var editorBrushes = []editorBrush{
+	{name: "Positive", block: PositiveBlock},
+	{name: "Negative", block: NegativeBlock},
+	{name: "Neutral", block: NeutralBlock},
+	{name: "Erase", erase: true},
+}
+
+// chargeSymbols are the charges a sequence's cells cycle through.
+var chargeSymbols = []byte("+-0")
+
+// EditorScene builds puzzles: painting the starting stack onto the board,
+// making up the piece sequence, trying the level out and saving it among
+// the player's own levels.
+type EditorScene struct {
+	sceneManager *SceneManager
+	gameboard    *Gameboard
+	blockManager *BlockManager
+	renderer     *GameRenderer
+	titleFont    *text.GoTextFace
+	itemFont     *text.GoTextFace
+	helpFont     *text.GoTextFace
+
+	// The level being edited.
+	name   string
+	blocks []Block
+	pieces []engine.PuzzlePiece
+	moves  int
+
+	brush            int
+	cursorX, cursorY int
+	// selected is the piece of the sequence being edited.
+	selected int
+	// dirty marks changes made since the level was last saved or opened.
+	dirty bool
+	// leaving is set by an Escape that would lose changes, so a second one
+	// leaves anyway. replacing is the same for a save that would write over
+	// another level.
+	leaving   bool
+	replacing bool
+
+	renaming bool
+	newName  []rune
+
+	// opening lists the saved levels while one is being chosen to open.
+	opening     []*engine.Puzzle
+	openingPick int
+
+	message    string
+	messageErr bool
+}
+
+func (e *EditorScene) Update() error {
+	switch {
+	case e.renaming:
+		e.updateRename()
+		return nil
+	case e.opening != nil:
+		e.updateOpen()
+		return nil
+	}
+
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	if ctrl {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyS):
+			e.save()
+		case inpututil.IsKeyJustPressed(ebiten.KeyO):
+			e.startOpen()
+		case inpututil.IsKeyJustPressed(ebiten.KeyN):
+			e.load(newBlankPuzzle())
+			e.say("New level", false)
+		}
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if e.dirty && !e.leaving {
+			e.leaving = true
+			e.say("Unsaved changes - press Escape again to leave without saving", true)
+			return nil
+		}
+		e.sceneManager.StartPuzzleSelect()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		e.testPlay()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		e.renaming = true
+		e.newName = []rune(e.name)
+		return nil
+	}
+
+	e.updateBoard()
+	e.updateSequence()
+	return nil
+}
+
+// updateBoard paints with the mouse, the left button laying the brush and
+// the right erasing, or with Space at the keyboard cursor.
+func (e *EditorScene) updateBoard() {
+	for i, key := range []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4} {
+		if inpututil.IsKeyJustPressed(key) {
+			e.brush = i
+		}
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		e.cursorX = stepInt(e.cursorX, -1, 0, engine.DefaultWidth-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		e.cursorX = stepInt(e.cursorX, 1, 0, engine.DefaultWidth-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		e.cursorY = stepInt(e.cursorY, -1, hiddenRows, engine.DefaultHeight-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		e.cursorY = stepInt(e.cursorY, 1, hiddenRows, engine.DefaultHeight-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		e.paint(e.cursorX, e.cursorY, editorBrushes[e.brush])
+	}
+
+	mx, my := ebiten.CursorPosition()
+	if !e.gameboard.Contains(mx, my) {
+		return
+	}
+	blockSize := e.blockManager.GetScaledBlockSize(e.gameboard.Width, e.gameboard.Height)
+	x, y := e.gameboard.ToGridCoordinates(mx, my, blockSize)
+	if x < 0 || x >= engine.DefaultWidth || y < hiddenRows || y >= engine.DefaultHeight {
+		return
+	}
+	switch {
+	case ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft):
+		e.cursorX, e.cursorY = x, y
+		e.paint(x, y, editorBrushes[e.brush])
+	case ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight):
+		e.cursorX, e.cursorY = x, y
+		e.paint(x, y, editorBrush{erase: true})
+	}
+}
+
+// paint puts brush's block in the cell at x, y, or empties it.
+func (e *EditorScene) paint(x, y int, brush editorBrush) {
+	i := slices.IndexFunc(e.blocks, func(b Block) bool { return b.X == x && b.Y == y })
+	switch {
+	case brush.erase && i < 0:
+		return
+	case brush.erase:
+		e.blocks = slices.Delete(e.blocks, i, i+1)
+	case i >= 0 && e.blocks[i].BlockType == brush.block:
+		return
+	case i >= 0:
+		e.blocks[i].BlockType = brush.block
+	default:
+		e.blocks = append(e.blocks, Block{X: x, Y: y, BlockType: brush.block})
+	}
+	e.changed()
+}
+
+// updateSequence edits the pieces: choosing one, adding and removing them,
+// changing a piece's type or the charge of one of its cells, and setting
+// how many may be placed.
+func (e *EditorScene) updateSequence() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft):
+		e.selected = stepInt(e.selected, -1, 0, len(e.pieces)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketRight):
+		e.selected = stepInt(e.selected, 1, 0, len(e.pieces)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyA):
+		e.selected++
+		e.pieces = slices.Insert(e.pieces, e.selected, e.pieces[e.selected-1])
+		e.changed()
+	case inpututil.IsKeyJustPressed(ebiten.KeyX) && len(e.pieces) > 1:
+		e.pieces = slices.Delete(e.pieces, e.selected, e.selected+1)
+		e.selected = clampInt(e.selected, 0, len(e.pieces)-1)
+		e.moves = clampInt(e.moves, 0, len(e.pieces))
+		e.changed()
+	case inpututil.IsKeyJustPressed(ebiten.KeyT):
+		piece := &e.pieces[e.selected]
+		t := engine.IPiece
+		if p, err := piece.Piece(); err == nil {
+			t = cycle(engine.PieceTypes, p.Type, 1)
+		}
+		piece.Type = engine.PieceLetter(t)
+		e.changed()
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus):
+		e.moves = stepInt(e.moves, -1, 0, len(e.pieces))
+		e.changed()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual):
+		e.moves = stepInt(e.moves, 1, 0, len(e.pieces))
+		e.changed()
+	}
+
+	for i, key := range []ebiten.Key{ebiten.KeyQ, ebiten.KeyW, ebiten.KeyE, ebiten.KeyR} {
+		piece := &e.pieces[e.selected]
+		if inpututil.IsKeyJustPressed(key) && i < len(piece.Charges) {
+			charges := []byte(piece.Charges)
+			charges[i] = cycle(chargeSymbols, charges[i], 1)
+			piece.Charges = string(charges)
+			e.changed()
+		}
+	}
+}
+
+// updateRename takes typed characters for the level's name until Enter
+// keeps it or Escape drops it.
+func (e *EditorScene) updateRename() {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if unicode.IsPrint(r) && len(e.newName) < maxPuzzleNameLength {
+			e.newName = append(e.newName, r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(e.newName) > 0 {
+		e.newName = e.newName[:len(e.newName)-1]
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		e.renaming = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		e.renaming = false
+		if name := string(e.newName); name != e.name {
+			e.name = name
+			e.changed()
+		}
+	}
+}
+
+// startOpen lists the saved levels to choose one to open.
+func (e *EditorScene) startOpen() {
+	puzzles, err := loadUserPuzzles()
+	if err != nil {
+		log.Printf("Warning: Could not load every saved level: %v", err)
+	}
+	if len(puzzles) == 0 {
+		e.say("No saved levels to open", true)
+		return
+	}
+	e.opening = puzzles
+	e.openingPick = 0
+}
+
+func (e *EditorScene) updateOpen() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		e.opening = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		e.openingPick = stepInt(e.openingPick, -1, 0, len(e.opening)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		e.openingPick = stepInt(e.openingPick, 1, 0, len(e.opening)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		p := e.opening[e.openingPick]
+		e.opening = nil
+		e.load(p)
+		e.say("Opened "+p.Name, false)
+	}
+}
+
+// puzzle returns the level as edited so far, which may not be valid yet.
+func (e *EditorScene) puzzle() *engine.Puzzle {
+	p := &engine.Puzzle{
+		Name:   e.name,
+		Pieces: slices.Clone(e.pieces),
+		Moves:  e.moves,
+	}
+	p.SetBlocks(e.blocks)
+	return p
+}
+
+// load starts editing a copy of p.
+func (e *EditorScene) load(p *engine.Puzzle) {
+	e.name = p.Name
+	e.blocks = p.Blocks()
+	e.pieces = slices.Clone(p.Pieces)
+	e.moves = p.Moves
+	e.selected = 0
+	e.dirty = false
+	e.leaving = false
+	e.replacing = false
+}
+
+func (e *EditorScene) save() {
+	p := e.puzzle()
+	if err := p.Validate(); err != nil {
+		e.say("Can't save: "+err.Error(), true)
+		return
+	}
+	path, err := saveUserPuzzle(p, e.replacing)
+	if errors.Is(err, errPuzzleFileTaken) {
+		e.replacing = true
+		e.say(fmt.Sprintf("%s: %v - press Ctrl+S again to replace it", filepath.Base(path), err), true)
+		return
+	}
+	e.replacing = false
+	if err != nil {
+		log.Printf("Warning: Could not save level: %v", err)
+		e.say("Could not save: "+err.Error(), true)
+		return
+	}
+	e.dirty = false
+	e.say("Saved to "+path, false)
+}
+
+// testPlay plays the level as it stands, coming back here when it ends.
+func (e *EditorScene) testPlay() {
+	p := e.puzzle()
+	if err := p.Validate(); err != nil {
+		e.say("Can't play: "+err.Error(), true)
+		return
+	}
+	e.sceneManager.TryPuzzle(p)
+}
+
+func (e *EditorScene) changed() {
+	e.dirty = true
+	e.leaving = false
+	e.replacing = false
+	e.message = ""
+}
+
+// say shows message under the board until something else replaces it.
+func (e *EditorScene) say(message string, isErr bool) {
+	e.message = message
+	e.messageErr = isErr
+}
+
+func (e *EditorScene) Draw(screen *ebiten.Image) {
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	e.renderer.Render(screen, e.blocks, nil)
+	e.drawBoardOverlay(screen)
+
+	title := e.name
+	if e.renaming {
+		title = string(e.newName) + "_"
+	} else if e.dirty {
+		title += " *"
+	}
+	drawCentredText(screen, "EDITOR: "+title, e.titleFont, e.gameboard.X+e.gameboard.Width/2, 15, color.RGBA{220, 220, 255, 255})
+
+	e.drawSequence(screen, e.gameboard.X+e.gameboard.Width+40, editorHeaderHeight)
+
+	footerY := e.gameboard.Y + e.gameboard.Height + 10
+	if e.message != "" {
+		clr := color.RGBA{150, 255, 150, 255}
+		if e.messageErr {
+			clr = color.RGBA{255, 120, 120, 255}
+		}
+		drawCentredText(screen, e.message, e.helpFont, w/2, footerY, clr)
+	}
+
+	help := []string{
+		"Mouse: paint, right button erases   Arrows + Space: paint at cursor   1-4: brush   F2: rename",
+		"[ ]: choose piece   A: add   X: remove   T: type   Q W E R: charges   - =: moves",
+		"Enter: test play   Ctrl+S: save   Ctrl+O: open   Ctrl+N: new   Escape: back",
+	}
+	if e.renaming {
+		help = []string{"Type a name, Enter to keep it, Escape to cancel"}
+	}
+	for i, line := range help {
+		drawCentredText(screen, line, e.helpFont, w/2, footerY+22+i*20, color.RGBA{150, 150, 170, 255})
+	}
+
+	if e.opening != nil {
+		e.drawOpen(screen, w, h)
+	}
+}
+
+// drawBoardOverlay shades the rows pieces spawn in and outlines the
+// keyboard cursor.
+func (e *EditorScene) drawBoardOverlay(screen *ebiten.Image) {
+	blockSize := e.blockManager.GetScaledBlockSize(e.gameboard.Width, e.gameboard.Height)
+	x, y := e.gameboard.ToScreenCoordinates(0, 0, blockSize)
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(blockSize*engine.DefaultWidth), float32(blockSize*hiddenRows), color.RGBA{0, 0, 0, 160}, false)
+
+	cx, cy := e.gameboard.ToScreenCoordinates(e.cursorX, e.cursorY, blockSize)
+	vector.StrokeRect(screen, float32(cx), float32(cy), float32(blockSize), float32(blockSize), 2, color.RGBA{255, 255, 100, 255}, false)
+}
+
+// drawSequence lists the brush, move limit and pieces beside the board,
+// scrolled to keep the selected piece in view.
+func (e *EditorScene) drawSequence(screen *ebiten.Image, x, y int) {
+	brush := editorBrushes[e.brush]
+	e.drawText(screen, "Brush: "+brush.name, x, y, color.RGBA{255, 200, 100, 255})
+	if !brush.erase {
+		size := float64(e.itemFont.Size)
+		e.blockManager.DrawBlock(screen, Block{BlockType: brush.block}, float64(x+170), float64(y+2), size)
+	}
+
+	moves := fmt.Sprintf("Moves: all %d", len(e.pieces))
+	if e.moves > 0 {
+		moves = fmt.Sprintf("Moves: %d of %d", e.moves, len(e.pieces))
+	}
+	e.drawText(screen, moves, x, y+30, color.RGBA{255, 200, 100, 255})
+
+	blockSize := e.blockManager.GetScaledBlockSize(e.gameboard.Width, e.gameboard.Height) * 0.5
+	rowHeight := int(blockSize*2) + 14
+	top := y + 70
+	visible := max((e.gameboard.Y+e.gameboard.Height-top)/rowHeight, 1)
+	first := max(e.selected-visible+1, 0)
+	for i := first; i < len(e.pieces) && i < first+visible; i++ {
+		rowY := top + (i-first)*rowHeight
+		clr := color.RGBA{180, 180, 200, 255}
+		if i == e.selected {
+			clr = color.RGBA{255, 255, 100, 255}
+			e.drawText(screen, ">", x-20, rowY, clr)
+		}
+		piece := e.pieces[i]
+		e.drawText(screen, fmt.Sprintf("%d. %s  %s", i+1, piece.Type, piece.Charges), x, rowY, clr)
+		p, err := piece.Piece()
+		if err != nil {
+			continue
+		}
+		for _, b := range p.Blocks {
+			bx := float64(x+160) + float64(b.X)*blockSize
+			by := float64(rowY) + float64(b.Y)*blockSize
+			e.blockManager.DrawBlock(screen, b, bx, by, blockSize)
+		}
+	}
+}
+
+// drawOpen shows the saved levels over the editor for choosing one.
+func (e *EditorScene) drawOpen(screen *ebiten.Image, w, h int) {
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), color.RGBA{5, 10, 20, 230}, false)
+	drawCentredText(screen, "OPEN LEVEL", e.titleFont, w/2, 60, color.RGBA{220, 220, 255, 255})
+	for i, p := range e.opening {
+		clr := color.RGBA{180, 180, 200, 255}
+		if i == e.openingPick {
+			clr = color.RGBA{255, 255, 100, 255}
+		}
+		drawCentredText(screen, p.Name, e.itemFont, w/2, 130+i*28, clr)
+	}
+	drawCentredText(screen, "Up/Down: choose   Enter: open   Escape: cancel", e.helpFont, w/2, 150+len(e.opening)*28, color.RGBA{150, 150, 170, 255})
+}
+
+func (e *EditorScene) drawText(screen *ebiten.Image, s string, x, y int, clr color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, e.itemFont, op)
+}
+
+// Layout keeps the board to the left, leaving room for the sequence.
+func (e *EditorScene) Layout(outerWidth, outerHeight int) (int, int) {
+	e.gameboard.UpdateScaleIn(image.Rect(0, editorHeaderHeight, outerWidth*3/5, outerHeight-editorFooterHeight))
+	return outerWidth, outerHeight
+}
+
+// newBlankPuzzle is where a new level starts: an empty board and a single
+// piece.
+func newBlankPuzzle() *engine.Puzzle {
+	return &engine.Puzzle{
+		Name:   "Untitled",
+		Pieces: []engine.PuzzlePiece{{Type: "I", Charges: "++--"}},
+	}
+}
+
+// NewEditorScene edits a copy of p, or a new level if p is nil.
+func NewEditorScene(sm *SceneManager, p *engine.Puzzle) *EditorScene {
+	titleFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	itemFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	helpFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	blockManager := NewBlockManager(&sm.settings.Blocks)
+	gameboard := NewGameboard(GameboardWidth, GameboardHeight)
+
+	e := &EditorScene{
+		sceneManager: sm,
+		gameboard:    gameboard,
+		blockManager: blockManager,
+		renderer:     NewGameRenderer(gameboard, blockManager),
+		titleFont:    &text.GoTextFace{Source: titleFontSource, Size: 32},
+		itemFont:     &text.GoTextFace{Source: itemFontSource, Size: 20},
+		helpFont:     &text.GoTextFace{Source: helpFontSource, Size: 16},
+		cursorX:      engine.DefaultWidth / 2,
+		cursorY:      engine.DefaultHeight - 1,
+	}
+	if p == nil {
+		p = newBlankPuzzle()
+	}
+	e.load(p)
+	return e
+}
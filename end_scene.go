@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"image/color"
+	"log"
+	"strings"
+	"unicode"
+	"union/engine"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -11,57 +15,144 @@ import (
 	"golang.org/x/image/font/gofont/goregular"
 )
 
+// defaultPlayerName is entered for players who leave the name blank.
+const defaultPlayerName = "PLAYER"
+
+// GameResult is how a finished game went, for the end screen.
+type GameResult struct {
+	Entry  engine.ScoreEntry
+	Config engine.Config
+	// End is why the game ended; it is empty for a replay that ran out of
+	// input first.
+	End engine.EndCause
+	// Cleared is how many blocks the game neutralized.
+	Cleared int
+	// Pieces is how many pieces were placed, and Best whether a solved
+	// puzzle took fewer of them than ever before.
+	Pieces int
+	Best   bool
+	// Trial marks a puzzle tried out from the editor. ProgressKey is what
+	// any other puzzle's solves are recorded under.
+	Trial       bool
+	ProgressKey string
+	// Stats is the tally kept since the game was started or continued.
+	Stats GameStats
+}
+
 type EndScene struct {
 	sceneManager *SceneManager
 	titleFont    *text.GoTextFace
 	subtitleFont *text.GoTextFace
-	finalScore   int
+	statsFont    *text.GoTextFace
+	result       GameResult
+	// table is the leaderboard table the game's mode keeps.
+	table string
+
+	// leaderboard is nil when the game doesn't count toward it, such as a
+	// replay, or when it couldn't be loaded.
+	leaderboard     *engine.Leaderboard
+	leaderboardView *LeaderboardView
+	enteringName    bool
+	name            []rune
+	rank            int
 }
 
 func (t *EndScene) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{25, 10, 10, 255})
 
-	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	titleY := screen.Bounds().Dy()/2 - 50
+	if t.leaderboard != nil {
+		titleY = 70
+	}
+	title, detail := t.headline()
+	t.drawCentred(screen, title, t.titleFont, titleY, color.RGBA{255, 100, 100, 255})
+	t.drawCentred(screen, detail, t.subtitleFont, titleY+50, color.RGBA{255, 200, 100, 255})
+	t.drawCentred(screen, t.statsLine(), t.statsFont, titleY+90, color.RGBA{200, 180, 160, 255})
 
-	titleText := "Game Over"
-	titleBounds, _ := text.Measure(titleText, t.titleFont, 0)
-	titleX := (w - int(titleBounds)) / 2
-	titleY := h/2 - 50
+	promptY := titleY + 130
+	switch {
+	case t.enteringName:
+		t.drawCentred(screen, "New high score! Enter your name:", t.subtitleFont, titleY+130, color.RGBA{150, 255, 150, 255})
+		t.drawCentred(screen, string(t.name)+"_", t.subtitleFont, titleY+170, color.RGBA{255, 255, 255, 255})
+		promptY = titleY + 220
+		t.drawCentred(screen, "Enter to save, Escape to skip", t.subtitleFont, promptY, color.RGBA{200, 150, 150, 255})
+		return
+	case t.leaderboard != nil:
+		t.leaderboardView.Draw(screen, t.leaderboard, t.table, t.rank, float64(titleY+120))
+		promptY = titleY + 160 + max(len(t.leaderboard.Entries(t.table)), 1)*22
+	}
 
+	prompt := "Press any key to restart"
+	if t.result.Config.Puzzle != nil {
+		prompt = "Enter to play again, Escape for puzzles"
+		if t.result.Trial {
+			prompt = "Enter to play again, Escape for the editor"
+		}
+	}
+	t.drawCentred(screen, prompt, t.subtitleFont, promptY, color.RGBA{200, 150, 150, 255})
+}
+
+// headline sums the game up in a title and a line under it, in the terms
+// of its mode.
+func (t *EndScene) headline() (title, detail string) {
+	entry := t.result.Entry
+	score := fmt.Sprintf("Final Score: %d", entry.Score)
+	switch mode := t.result.Config.Mode; {
+	case t.result.End == engine.Solved:
+		detail := fmt.Sprintf("Solved in %d moves", t.result.Pieces)
+		if t.result.Best {
+			detail += " - a new best!"
+		}
+		return "Puzzle Solved!", detail
+	case t.result.Config.Puzzle != nil:
+		return "Out of Moves", t.result.Config.Puzzle.Name + " is still charged"
+	case t.result.End == engine.GoalReached:
+		return "Sprint Complete!", "Time: " + engine.FormatTicks(entry.Ticks, true)
+	case mode == engine.SprintMode:
+		left := max(t.result.Config.SprintGoal-t.result.Cleared, 0)
+		return "Sprint Failed", fmt.Sprintf("%d blocks short of the goal", left)
+	case t.result.End == engine.TimeUp:
+		return "Time's Up!", score
+	case mode == engine.ZenMode:
+		return "Zen Session", fmt.Sprintf("%s in %s", score, engine.FormatTicks(entry.Ticks, false))
+	}
+	return "Game Over", score
+}
+
+// statsLine lists the game's stats in one line under the headline.
+func (t *EndScene) statsLine() string {
+	stats := t.result.Stats
+	line := fmt.Sprintf("%d pieces, %d hard drops, %d reactions", stats.PiecesPlaced, stats.HardDrops, stats.Reactions)
+	if stats.Reactions > 0 {
+		line += fmt.Sprintf(" (biggest %d, best chain %d, best combo %d)", stats.BiggestReaction, stats.BestChain, stats.BestCombo)
+	}
+	return line + fmt.Sprintf(", %d storms, %d storm assists, %d neutrals",
+		stats.StormsIgnited, stats.StormAssists, stats.NeutralsDropped)
+}
+
+func (t *EndScene) drawCentred(screen *ebiten.Image, s string, font *text.GoTextFace, y int, clr color.Color) {
+	w := screen.Bounds().Dx()
+	bounds, _ := text.Measure(s, font, 0)
 	op := &text.DrawOptions{}
-	op.GeoM.Translate(float64(titleX), float64(titleY))
-	op.ColorScale.ScaleWithColor(color.RGBA{255, 100, 100, 255})
-	text.Draw(screen, titleText, t.titleFont, op)
-
-	scoreText := fmt.Sprintf("Final Score: %d", t.finalScore)
-	scoreBounds, _ := text.Measure(scoreText, t.subtitleFont, 0)
-	scoreX := (w - int(scoreBounds)) / 2
-	scoreY := titleY + 50
-
-	op3 := &text.DrawOptions{}
-	op3.GeoM.Translate(float64(scoreX), float64(scoreY))
-	op3.ColorScale.ScaleWithColor(color.RGBA{255, 200, 100, 255})
-	text.Draw(screen, scoreText, t.subtitleFont, op3)
-
-	subtitleText := "Press any key to restart"
-	subtitleBounds, _ := text.Measure(subtitleText, t.subtitleFont, 0)
-	subtitleX := (w - int(subtitleBounds)) / 2
-	subtitleY := titleY + 100
-
-	op2 := &text.DrawOptions{}
-	op2.GeoM.Translate(float64(subtitleX), float64(subtitleY))
-	op2.ColorScale.ScaleWithColor(color.RGBA{200, 150, 150, 255})
-	text.Draw(screen, subtitleText, t.subtitleFont, op2)
+	op.GeoM.Translate(float64((w-int(bounds))/2), float64(y))
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, font, op)
 }
 
 func (t *EndScene) Update() error {
-	if ebiten.IsKeyPressed(ebiten.KeySpace) ||
-		ebiten.IsKeyPressed(ebiten.KeyEnter) ||
-		ebiten.IsKeyPressed(ebiten.KeyEscape) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyA) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyS) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyD) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyW) {
+	if t.enteringName {
+		t.updateNameEntry()
+		return nil
+	}
+	if t.result.Config.Puzzle != nil {
+		t.updatePuzzle()
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEnter) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEscape) ||
+		t.sceneManager.settings.Controls.AnyJustPressed() {
 		t.sceneManager.gameScene = NewGameScene(t.sceneManager)
 		t.sceneManager.TransitionTo(SceneGame)
 		return nil
@@ -75,11 +166,67 @@ func (t *EndScene) Update() error {
 	return nil
 }
 
+// updatePuzzle offers another go at the puzzle just played, or a
+// different one.
+func (t *EndScene) updatePuzzle() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		t.sceneManager.LeavePuzzle(t.result.Trial)
+		return
+	}
+	if !inpututil.IsKeyJustPressed(ebiten.KeyEnter) &&
+		!inpututil.IsKeyJustPressed(ebiten.KeySpace) &&
+		!t.sceneManager.settings.Controls.AnyJustPressed() {
+		return
+	}
+	if t.result.Trial {
+		t.sceneManager.TryPuzzle(t.result.Config.Puzzle)
+	} else {
+		t.sceneManager.StartPuzzle(t.result.Config.Puzzle, t.result.ProgressKey)
+	}
+}
+
+// updateNameEntry takes typed characters for the player's name until Enter
+// saves the score or Escape passes it up.
+func (t *EndScene) updateNameEntry() {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if unicode.IsPrint(r) && len(t.name) < engine.MaxNameLength {
+			t.name = append(t.name, unicode.ToUpper(r))
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(t.name) > 0 {
+		t.name = t.name[:len(t.name)-1]
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		t.enteringName = false
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		t.enteringName = false
+		t.recordScore()
+	}
+}
+
+func (t *EndScene) recordScore() {
+	name := strings.TrimSpace(string(t.name))
+	if name == "" {
+		name = defaultPlayerName
+	}
+	t.result.Entry.Name = name
+	t.leaderboard.LastName = name
+	t.rank = t.leaderboard.Add(t.table, t.result.Entry)
+	if err := saveLeaderboard(t.leaderboard); err != nil {
+		log.Printf("Warning: Could not save leaderboard: %v", err)
+	}
+}
+
 func (t *EndScene) Layout(outerWidth, outerHeight int) (int, int) {
 	return outerWidth, outerHeight
 }
 
-func NewEndScene(sm *SceneManager, finalScore int) *EndScene {
+// NewEndScene shows the result of a finished game. Ranked games that make
+// their mode's leaderboard ask for the player's name before showing it.
+func NewEndScene(sm *SceneManager, result GameResult, ranked bool) *EndScene {
 	titleFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
 	titleFont := &text.GoTextFace{
 		Source: titleFontSource,
@@ -91,11 +238,32 @@ func NewEndScene(sm *SceneManager, finalScore int) *EndScene {
 		Source: subtitleFontSource,
 		Size:   24,
 	}
+	statsFont := &text.GoTextFace{
+		Source: subtitleFontSource,
+		Size:   16,
+	}
 
-	return &EndScene{
-		sceneManager: sm,
-		titleFont:    titleFont,
-		subtitleFont: subtitleFont,
-		finalScore:   finalScore,
+	t := &EndScene{
+		sceneManager:    sm,
+		titleFont:       titleFont,
+		subtitleFont:    subtitleFont,
+		statsFont:       statsFont,
+		result:          result,
+		table:           engine.LeaderboardTable(result.Config),
+		leaderboardView: NewLeaderboardView(),
+		rank:            -1,
 	}
+
+	if ranked {
+		leaderboard, err := loadLeaderboard()
+		if err != nil {
+			log.Printf("Warning: Could not load leaderboard: %v", err)
+		} else {
+			t.leaderboard = leaderboard
+			t.enteringName = leaderboard.Qualifies(t.table, result.Entry)
+			t.name = []rune(leaderboard.LastName)
+		}
+	}
+
+	return t
 }
@@ -0,0 +1,157 @@
+package engine
+
+import "math"
+
+// Timing constants for the block animations. They live here rather than
+// with the renderer because reactions and storms wait on them. The
+// durations and speeds are only defaults; see Timing.
+const (
+	WobbleDuration  = 0.8
+	WobbleFrequency = 8.0
+	StormFrequency  = 12.0
+	SparkFrequency  = 15.0
+	FallSpeed       = 4.0
+	ArcSpeed        = 4.0
+	ArcHeight       = 3.0
+	MinArcScale     = 0.1
+	MaxRotation     = 360.0
+	WarningDuration = 1.0
+)
+
+func (b *Board) UpdateFallingBlocks() bool {
+	anyBlocksLanded := false
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		if block.IsFalling {
+			fallDistance := block.FallTargetY - block.FallStartY
+			if fallDistance > 0 {
+				block.FallProgress += b.Timing.fallStep(block)
+				if block.FallProgress >= 1.0 {
+					block.FallProgress = 1.0
+					block.Y = int(block.FallTargetY)
+					block.IsFalling = false
+					anyBlocksLanded = true
+				}
+			} else {
+				block.IsFalling = false
+				anyBlocksLanded = true
+			}
+		}
+	}
+	return anyBlocksLanded
+}
+
+// arcStep is how far an arc advances each tick.
+func (t Timing) arcStep() float64 {
+	return t.ArcSpeed * TickSeconds
+}
+
+// fallStep is how far a falling block's progress advances each tick. Blocks
+// fall at a constant speed, so longer drops advance more slowly.
+func (t Timing) fallStep(block *Block) float64 {
+	fallDistance := block.FallTargetY - block.FallStartY
+	if fallDistance <= 0 {
+		return 1.0
+	}
+	return t.FallSpeed * TickSeconds / fallDistance
+}
+
+// GetBlockRenderTransform returns the grid position, rotation and scale a
+// block should be drawn at, taking any arc or fall in progress into account.
+// alpha is how far the renderer is between the last tick and the next, in
+// [0, 1], so motion stays smooth when frames and ticks don't line up.
+func (b *Board) GetBlockRenderTransform(block *Block, alpha float64) (float64, float64, float64, float64) {
+	if block.IsArcing {
+		arcing := *block
+		arcing.ArcProgress = math.Min(block.ArcProgress+b.Timing.arcStep()*alpha, 1.0)
+		return GetBlockArcPosition(&arcing)
+	} else if block.IsFalling {
+		progress := math.Min(block.FallProgress+b.Timing.fallStep(block)*alpha, 1.0)
+		currentY := block.FallStartY + (block.FallTargetY-block.FallStartY)*progress
+		return float64(block.X), currentY, 0.0, 1.0
+	}
+	return float64(block.X), float64(block.Y), 0.0, 1.0
+}
+
+func StartBlockArc(block *Block, startX, startY, targetX, targetY float64) {
+	block.IsArcing = true
+	block.ArcStartX = startX
+	block.ArcStartY = startY
+	block.ArcTargetX = targetX
+	block.ArcTargetY = targetY
+	block.ArcProgress = 0.0
+	block.ArcRotation = 0.0
+	block.ArcScale = MinArcScale
+}
+
+func GetBlockArcPosition(block *Block) (float64, float64, float64, float64) {
+	if !block.IsArcing {
+		return float64(block.X), float64(block.Y), 0.0, 1.0
+	}
+	t := block.ArcProgress
+	currentX := block.ArcStartX + (block.ArcTargetX-block.ArcStartX)*t
+	linearY := block.ArcStartY + (block.ArcTargetY-block.ArcStartY)*t
+	arcOffset := ArcHeight * 4 * t * (1 - t)
+	currentY := linearY - arcOffset
+	currentRotation := block.ArcRotation + MaxRotation*t
+	currentScale := MinArcScale + (1.0-MinArcScale)*t
+	return currentX, currentY, currentRotation, currentScale
+}
+
+func (b *Board) UpdateArcingBlocks() bool {
+	anyBlocksFinishedArcing := false
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		if block.IsArcing {
+			block.ArcProgress += b.Timing.arcStep()
+			if block.ArcProgress >= 1.0 {
+				block.ArcProgress = 1.0
+				targetColumn := int(block.ArcTargetX)
+				finalY := b.Height - 1
+				for _, placedBlock := range b.placedBlocks {
+					if &placedBlock != block && placedBlock.X == targetColumn && placedBlock.Y < finalY {
+						finalY = placedBlock.Y - 1
+					}
+				}
+				if finalY < 0 {
+					finalY = 0
+				}
+				block.X = targetColumn
+				block.Y = finalY
+				block.IsArcing = false
+				block.ArcScale = 1.0
+				block.ArcRotation = 0.0
+				anyBlocksFinishedArcing = true
+			}
+		}
+	}
+	return anyBlocksFinishedArcing
+}
+
+func (b *Board) StartBlockFall(block *Block) {
+	targetY := block.Y
+	for newY := block.Y + 1; newY < b.Height; newY++ {
+		occupied := false
+		for _, placedBlock := range b.placedBlocks {
+			if &placedBlock == block {
+				continue
+			}
+			checkY := placedBlock.Y
+			if placedBlock.IsFalling {
+				checkY = int(placedBlock.FallTargetY)
+			}
+			if placedBlock.X == block.X && checkY == newY {
+				occupied = true
+				break
+			}
+		}
+		if occupied {
+			break
+		}
+		targetY = newY
+	}
+	block.IsFalling = true
+	block.FallStartY = float64(block.Y)
+	block.FallTargetY = float64(targetY)
+	block.FallProgress = 0
+}
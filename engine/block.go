@@ -0,0 +1,45 @@
+package engine
+
+type BlockType int
+
+const (
+	PositiveBlock BlockType = iota
+	NegativeBlock
+	NeutralBlock
+)
+
+// Charge returns the value a block contributes to a zero-sum reaction.
+func (bt BlockType) Charge() int {
+	switch bt {
+	case PositiveBlock:
+		return 1
+	case NegativeBlock:
+		return -1
+	}
+	return 0
+}
+
+type Block struct {
+	X, Y          int
+	BlockType     BlockType
+	IsWobbling    bool
+	WobbleTicks   int
+	WobblePhase   float64
+	ShowPowSprite bool
+	IsInStorm     bool
+	StormTicks    int
+	StormPhase    float64
+	SparkPhase    float64
+	IsFalling     bool
+	FallStartY    float64
+	FallTargetY   float64
+	FallProgress  float64
+	IsArcing      bool
+	ArcStartX     float64
+	ArcStartY     float64
+	ArcTargetX    float64
+	ArcTargetY    float64
+	ArcProgress   float64
+	ArcRotation   float64
+	ArcScale      float64
+}
@@ -0,0 +1,162 @@
+package engine
+
+import "math/rand/v2"
+
+const (
+	DefaultWidth  = 12
+	DefaultHeight = 20
+)
+
+// Board is the fixed-size playfield grid. It holds every settled block and
+// the storms burning in its columns, and knows nothing about how either is
+// drawn.
+type Board struct {
+	Width        int
+	Height       int
+	Timing       Timing
+	placedBlocks []Block
+	activeStorms map[int]*Storm
+	stormRand    *rand.Rand
+}
+
+// NewBoard creates an empty board with the default timing. Storm timing
+// and targeting draw from stormRand.
+func NewBoard(width, height int, stormRand *rand.Rand) *Board {
+	return &Board{
+		Width:        width,
+		Height:       height,
+		Timing:       DefaultTiming(),
+		placedBlocks: make([]Block, 0),
+		activeStorms: make(map[int]*Storm),
+		stormRand:    stormRand,
+	}
+}
+
+func (b *Board) IsValidPosition(piece *Piece, offsetX, offsetY int) bool {
+	for _, block := range piece.Blocks {
+		newX := piece.X + block.X + offsetX
+		newY := piece.Y + block.Y + offsetY
+		if newX < 0 || newX >= b.Width || newY >= b.Height {
+			return false
+		}
+		for _, placedBlock := range b.placedBlocks {
+			if placedBlock.X == newX && placedBlock.Y == newY {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (b *Board) IsValidPositionIgnoreNeutral(piece *Piece, offsetX, offsetY int) bool {
+	for _, block := range piece.Blocks {
+		newX := piece.X + block.X + offsetX
+		newY := piece.Y + block.Y + offsetY
+		if newX < 0 || newX >= b.Width || newY >= b.Height {
+			return false
+		}
+		for _, placedBlock := range b.placedBlocks {
+			if placedBlock.BlockType != NeutralBlock && placedBlock.X == newX && placedBlock.Y == newY {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (b *Board) PlacePiece(piece *Piece) {
+	if piece == nil {
+		return
+	}
+	for _, block := range piece.Blocks {
+		placedBlock := Block{
+			X:         piece.X + block.X,
+			Y:         piece.Y + block.Y,
+			BlockType: block.BlockType,
+		}
+		b.placedBlocks = append(b.placedBlocks, placedBlock)
+	}
+}
+
+func (b *Board) GetPlacedBlocks() []Block {
+	return b.placedBlocks
+}
+
+// SpawnColumn is the column new pieces enter the board at.
+func (b *Board) SpawnColumn() int {
+	return b.Width / 2
+}
+
+func (b *Board) SpawnPiece(pieceType PieceType, genBlockType func() BlockType) *Piece {
+	return NewPiece(pieceType, b.SpawnColumn(), 0, genBlockType)
+}
+
+// TryRotatePiece turns the piece, trying each of its wall kicks in order
+// until one fits. The piece is left alone if none do.
+func (b *Board) TryRotatePiece(piece *Piece, turn Turn) bool {
+	if piece == nil {
+		return false
+	}
+	rotated := piece.Copy()
+	RotatePiece(rotated, turn)
+	for _, k := range kicksFor(piece.Type, piece.Rotation, turn) {
+		if b.IsValidPosition(rotated, k.X, -k.Y) {
+			piece.Blocks = rotated.Blocks
+			piece.Rotation = rotated.Rotation
+			piece.X += k.X
+			piece.Y -= k.Y
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Board) TryMovePiece(piece *Piece, deltaX, deltaY int) bool {
+	if piece == nil {
+		return false
+	}
+	if b.IsValidPosition(piece, deltaX, deltaY) {
+		piece.X += deltaX
+		piece.Y += deltaY
+		return true
+	}
+	return false
+}
+
+func (b *Board) CalculateDropPosition(piece *Piece) *Piece {
+	if piece == nil {
+		return nil
+	}
+	shadowPiece := &Piece{
+		Type:     piece.Type,
+		X:        piece.X,
+		Y:        piece.Y,
+		Rotation: piece.Rotation,
+		Blocks:   make([]Block, len(piece.Blocks)),
+	}
+	for i, block := range piece.Blocks {
+		shadowPiece.Blocks[i] = Block{
+			X:         block.X,
+			Y:         block.Y,
+			BlockType: block.BlockType,
+		}
+	}
+	for b.IsValidPosition(shadowPiece, 0, 1) {
+		shadowPiece.Y++
+	}
+	return shadowPiece
+}
+
+func (b *Board) IsGameOver() bool {
+	for _, block := range b.placedBlocks {
+		if block.Y <= 0 && block.BlockType != NeutralBlock {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Board) AddNeutralBlock(block Block) *Block {
+	b.placedBlocks = append(b.placedBlocks, block)
+	return &b.placedBlocks[len(b.placedBlocks)-1]
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSpawnPiece(t *testing.T) {
+	b := NewBoard(DefaultWidth, DefaultHeight, nil)
+	for _, pieceType := range PieceTypes {
+		piece := b.SpawnPiece(pieceType, positive)
+		if piece.X != b.SpawnColumn() || piece.Y != 0 || piece.Rotation != 0 {
+			t.Errorf("piece %d spawned at (%d, %d) turned %d, want the spawn point unturned", pieceType, piece.X, piece.Y, piece.Rotation)
+		}
+		if len(piece.Blocks) != 4 || !b.IsValidPosition(piece, 0, 0) {
+			t.Errorf("piece %d spawned as %+v, which doesn't fit an empty board", pieceType, piece.Blocks)
+		}
+	}
+}
+
+func TestTryMovePiece(t *testing.T) {
+	b := parseBoard(t, `
+......
+......
+......
+......
+...+..
+`)
+	piece := NewPiece(OPiece, 2, 0, positive)
+
+	if !b.TryMovePiece(piece, -1, 0) || !b.TryMovePiece(piece, -1, 0) {
+		t.Fatal("couldn't move left across an empty board")
+	}
+	if b.TryMovePiece(piece, -1, 0) || piece.X != 0 {
+		t.Errorf("moved through the left wall to column %d", piece.X)
+	}
+
+	// Moving right, the O comes to rest on the block in column 3.
+	b.TryMovePiece(piece, 2, 0)
+	for b.TryMovePiece(piece, 0, 1) {
+	}
+	if piece.Y != 2 {
+		t.Errorf("piece stopped at row %d, want 2 on top of the block", piece.Y)
+	}
+	if landing := b.CalculateDropPosition(NewPiece(OPiece, 0, 0, positive)); landing.Y != 3 {
+		t.Errorf("drop position in an empty column is row %d, want the floor at 3", landing.Y)
+	}
+}
+
+func TestTryRotatePiece(t *testing.T) {
+	b := NewBoard(DefaultWidth, DefaultHeight, nil)
+	piece := NewPiece(TPiece, 4, 5, positive)
+	for i, turn := range []Turn{TurnCW, TurnCW, TurnCCW, Turn180} {
+		if !b.TryRotatePiece(piece, turn) {
+			t.Fatalf("turn %d refused in open space", i)
+		}
+	}
+	if piece.Rotation != 3 || piece.X != 4 || piece.Y != 5 {
+		t.Errorf("piece ended turned %d at (%d, %d), want 3 at (4, 5) with no kicks", piece.Rotation, piece.X, piece.Y)
+	}
+}
+
+// TestReactionPlaysOut follows a reaction from detection, through the
+// wobble, to the blocks above falling into the gap.
+func TestReactionPlaysOut(t *testing.T) {
+	b := parseBoard(t, `
+..0...
++0-+-.
+.++--.
+`)
+	if points := b.CheckForNewReactions(); points != 10 {
+		t.Fatalf("reaction scored %d, want 10 for four blocks", points)
+	}
+	// The neutral block splits the row above into runs too short to react.
+	for _, block := range b.placedBlocks {
+		if block.IsWobbling != (block.Y == 2) {
+			t.Errorf("block at (%d, %d) wobbling is %v", block.X, block.Y, block.IsWobbling)
+		}
+	}
+
+	for range WobbleTicks {
+		b.UpdateWobblingBlocks()
+	}
+	removed := b.RemoveFinishedWobblingBlocks()
+	if len(removed) != 4 {
+		t.Fatalf("removed %d blocks after the wobble, want 4", len(removed))
+	}
+	b.processBlockFalling()
+	settle(t, b)
+
+	want := "......\n..0...\n+0-+-.\n"
+	if got := drawBlocks(b, b.placedBlocks); got != want {
+		t.Errorf("board after the reaction:\n%swant:\n%s", got, want)
+	}
+}
+
+func TestStormIgnitesAndThrows(t *testing.T) {
+	b := parseBoard(t, `
+......
+.-....
+.-....
+.-....
+.-..+.
+`)
+	b.stormRand = rand.New(rand.NewPCG(1, 2))
+
+	if ignited := b.CheckForElectricalStorms(); len(ignited) != 1 || ignited[0] != 1 {
+		t.Fatalf("storms ignited in columns %v, want [1]", ignited)
+	}
+	var thrown []Block
+	for range StormMaxTicks {
+		b.UpdateElectricalStorms()
+		thrown = append(thrown, b.UpdateStormTimers()...)
+	}
+	if len(thrown) != 1 || thrown[0].BlockType != NeutralBlock || !thrown[0].IsArcing {
+		t.Errorf("storm threw %+v, want one arcing neutral block", thrown)
+	}
+
+	// Breaking the stack up puts the storm out.
+	b.placedBlocks[0].BlockType = PositiveBlock
+	b.ClearInvalidStorms()
+	if ignited := b.UpdateActiveStorms(); len(ignited) != 0 || len(b.activeStorms) != 0 {
+		t.Errorf("storm still burning in columns %v", b.activeStorms)
+	}
+}
@@ -0,0 +1,363 @@
+package engine
+
+import "math"
+
+// BotWeights sets how much the bot cares about each thing it looks at when
+// it judges a placement. Rewards add to a placement's score and penalties
+// take away from it, so every weight is positive.
+type BotWeights struct {
+	// Cleared rewards each block the placement neutralizes, cascades
+	// included, and Chain each reaction after the first.
+	Cleared float64 `json:"cleared"`
+	Chain   float64 `json:"chain"`
+	// Height penalizes the tallest column and Holes each empty cell with
+	// a block somewhere above it.
+	Height float64 `json:"height"`
+	Holes  float64 `json:"holes"`
+	// StormRisk penalizes same-charge columns as they grow toward the four
+	// it takes to start a storm.
+	StormRisk float64 `json:"stormRisk"`
+	// Imbalance penalizes charge left over in each run of touching blocks
+	// along a row; a run that sums to zero is one block from reacting.
+	Imbalance float64 `json:"imbalance"`
+}
+
+func DefaultBotWeights() BotWeights {
+	return BotWeights{
+		Cleared:   3,
+		Chain:     8,
+		Height:    1.5,
+		Holes:     4,
+		StormRisk: 6,
+		Imbalance: 0.75,
+	}
+}
+
+// botGiveUpTicks is how long the bot works a piece toward its target
+// before dropping it wherever it has got to.
+const botGiveUpTicks = 4 * TicksPerSecond
+
+// Bot is a reference Controller that plays one piece at a time. When a
+// piece arrives it tries every rotation and column for it, and for the
+// piece it could hold instead, scores where each would land, and then taps
+// its way to the best.
+type Bot struct {
+	Weights BotWeights
+	// Pace is how many ticks the bot lets go of the controls between
+	// presses. The game needs at least one to see each press; more makes
+	// the bot easier to watch.
+	Pace int
+
+	planned     bool
+	plannedFor  int
+	plannedHold bool
+	target      placement
+	wait        int
+	ticks       int
+}
+
+// placement is where the bot means to put the current piece.
+type placement struct {
+	hold     bool
+	rotation int
+	x        int
+	score    float64
+}
+
+func NewBot(weights BotWeights, pace int) *Bot {
+	return &Bot{Weights: weights, Pace: max(pace, 1)}
+}
+
+func (b *Bot) Input(view *View) Input {
+	if view.Current == nil {
+		b.planned = false
+		return Input{}
+	}
+	if !b.planned || view.Pieces != b.plannedFor || view.CanHold != b.plannedHold {
+		b.plan(view)
+	}
+
+	b.ticks++
+	if b.wait > 0 {
+		b.wait--
+		return Input{}
+	}
+	in := b.press(view.Current)
+	if in.HardDrop && b.ticks <= botGiveUpTicks && boardMoving(view) {
+		// Dropping onto blocks still on the move lands the piece where
+		// they were, not where they will be, so let gravity bring it down
+		// meanwhile.
+		return Input{}
+	}
+	b.wait = b.Pace
+	return in
+}
+
+// boardMoving reports whether any block in view is still falling, arcing
+// or reacting.
+func boardMoving(view *View) bool {
+	for _, block := range view.Blocks {
+		if block.IsFalling || block.IsArcing || block.IsWobbling {
+			return true
+		}
+	}
+	return false
+}
+
+// press returns the one control that takes piece a step closer to the
+// target.
+func (b *Bot) press(piece *Piece) Input {
+	target := b.target
+	switch {
+	case b.ticks > botGiveUpTicks:
+		return Input{HardDrop: true}
+	case target.hold:
+		return Input{Hold: true}
+	case piece.Rotation != target.rotation:
+		switch Turn(normalizeRotation(target.rotation - piece.Rotation)) {
+		case TurnCW:
+			return Input{Rotate: true}
+		case Turn180:
+			return Input{Rotate180: true}
+		default:
+			return Input{RotateCCW: true}
+		}
+	case piece.X < target.x:
+		return Input{Right: true}
+	case piece.X > target.x:
+		return Input{Left: true}
+	}
+	return Input{HardDrop: true}
+}
+
+// plan picks the target for the piece in view.
+func (b *Bot) plan(view *View) {
+	b.planned = true
+	b.plannedFor = view.Pieces
+	b.plannedHold = view.CanHold
+	b.ticks = 0
+
+	board := view.Board()
+	b.target = b.best(board, view.Current)
+
+	if !view.CanHold {
+		return
+	}
+	other := view.Hold
+	if other == nil && len(view.Queue) > 0 {
+		other = view.Queue[0]
+	}
+	if other == nil {
+		return
+	}
+	// The held piece comes out at the spawn point, so plan it from there.
+	other = other.Copy()
+	other.X, other.Y = board.SpawnColumn(), 0
+	if alt := b.best(board, other); alt.score > b.target.score {
+		b.target = placement{hold: true, score: alt.score}
+	}
+}
+
+// best scores every placement of piece and returns the highest.
+func (b *Bot) best(board *Board, piece *Piece) placement {
+	best := placement{rotation: piece.Rotation, x: piece.X, score: math.Inf(-1)}
+	for _, landed := range Placements(board, piece) {
+		if score := b.evaluate(board, landed); score > best.score {
+			best = placement{rotation: landed.Rotation, x: landed.X, score: score}
+		}
+	}
+	return best
+}
+
+// Placements returns everywhere piece can land by turning once where it is,
+// sliding along its row and dropping: one piece per rotation and column,
+// already at its landing row.
+func Placements(board *Board, piece *Piece) []*Piece {
+	var landed []*Piece
+	for _, turn := range []Turn{0, TurnCW, Turn180, TurnCCW} {
+		if turn != 0 && piece.Type == OPiece {
+			break
+		}
+		turned := piece.Copy()
+		if turn != 0 && !board.TryRotatePiece(turned, turn) {
+			continue
+		}
+		for board.TryMovePiece(turned, -1, 0) {
+		}
+		for {
+			landed = append(landed, board.CalculateDropPosition(turned))
+			if !board.TryMovePiece(turned, 1, 0) {
+				break
+			}
+		}
+	}
+	return landed
+}
+
+// evaluate scores the board left by locking landed, once it has settled.
+func (b *Bot) evaluate(board *Board, landed *Piece) float64 {
+	for _, block := range landed.Blocks {
+		if landed.Y+block.Y <= 0 {
+			return math.Inf(-1)
+		}
+	}
+	trial := NewBoard(board.Width, board.Height, nil)
+	trial.SetPlacedBlocks(board.placedBlocks)
+	trial.PlacePiece(landed)
+	trial.settle()
+	cleared, reactions := trial.resolveReactions()
+
+	w := b.Weights
+	score := w.Cleared*float64(cleared) + w.Chain*float64(max(reactions-1, 0))
+	height, holes := trial.stackShape()
+	score -= w.Height * float64(height)
+	score -= w.Holes * float64(holes)
+	score -= w.StormRisk * float64(trial.stormRisk())
+	score -= w.Imbalance * float64(trial.rowImbalance())
+	return score
+}
+
+// settle brings the board to where it will rest once the blocks already
+// reacting have gone. Their going drops every block above a gap, so the
+// board collapses whenever any are reacting or still falling.
+func (b *Board) settle() {
+	kept := b.placedBlocks[:0]
+	moving := false
+	for _, block := range b.placedBlocks {
+		moving = moving || block.IsWobbling || block.IsFalling
+		if !block.IsWobbling {
+			kept = append(kept, block)
+		}
+	}
+	b.placedBlocks = kept
+	if moving {
+		b.collapse()
+	}
+}
+
+// resolveReactions removes every reaction on the board at once, dropping
+// the blocks after each as the game would, and returns how many blocks were
+// neutralized and in how many reactions.
+func (b *Board) resolveReactions() (cleared, reactions int) {
+	for {
+		blocks := b.findBlocksToRemove()
+		if len(blocks) == 0 {
+			return cleared, reactions
+		}
+		cleared += len(blocks)
+		reactions++
+		b.removeBlocks(blocks)
+		b.collapse()
+	}
+}
+
+// collapse drops every block straight to the floor of its column, the
+// instant version of processBlockFalling.
+func (b *Board) collapse() {
+	columns := b.columns()
+	for x := range columns {
+		y := b.Height - 1
+		for row := b.Height - 1; row >= 0; row-- {
+			if i := columns[x][row]; i >= 0 {
+				b.placedBlocks[i].Y = y
+				y--
+			}
+		}
+	}
+}
+
+// columns indexes the board by cell: columns[x][y] is the index of the
+// block there, or -1.
+func (b *Board) columns() [][]int {
+	columns := make([][]int, b.Width)
+	for x := range columns {
+		columns[x] = make([]int, b.Height)
+		for y := range columns[x] {
+			columns[x][y] = -1
+		}
+	}
+	for i, block := range b.placedBlocks {
+		if block.X >= 0 && block.X < b.Width && block.Y >= 0 && block.Y < b.Height {
+			columns[block.X][block.Y] = i
+		}
+	}
+	return columns
+}
+
+// stackShape returns the height of the tallest column and the number of
+// empty cells covered by a block.
+func (b *Board) stackShape() (height, holes int) {
+	for _, column := range b.columns() {
+		covered := false
+		for y, i := range column {
+			switch {
+			case i >= 0 && !covered:
+				covered = true
+				height = max(height, b.Height-y)
+			case i < 0 && covered:
+				holes++
+			}
+		}
+	}
+	return height, holes
+}
+
+// stormRisk grows with the square of each run of three or more same-charge
+// blocks stacked in a column, so a run that is about to storm weighs far
+// more than one that has just begun.
+func (b *Board) stormRisk() int {
+	risk := 0
+	for _, column := range b.columns() {
+		run, last := 0, NeutralBlock
+		for _, i := range column {
+			kind := NeutralBlock
+			if i >= 0 {
+				kind = b.placedBlocks[i].BlockType
+			}
+			if kind != NeutralBlock && kind == last {
+				run++
+			} else {
+				risk += runRisk(run)
+				run = 1
+			}
+			last = kind
+		}
+		risk += runRisk(run)
+	}
+	return risk
+}
+
+func runRisk(run int) int {
+	if run < 3 {
+		return 0
+	}
+	return (run - 2) * (run - 2)
+}
+
+// rowImbalance adds up how far each run of touching charged blocks along a
+// row is from summing to zero.
+func (b *Board) rowImbalance() int {
+	columns := b.columns()
+	imbalance := 0
+	for y := 0; y < b.Height; y++ {
+		sum := 0
+		for x := 0; x < b.Width; x++ {
+			i := columns[x][y]
+			if i < 0 || b.placedBlocks[i].BlockType == NeutralBlock {
+				imbalance += abs(sum)
+				sum = 0
+				continue
+			}
+			sum += b.placedBlocks[i].BlockType.Charge()
+		}
+		imbalance += abs(sum)
+	}
+	return imbalance
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+)
+
+func positive() BlockType { return PositiveBlock }
+
+// playPiece lets bot play g until the falling piece locks.
+func playPiece(t *testing.T, g *Game, bot *Bot) {
+	t.Helper()
+	pieces := g.Pieces()
+	for range 2 * botGiveUpTicks {
+		g.Step(bot.Input(g.View()))
+		if g.Pieces() != pieces {
+			return
+		}
+	}
+	t.Fatal("bot never locked its piece")
+}
+
+func TestPlacementsLand(t *testing.T) {
+	b := NewBoard(DefaultWidth, DefaultHeight, nil)
+	for _, pieceType := range PieceTypes {
+		piece := b.SpawnPiece(pieceType, positive)
+		landed := Placements(b, piece)
+		if pieceType == OPiece && len(landed) != b.Width-1 {
+			t.Errorf("O piece has %d placements, want %d", len(landed), b.Width-1)
+		}
+		for _, p := range landed {
+			if !b.IsValidPosition(p, 0, 0) || b.IsValidPosition(p, 0, 1) {
+				t.Errorf("piece %d placed at (%d, %d) turned %d is not resting on the floor", pieceType, p.X, p.Y, p.Rotation)
+			}
+		}
+	}
+}
+
+func TestBotTakesReaction(t *testing.T) {
+	g := fixtureGame(t, "testdata/bot/reaction.txt")
+	g.enterPiece(NewPiece(OPiece, 0, 0, positive))
+	// Without hold, so the bot has to play the O.
+	g.holdUsed = true
+
+	playPiece(t, g, NewBot(DefaultBotWeights(), 1))
+	reacting := 0
+	for _, block := range g.Board.GetPlacedBlocks() {
+		if block.IsWobbling {
+			reacting++
+		}
+	}
+	if reacting != 4 {
+		t.Errorf("bot's placement set %d blocks reacting, want 4", reacting)
+	}
+}
+
+func TestBotAvoidsStorm(t *testing.T) {
+	g := fixtureGame(t, "testdata/bot/storm.txt")
+	g.enterPiece(NewPiece(IPiece, 0, 0, positive))
+
+	playPiece(t, g, NewBot(DefaultBotWeights(), 1))
+	for _, block := range g.Board.GetPlacedBlocks() {
+		if block.IsInStorm {
+			t.Fatalf("bot stacked column %d into a storm", block.X)
+		}
+	}
+}
+
+// TestBotSoak has the bot play whole games and checks that the board stays
+// sound throughout, and that the same seed plays out the same way.
+func TestBotSoak(t *testing.T) {
+	if testing.Short() {
+		t.Skip("soak test")
+	}
+	for seed := int64(1); seed <= 3; seed++ {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			first := soak(t, seed)
+			if first.Pieces() < 50 {
+				t.Errorf("bot only locked %d pieces", first.Pieces())
+			}
+			again := soak(t, seed)
+			if again.Score != first.Score || again.Tick() != first.Tick() {
+				t.Errorf("second run scored %d by tick %d, first %d by tick %d",
+					again.Score, again.Tick(), first.Score, first.Tick())
+			}
+		})
+	}
+}
+
+func soak(t *testing.T, seed int64) *Game {
+	t.Helper()
+	g := NewGame(NewRNG(seed), DefaultConfig())
+	bot := NewBot(DefaultBotWeights(), 1)
+	for range 20 * TicksPerSecond * 60 {
+		if g.IsOver() {
+			break
+		}
+		g.Step(bot.Input(g.View()))
+
+		cells := make(map[[2]int]bool)
+		for _, block := range g.Board.GetPlacedBlocks() {
+			if block.X < 0 || block.X >= g.Board.Width || block.Y < 0 || block.Y >= g.Board.Height {
+				t.Fatalf("tick %d: block out of bounds at (%d, %d)", g.Tick(), block.X, block.Y)
+			}
+			if block.IsFalling || block.IsArcing {
+				continue
+			}
+			cell := [2]int{block.X, block.Y}
+			if cells[cell] {
+				t.Fatalf("tick %d: two blocks at (%d, %d)", g.Tick(), block.X, block.Y)
+			}
+			cells[cell] = true
+		}
+	}
+	return g
+}
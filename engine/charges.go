@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// ChargeGenerator picks the charge of every cell of a new piece.
+type ChargeGenerator interface {
+	// Charges returns the block type for each of a piece's n cells.
+	Charges(n, level int) []BlockType
+	// State returns whatever the generator remembers between pieces so a
+	// saved game deals the same charges.
+	State() []BlockType
+	SetState(state []BlockType)
+}
+
+// ChargeKind names a ChargeGenerator implementation.
+type ChargeKind string
+
+const (
+	IndependentCharges ChargeKind = "independent"
+	BalancedCharges    ChargeKind = "balanced"
+	ChargeBag          ChargeKind = "bag"
+	LevelScaledCharges ChargeKind = "scaled"
+)
+
+// ChargeKinds lists every charge generator that can be chosen.
+var ChargeKinds = []ChargeKind{IndependentCharges, BalancedCharges, ChargeBag, LevelScaledCharges}
+
+func ParseCharges(name string) (ChargeKind, error) {
+	kind := ChargeKind(name)
+	if !slices.Contains(ChargeKinds, kind) {
+		return "", fmt.Errorf("unknown charge generator %q (want one of %v)", name, ChargeKinds)
+	}
+	return kind, nil
+}
+
+// NewChargeGenerator creates the generator of the given kind drawing from
+// r. Unknown kinds get independent odds.
+func NewChargeGenerator(kind ChargeKind, r *rand.Rand) ChargeGenerator {
+	switch kind {
+	case BalancedCharges:
+		return &balancedCharges{rand: r}
+	case ChargeBag:
+		return &chargeBag{rand: r}
+	case LevelScaledCharges:
+		return &levelScaledCharges{rand: r}
+	}
+	return &independentCharges{rand: r}
+}
+
+// neutralOdds is the chance of a cell rolling neutral; the rest is split
+// evenly between positive and negative.
+const neutralOdds = 0.2
+
+// rollCharge picks one cell's charge, neutral with the given odds and
+// positive or negative evenly otherwise.
+func rollCharge(r *rand.Rand, neutral float64) BlockType {
+	charged := (1 - neutral) / 2
+	x := r.Float64()
+	if x < charged {
+		return PositiveBlock
+	} else if x < 2*charged {
+		return NegativeBlock
+	}
+	return NeutralBlock
+}
+
+// independentCharges rolls every cell on its own at 40/40/20, so a piece
+// can come out all one charge.
+type independentCharges struct {
+	rand *rand.Rand
+}
+
+func (ic *independentCharges) Charges(n, level int) []BlockType {
+	charges := make([]BlockType, n)
+	for i := range charges {
+		charges[i] = rollCharge(ic.rand, neutralOdds)
+	}
+	return charges
+}
+
+func (ic *independentCharges) State() []BlockType { return nil }
+
+func (ic *independentCharges) SetState([]BlockType) {}
+
+// balancedCharges rolls which cells are neutral as usual, then splits the
+// rest between positive and negative so every piece nets within one.
+type balancedCharges struct {
+	rand *rand.Rand
+}
+
+func (bc *balancedCharges) Charges(n, level int) []BlockType {
+	charges := make([]BlockType, n)
+	var charged []int
+	for i := range charges {
+		if bc.rand.Float64() < neutralOdds {
+			charges[i] = NeutralBlock
+		} else {
+			charged = append(charged, i)
+		}
+	}
+	positives := len(charged) / 2
+	if len(charged)%2 == 1 && bc.rand.IntN(2) == 0 {
+		positives++
+	}
+	bc.rand.Shuffle(len(charged), func(i, j int) {
+		charged[i], charged[j] = charged[j], charged[i]
+	})
+	for k, i := range charged {
+		if k < positives {
+			charges[i] = PositiveBlock
+		} else {
+			charges[i] = NegativeBlock
+		}
+	}
+	return charges
+}
+
+func (bc *balancedCharges) State() []BlockType { return nil }
+
+func (bc *balancedCharges) SetState([]BlockType) {}
+
+// chargeBagContents is one bag's worth of charges, in the same 40/40/20
+// proportions as the independent odds.
+var chargeBagContents = []BlockType{
+	PositiveBlock, PositiveBlock, PositiveBlock, PositiveBlock,
+	PositiveBlock, PositiveBlock, PositiveBlock, PositiveBlock,
+	NegativeBlock, NegativeBlock, NegativeBlock, NegativeBlock,
+	NegativeBlock, NegativeBlock, NegativeBlock, NegativeBlock,
+	NeutralBlock, NeutralBlock, NeutralBlock, NeutralBlock,
+}
+
+// chargeBag deals cells from a shuffled bag of charges, so over every five
+// pieces the positives and negatives come out even.
+type chargeBag struct {
+	rand *rand.Rand
+	bag  []BlockType
+}
+
+func (cb *chargeBag) Charges(n, level int) []BlockType {
+	charges := make([]BlockType, n)
+	for i := range charges {
+		if len(cb.bag) == 0 {
+			cb.bag = slices.Clone(chargeBagContents)
+			cb.rand.Shuffle(len(cb.bag), func(i, j int) {
+				cb.bag[i], cb.bag[j] = cb.bag[j], cb.bag[i]
+			})
+		}
+		charges[i] = cb.bag[0]
+		cb.bag = cb.bag[1:]
+	}
+	return charges
+}
+
+func (cb *chargeBag) State() []BlockType {
+	return slices.Clone(cb.bag)
+}
+
+func (cb *chargeBag) SetState(state []BlockType) {
+	cb.bag = slices.Clone(state)
+}
+
+// Neutral odds for levelScaledCharges: they start low and climb with the
+// level up to a cap.
+const (
+	scaledNeutralBase  = 0.1
+	scaledNeutralStep  = 0.02
+	scaledNeutralLimit = 0.4
+)
+
+// levelScaledCharges rolls cells independently, with neutral blocks getting
+// more common as the level rises.
+type levelScaledCharges struct {
+	rand *rand.Rand
+}
+
+func (lc *levelScaledCharges) Charges(n, level int) []BlockType {
+	neutral := min(scaledNeutralBase+scaledNeutralStep*float64(level-1), scaledNeutralLimit)
+	charges := make([]BlockType, n)
+	for i := range charges {
+		charges[i] = rollCharge(lc.rand, neutral)
+	}
+	return charges
+}
+
+func (lc *levelScaledCharges) State() []BlockType { return nil }
+
+func (lc *levelScaledCharges) SetState([]BlockType) {}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func netCharge(charges []BlockType) int {
+	net := 0
+	for _, c := range charges {
+		net += c.Charge()
+	}
+	return net
+}
+
+func TestBalancedChargesNetWithinOne(t *testing.T) {
+	g := NewChargeGenerator(BalancedCharges, rand.New(rand.NewPCG(3, 4)))
+	for i := range 10000 {
+		charges := g.Charges(4, 1)
+		if net := netCharge(charges); net < -1 || net > 1 {
+			t.Fatalf("piece %d has charges %v, net %d", i, charges, net)
+		}
+	}
+}
+
+func TestChargeBagBalancesEveryBag(t *testing.T) {
+	g := NewChargeGenerator(ChargeBag, rand.New(rand.NewPCG(5, 6)))
+	var dealt []BlockType
+	for range 500 {
+		dealt = append(dealt, g.Charges(4, 1)...)
+	}
+	size := len(chargeBagContents)
+	for start := 0; start+size <= len(dealt); start += size {
+		if net := netCharge(dealt[start : start+size]); net != 0 {
+			t.Fatalf("bag at %d nets %d", start, net)
+		}
+	}
+}
+
+func TestLevelScaledNeutralRate(t *testing.T) {
+	neutralRate := func(level int) float64 {
+		g := NewChargeGenerator(LevelScaledCharges, rand.New(rand.NewPCG(1, 1)))
+		neutrals := 0
+		const cells = 40000
+		for _, c := range g.Charges(cells, level) {
+			if c == NeutralBlock {
+				neutrals++
+			}
+		}
+		return float64(neutrals) / cells
+	}
+	low, high, capped := neutralRate(1), neutralRate(10), neutralRate(100)
+	if low < 0.08 || low > 0.12 {
+		t.Errorf("level 1 neutral rate %.3f, want about %.2f", low, scaledNeutralBase)
+	}
+	if high <= low {
+		t.Errorf("level 10 neutral rate %.3f not above level 1's %.3f", high, low)
+	}
+	if capped > scaledNeutralLimit+0.02 {
+		t.Errorf("level 100 neutral rate %.3f above the %.2f cap", capped, scaledNeutralLimit)
+	}
+}
+
+func TestChargeGeneratorStateResumes(t *testing.T) {
+	for _, kind := range ChargeKinds {
+		t.Run(string(kind), func(t *testing.T) {
+			src := rand.NewPCG(9, 9)
+			g := NewChargeGenerator(kind, rand.New(src))
+			g.Charges(4*7, 3)
+
+			srcState, err := src.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			resumedSrc := rand.NewPCG(0, 0)
+			if err := resumedSrc.UnmarshalBinary(srcState); err != nil {
+				t.Fatal(err)
+			}
+			resumed := NewChargeGenerator(kind, rand.New(resumedSrc))
+			resumed.SetState(g.State())
+
+			for range 20 {
+				if want, got := g.Charges(4, 3), resumed.Charges(4, 3); !slices.Equal(got, want) {
+					t.Fatalf("resumed generator dealt %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package engine
+
+import "fmt"
+
+// The simulation advances in fixed ticks rather than wall-clock time, so a
+// game plays out the same on any machine and at any frame rate. Every timer
+// below is counted in ticks; the animation timings are the defaults a
+// Config's Timing may change.
+const (
+	TicksPerSecond = 60
+	TickSeconds    = 1.0 / TicksPerSecond
+
+	WobbleTicks  = int(WobbleDuration * TicksPerSecond)
+	WarningTicks = int(WarningDuration * TicksPerSecond)
+
+	// A storm throws a neutral block somewhere between these two intervals.
+	StormMinTicks = 3 * TicksPerSecond
+	StormMaxTicks = 5 * TicksPerSecond
+)
+
+// Timing is how long the board's reactions, falls and storms take. The
+// game waits on them, so they are rules rather than looks: Config carries
+// them into replays and saves. The zero Timing stands for DefaultTiming.
+type Timing struct {
+	WobbleTicks int `json:"wobbleTicks"`
+	// FallSpeed is how many rows a second a block falls, and ArcSpeed how
+	// many times a second a thrown block could cross its arc.
+	FallSpeed float64 `json:"fallSpeed"`
+	ArcSpeed  float64 `json:"arcSpeed"`
+	// A storm warns WarningTicks before each throw, and throws every
+	// StormMinTicks to StormMaxTicks.
+	WarningTicks  int `json:"warningTicks"`
+	StormMinTicks int `json:"stormMinTicks"`
+	StormMaxTicks int `json:"stormMaxTicks"`
+}
+
+func DefaultTiming() Timing {
+	return Timing{
+		WobbleTicks:   WobbleTicks,
+		FallSpeed:     FallSpeed,
+		ArcSpeed:      ArcSpeed,
+		WarningTicks:  WarningTicks,
+		StormMinTicks: StormMinTicks,
+		StormMaxTicks: StormMaxTicks,
+	}
+}
+
+// orDefault returns t, or DefaultTiming if t was left unset.
+func (t Timing) orDefault() Timing {
+	if t == (Timing{}) {
+		return DefaultTiming()
+	}
+	return t
+}
+
+// Validate reports whether t can be played. The zero Timing is valid.
+func (t Timing) Validate() error {
+	if t == (Timing{}) {
+		return nil
+	}
+	switch {
+	case t.WobbleTicks < 1:
+		return fmt.Errorf("wobble lasts %d ticks, want at least 1", t.WobbleTicks)
+	case !(t.FallSpeed > 0) || !(t.ArcSpeed > 0):
+		return fmt.Errorf("fall speed %g and arc speed %g must be above 0", t.FallSpeed, t.ArcSpeed)
+	case t.StormMinTicks < 1 || t.StormMaxTicks < t.StormMinTicks:
+		return fmt.Errorf("storms throw every %d to %d ticks, want at least 1 and in order", t.StormMinTicks, t.StormMaxTicks)
+	case t.WarningTicks < 0:
+		return fmt.Errorf("storm warning lasts %d ticks, want at least 0", t.WarningTicks)
+	}
+	return nil
+}
+
+// TicksToSeconds converts a tick count to seconds for code that animates
+// against real time, such as the renderer.
+func TicksToSeconds(ticks int) float64 {
+	return float64(ticks) * TickSeconds
+}
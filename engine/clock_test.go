@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// ticksUntil steps update until done reports true and returns how many
+// ticks that took.
+func ticksUntil(t *testing.T, update func(), done func() bool) int {
+	t.Helper()
+	for tick := 1; tick <= 10*TicksPerSecond; tick++ {
+		update()
+		if done() {
+			return tick
+		}
+	}
+	t.Fatal("never finished")
+	return 0
+}
+
+// TestAnimationTicks pins every timed rule to a whole number of ticks, so
+// they take as long at any frame rate.
+func TestAnimationTicks(t *testing.T) {
+	b := parseBoard(t, `
+....
+....
+++--
+`)
+	b.StartNewReactions()
+	wobble := ticksUntil(t, func() { b.UpdateWobblingBlocks() }, func() bool {
+		return len(b.RemoveFinishedWobblingBlocks()) > 0
+	})
+	if wobble != WobbleTicks || WobbleTicks != 48 {
+		t.Errorf("wobble took %d ticks (WobbleTicks %d), want 48", wobble, WobbleTicks)
+	}
+
+	// Two rows at FallSpeed rows a second, and an arc at ArcSpeed. Both add
+	// up their progress in floating point, which falls just short of 1 on
+	// the ideal tick and lands on the one after, the same on every machine.
+	b = parseBoard(t, `
++...
+....
+....
+`)
+	b.StartBlockFall(&b.placedBlocks[0])
+	fall := ticksUntil(t, func() { b.UpdateFallingBlocks() }, func() bool { return !b.placedBlocks[0].IsFalling })
+	if want := int(2/FallSpeed*TicksPerSecond) + 1; fall != want {
+		t.Errorf("two-row fall took %d ticks, want %d", fall, want)
+	}
+
+	b = parseBoard(t, "....\n....\n....\n")
+	block := b.AddNeutralBlock(Block{BlockType: NeutralBlock, IsArcing: true})
+	StartBlockArc(block, 0, 2, 3, 0)
+	arc := ticksUntil(t, func() { b.UpdateArcingBlocks() }, func() bool { return !b.placedBlocks[0].IsArcing })
+	if want := int(TicksPerSecond/ArcSpeed) + 1; arc != want {
+		t.Errorf("arc took %d ticks, want %d", arc, want)
+	}
+}
+
+func TestStormTicks(t *testing.T) {
+	b := parseBoard(t, `
+......
+.+....
+.+....
+.+....
+.+....
+`)
+	b.stormRand = rand.New(rand.NewPCG(3, 4))
+	b.CheckForElectricalStorms()
+	storm := b.activeStorms[1]
+	if storm.NextDrop < StormMinTicks || storm.NextDrop > StormMaxTicks {
+		t.Fatalf("storm due in %d ticks, want between %d and %d", storm.NextDrop, StormMinTicks, StormMaxTicks)
+	}
+	due := storm.NextDrop
+
+	warned := 0
+	throw := ticksUntil(t, func() {}, func() bool {
+		thrown := b.UpdateStormTimers()
+		if storm.IsWarning && warned == 0 {
+			warned = storm.Timer
+		}
+		return len(thrown) > 0
+	})
+	if throw != due {
+		t.Errorf("storm threw after %d ticks, want %d", throw, due)
+	}
+	if warned != due-WarningTicks {
+		t.Errorf("warning started at tick %d, want %d", warned, due-WarningTicks)
+	}
+}
+
+func TestConfigTiming(t *testing.T) {
+	if g := NewGame(NewRNG(1), Config{}); g.Board.Timing != DefaultTiming() {
+		t.Errorf("unset timing played as %+v, want the defaults", g.Board.Timing)
+	}
+
+	config := DefaultConfig()
+	config.Timing.WobbleTicks = 10
+	g := NewGame(NewRNG(1), config)
+	g.Board.SetPlacedBlocks(parseBoard(t, "++--\n").GetPlacedBlocks())
+	g.Board.StartNewReactions()
+	wobble := ticksUntil(t, func() { g.Board.UpdateWobblingBlocks() }, func() bool {
+		return len(g.Board.RemoveFinishedWobblingBlocks()) > 0
+	})
+	if wobble != 10 {
+		t.Errorf("wobble took %d ticks, want the configured 10", wobble)
+	}
+
+	state, err := g.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreGame(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Board.Timing != config.Timing {
+		t.Errorf("restored game timed as %+v, want %+v", restored.Board.Timing, config.Timing)
+	}
+
+	for _, bad := range []func(*Timing){
+		func(t *Timing) { t.WobbleTicks = 0 },
+		func(t *Timing) { t.FallSpeed = -1 },
+		func(t *Timing) { t.StormMaxTicks = t.StormMinTicks - 1 },
+		func(t *Timing) { t.WarningTicks = -1 },
+	} {
+		timing := DefaultTiming()
+		bad(&timing)
+		if timing.Validate() == nil {
+			t.Errorf("timing %+v passed validation", timing)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package engine
+
+// Config holds the rule choices a game starts with. Replays and saves carry
+// it so the game plays out the same way again.
+type Config struct {
+	Randomizer RandomizerKind `json:"randomizer"`
+	Charges    ChargeKind     `json:"charges"`
+	// Preview is how many upcoming pieces are dealt ahead and shown.
+	Preview int `json:"preview"`
+	// LockDelayTicks is how long a piece may rest on the stack before it
+	// locks. Moving or turning it restarts the wait, up to LockResets times
+	// per row it reaches.
+	LockDelayTicks int `json:"lockDelayTicks"`
+	LockResets     int `json:"lockResets"`
+	// GravityCurve and BlocksPerLevel set how fast pieces fall at each
+	// level and how quickly the levels come.
+	GravityCurve   []int `json:"gravityCurve"`
+	BlocksPerLevel int   `json:"blocksPerLevel"`
+	// Handling is how held movement repeats. It is carried with the rules
+	// because a replay's inputs only reproduce the game under the same
+	// timing.
+	Handling Handling `json:"handling"`
+	// Mode is what the game is played for. SprintGoal is the blocks a
+	// sprint must neutralize, and UltraSeconds how long an ultra game
+	// lasts.
+	Mode         ModeKind `json:"mode"`
+	SprintGoal   int      `json:"sprintGoal"`
+	UltraSeconds int      `json:"ultraSeconds"`
+	// Puzzle, when set, is the level being played. It deals the pieces and
+	// charges in place of the randomizer and charge settings, and the game
+	// is played in PuzzleMode whatever Mode says.
+	Puzzle *Puzzle `json:"puzzle,omitempty"`
+	// Timing is how long the board's reactions, falls and storms take.
+	Timing Timing `json:"timing"`
+}
+
+// Limits on Config.Preview.
+const (
+	MinPreview = 1
+	MaxPreview = 6
+)
+
+func DefaultConfig() Config {
+	return Config{
+		Randomizer: SevenBag,
+		Charges:    IndependentCharges,
+		Preview:    5,
+
+		LockDelayTicks: TicksPerSecond / 2,
+		LockResets:     15,
+		GravityCurve:   DefaultGravityCurve,
+		BlocksPerLevel: DefaultBlocksPerLevel,
+		Handling:       DefaultHandling(),
+
+		Mode:         ClassicMode,
+		SprintGoal:   DefaultSprintGoal,
+		UltraSeconds: DefaultUltraSeconds,
+
+		Timing: DefaultTiming(),
+	}
+}
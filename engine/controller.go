@@ -0,0 +1,58 @@
+package engine
+
+// Controller decides each tick's input from what it can see of the game.
+// The keyboard, a replay and a bot all drive a game the same way.
+type Controller interface {
+	Input(view *View) Input
+}
+
+// View is a read-only copy of what a player can see of a game: the blocks
+// on the board, the falling piece, the queue and the hold slot. Controllers
+// may do what they like with it without touching the game.
+type View struct {
+	Width, Height int
+	Blocks        []Block
+	// Current is nil once the game is over.
+	Current *Piece
+	Queue   []*Piece
+	Hold    *Piece
+	CanHold bool
+	// Pieces is how many pieces have locked, so a controller can tell a
+	// new piece from the last one.
+	Pieces int
+	Score  int
+	Level  int
+	Tick   int
+}
+
+// View returns a copy of the game as a player sees it.
+func (g *Game) View() *View {
+	v := &View{
+		Width:   g.Board.Width,
+		Height:  g.Board.Height,
+		Blocks:  append([]Block(nil), g.Board.GetPlacedBlocks()...),
+		CanHold: g.CanHold(),
+		Pieces:  g.pieces,
+		Score:   g.Score,
+		Level:   g.level,
+		Tick:    g.tick,
+	}
+	if g.Current != nil && !g.over {
+		v.Current = g.Current.Copy()
+	}
+	for _, piece := range g.Queue {
+		v.Queue = append(v.Queue, piece.Copy())
+	}
+	if g.Hold != nil {
+		v.Hold = g.Hold.Copy()
+	}
+	return v
+}
+
+// Board returns a scratch board holding the view's blocks, for trying out
+// placements.
+func (v *View) Board() *Board {
+	b := NewBoard(v.Width, v.Height, nil)
+	b.SetPlacedBlocks(append([]Block(nil), v.Blocks...))
+	return b
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata from current output")
+
+// Board fixtures are drawn one row per line, top row first, with one
+// character per cell: '+' positive, '-' negative, '0' neutral, 'p' and 'n'
+// positive and negative blocks that are already wobbling, '.' empty.
+//
+// Blank lines and lines starting with # are ignored, so fixtures can say
+// what they are testing.
+var fixtureCells = map[byte]Block{
+	'+': {BlockType: PositiveBlock},
+	'-': {BlockType: NegativeBlock},
+	'0': {BlockType: NeutralBlock},
+	'p': {BlockType: PositiveBlock, IsWobbling: true},
+	'n': {BlockType: NegativeBlock, IsWobbling: true},
+}
+
+func parseBoard(t *testing.T, text string) *Board {
+	t.Helper()
+	var rows []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rows = append(rows, line)
+	}
+	if len(rows) == 0 {
+		t.Fatal("fixture has no rows")
+	}
+
+	b := NewBoard(len(rows[0]), len(rows), nil)
+	for y, row := range rows {
+		if len(row) != b.Width {
+			t.Fatalf("row %d is %d cells wide, want %d", y, len(row), b.Width)
+		}
+		for x := 0; x < len(row); x++ {
+			if row[x] == '.' {
+				continue
+			}
+			block, ok := fixtureCells[row[x]]
+			if !ok {
+				t.Fatalf("row %d: unknown cell %q", y, row[x])
+			}
+			block.X, block.Y = x, y
+			b.placedBlocks = append(b.placedBlocks, block)
+		}
+	}
+	return b
+}
+
+// drawBlocks draws blocks on an empty grid the size of b.
+func drawBlocks(b *Board, blocks []Block) string {
+	grid := make([][]byte, b.Height)
+	for y := range grid {
+		grid[y] = []byte(strings.Repeat(".", b.Width))
+	}
+	for _, block := range blocks {
+		if block.Y < 0 || block.Y >= b.Height || block.X < 0 || block.X >= b.Width {
+			continue
+		}
+		grid[block.Y][block.X] = fixtureCell(block)
+	}
+	var sb strings.Builder
+	for _, row := range grid {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func fixtureCell(block Block) byte {
+	switch block.BlockType {
+	case PositiveBlock:
+		if block.IsWobbling {
+			return 'p'
+		}
+		return '+'
+	case NegativeBlock:
+		if block.IsWobbling {
+			return 'n'
+		}
+		return '-'
+	}
+	return '0'
+}
+
+// settle runs falling blocks to the ground the way the game's ticks would.
+func settle(t *testing.T, b *Board) {
+	t.Helper()
+	for range TicksPerSecond * b.Height {
+		b.UpdateFallingBlocks()
+		falling := false
+		for _, block := range b.placedBlocks {
+			falling = falling || block.IsFalling
+		}
+		if !falling {
+			return
+		}
+	}
+	t.Fatal("blocks never stopped falling")
+}
+
+// checkGolden compares got with the golden file at path, or rewrites the
+// file when the test is run with -update.
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from current output (run with -update to accept):\n--- got ---\n%s--- want ---\n%s",
+			filepath.Base(path), got, want)
+	}
+}
@@ -0,0 +1,540 @@
+package engine
+
+import "slices"
+
+type BlocksRemovedCallback func(blocks []Block)
+type PiecePlacedCallback func(piece *Piece)
+type NeutralSpawnedCallback func(block Block)
+type ReactionStartedCallback func(blocks []Block, reaction Reaction)
+type StormIgnitedCallback func(column int)
+type PieceSpawnedCallback func(piece *Piece)
+type PieceMovedCallback func(piece *Piece)
+type PieceRotatedCallback func(piece *Piece)
+type HardDropCallback func(dropHeight int)
+type GarbageRaisedCallback func(rows int)
+
+// EndCause is why a game ended.
+type EndCause string
+
+const (
+	// BlockOut is a new piece finding no room to enter the board.
+	BlockOut EndCause = "blockOut"
+	// TopOut is a piece locking with a charged block in the top row.
+	TopOut EndCause = "topOut"
+)
+
+// Points for every row a piece is soft or hard dropped.
+const (
+	SoftDropPoints = 1
+	HardDropPoints = 2
+)
+
+// Game runs a single round of Un-ion: the board, the falling piece, the
+// queue of pieces to come, the held piece and the score. It has no renderer;
+// callers drive it and read its state back to draw.
+type Game struct {
+	Board       *Board
+	Current     *Piece
+	Queue       []*Piece
+	Hold        *Piece
+	Score       int
+	config      Config
+	rng         *RNG
+	randomizer  Randomizer
+	charges     ChargeGenerator
+	mode        GameMode
+	level       int
+	cleared     int
+	pieces      int
+	scoring     ScoringState
+	garbageIn   int
+	garbageOut  int
+	over        bool
+	end         EndCause
+	holdUsed    bool
+	lockTicks   int
+	lockResets  int
+	lowestY     int
+	tick        int
+	gravity     int
+	lastInput   Input
+	leftRepeat  repeatTimer
+	rightRepeat repeatTimer
+	dropRepeat  repeatTimer
+	onRemoved   BlocksRemovedCallback
+	onPlaced    PiecePlacedCallback
+	onNeutral   NeutralSpawnedCallback
+	onReaction  ReactionStartedCallback
+	onStorm     StormIgnitedCallback
+	onSpawned   PieceSpawnedCallback
+	onMoved     PieceMovedCallback
+	onRotated   PieceRotatedCallback
+	onHardDrop  HardDropCallback
+	onGarbage   GarbageRaisedCallback
+}
+
+func NewGame(rng *RNG, config Config) *Game {
+	g := &Game{
+		Board:  NewBoard(DefaultWidth, DefaultHeight, rng.Storm),
+		config: config,
+		rng:    rng,
+		mode:   NewGameMode(config),
+		level:  1,
+	}
+	g.Board.Timing = config.Timing.orDefault()
+	g.randomizer, g.charges = newDealers(config, rng)
+	if config.Puzzle != nil {
+		g.Board.SetPlacedBlocks(config.Puzzle.Blocks())
+	}
+	for range min(max(config.Preview, MinPreview), MaxPreview) {
+		g.generateNextPiece()
+	}
+	g.SpawnPiece()
+	return g
+}
+
+func (g *Game) SetBlocksRemovedCallback(callback BlocksRemovedCallback) {
+	g.onRemoved = callback
+}
+
+func (g *Game) SetPiecePlacedCallback(callback PiecePlacedCallback) {
+	g.onPlaced = callback
+}
+
+func (g *Game) SetNeutralSpawnedCallback(callback NeutralSpawnedCallback) {
+	g.onNeutral = callback
+}
+
+// SetReactionStartedCallback is called when blocks are neutralized and
+// start to wobble, with the points the reaction scored.
+func (g *Game) SetReactionStartedCallback(callback ReactionStartedCallback) {
+	g.onReaction = callback
+}
+
+func (g *Game) SetStormIgnitedCallback(callback StormIgnitedCallback) {
+	g.onStorm = callback
+}
+
+func (g *Game) SetPieceSpawnedCallback(callback PieceSpawnedCallback) {
+	g.onSpawned = callback
+}
+
+// SetPieceMovedCallback is called whenever input moves the falling piece.
+func (g *Game) SetPieceMovedCallback(callback PieceMovedCallback) {
+	g.onMoved = callback
+}
+
+func (g *Game) SetPieceRotatedCallback(callback PieceRotatedCallback) {
+	g.onRotated = callback
+}
+
+func (g *Game) SetHardDropCallback(callback HardDropCallback) {
+	g.onHardDrop = callback
+}
+
+func (g *Game) SetGarbageRaisedCallback(callback GarbageRaisedCallback) {
+	g.onGarbage = callback
+}
+
+// Tick returns the number of ticks the game has been stepped.
+func (g *Game) Tick() int {
+	return g.tick
+}
+
+func (g *Game) IsOver() bool {
+	return g.over
+}
+
+// EndCause returns why the game ended, or "" while it is still going.
+func (g *Game) EndCause() EndCause {
+	return g.end
+}
+
+func (g *Game) finish(cause EndCause) {
+	g.over = true
+	g.end = cause
+}
+
+// Mode returns the mode the game is played in.
+func (g *Game) Mode() GameMode {
+	return g.mode
+}
+
+// forgive clears the stack away instead of ending the game, if the mode
+// allows it, and reports whether it did.
+func (g *Game) forgive() bool {
+	if !g.mode.Forgiving() {
+		return false
+	}
+	g.Board.SetPlacedBlocks(nil)
+	g.Board.SetStorms(nil)
+	return true
+}
+
+func (g *Game) RNG() *RNG {
+	return g.rng
+}
+
+func (g *Game) Config() Config {
+	return g.config
+}
+
+func (g *Game) Level() int {
+	return g.level
+}
+
+// BlocksCleared returns how many blocks have been neutralized this game.
+func (g *Game) BlocksCleared() int {
+	return g.cleared
+}
+
+// Scoring returns the current chain and combo and the best of each so far.
+func (g *Game) Scoring() ScoringState {
+	return g.scoring
+}
+
+// Pieces returns how many pieces have locked.
+func (g *Game) Pieces() int {
+	return g.pieces
+}
+
+// newPiece deals a piece of the given type with its charges.
+func (g *Game) newPiece(pieceType PieceType, x, y int) *Piece {
+	charges := g.charges.Charges(len(pieceShapes[pieceType]), g.level)
+	return NewPiece(pieceType, x, y, func() BlockType {
+		charge := charges[0]
+		charges = charges[1:]
+		return charge
+	})
+}
+
+// SpawnPiece promotes the front of the queue to the board and deals a new
+// piece onto the back.
+func (g *Game) SpawnPiece() {
+	if len(g.Queue) == 0 {
+		g.generateNextPiece()
+	}
+	next := g.Queue[0]
+	g.Queue = g.Queue[1:]
+	g.generateNextPiece()
+	g.enterPiece(next)
+}
+
+// enterPiece puts piece on the board at the spawn point. The game ends if
+// it overlaps a charged block.
+func (g *Game) enterPiece(piece *Piece) {
+	g.Current = piece.Copy()
+	g.Current.X = g.Board.SpawnColumn()
+	g.Current.Y = 0
+	g.gravity = 0
+	g.lockTicks = 0
+	g.lockResets = 0
+	g.lowestY = g.Current.Y
+
+	if !g.Board.IsValidPositionIgnoreNeutral(g.Current, 0, 0) && !g.forgive() {
+		g.finish(BlockOut)
+		return
+	}
+	if g.onSpawned != nil {
+		g.onSpawned(g.Current)
+	}
+}
+
+func (g *Game) generateNextPiece() {
+	g.Queue = append(g.Queue, g.newPiece(g.randomizer.Next(), 0, 0))
+}
+
+// CanHold reports whether the falling piece may be swapped into the hold
+// slot; it may only be once per piece.
+func (g *Game) CanHold() bool {
+	return g.Current != nil && !g.holdUsed
+}
+
+// HoldPiece puts the falling piece in the hold slot, turned back to its
+// spawn orientation with its charges intact, and brings out the piece that
+// was held, or the next piece if the slot was empty.
+func (g *Game) HoldPiece() bool {
+	if !g.CanHold() {
+		return false
+	}
+	held := g.Current.Copy()
+	RotatePiece(held, Turn(-held.Rotation))
+	held.X, held.Y = 0, 0
+
+	if g.Hold == nil {
+		g.SpawnPiece()
+	} else {
+		g.enterPiece(g.Hold)
+	}
+	g.Hold = held
+	g.holdUsed = true
+	return true
+}
+
+func (g *Game) TryMovePiece(deltaX, deltaY int) bool {
+	return g.Board.TryMovePiece(g.Current, deltaX, deltaY)
+}
+
+func (g *Game) TryRotatePiece(turn Turn) bool {
+	if !g.Board.TryRotatePiece(g.Current, turn) {
+		return false
+	}
+	g.pieceShifted()
+	if g.onRotated != nil {
+		g.onRotated(g.Current)
+	}
+	return true
+}
+
+// HardDrop drops the falling piece straight to where it lands and locks it.
+func (g *Game) HardDrop() {
+	if g.Current == nil || g.over {
+		return
+	}
+	landing := g.Board.CalculateDropPosition(g.Current)
+	distance := landing.Y - g.Current.Y
+	g.Current.Y = landing.Y
+	g.addDropScore(distance * HardDropPoints)
+	if g.onHardDrop != nil {
+		g.onHardDrop(distance)
+	}
+	g.LockPiece()
+}
+
+func (g *Game) grounded() bool {
+	return g.Current != nil && !g.Board.IsValidPosition(g.Current, 0, 1)
+}
+
+// pieceShifted restarts the lock delay after the piece moves or turns on the
+// stack, as long as it has resets left. Reaching a new lowest row gives it
+// a fresh set.
+func (g *Game) pieceShifted() {
+	if g.Current.Y > g.lowestY {
+		g.lowestY = g.Current.Y
+		g.lockResets = 0
+	}
+	if g.lockTicks > 0 && g.lockResets < g.config.LockResets {
+		g.lockResets++
+		g.lockTicks = 0
+	}
+}
+
+// updateLockDelay locks the piece once it has rested on the stack for the
+// lock delay.
+func (g *Game) updateLockDelay() {
+	if !g.grounded() {
+		g.lockTicks = 0
+		return
+	}
+	g.lockTicks++
+	if g.lockTicks >= g.config.LockDelayTicks {
+		g.LockPiece()
+	}
+}
+
+// LockPiece settles the current piece where it is, starts any reactions
+// and storms it causes, and spawns the next piece.
+func (g *Game) LockPiece() {
+	if g.Current == nil || g.over {
+		return
+	}
+
+	g.Board.PlacePiece(g.Current)
+	g.pieces++
+	g.holdUsed = false
+	if g.onPlaced != nil {
+		g.onPlaced(g.Current)
+	}
+
+	// Garbage only rises when the lock didn't react, so a player under
+	// attack can hold it off by reacting.
+	if !g.checkReactions(fromLock) {
+		g.scoring.Combo = 0
+		g.raiseGarbage()
+	}
+
+	if g.Board.IsGameOver() && !g.forgive() {
+		g.finish(TopOut)
+		return
+	}
+
+	g.SpawnPiece()
+}
+
+// Step advances the game by one tick: block animations and storms first,
+// then the player's input, then gravity and the lock delay. Last, the mode
+// checks whether the game has reached its end. A resignation ends it at
+// once, without playing the tick.
+func (g *Game) Step(input Input) {
+	if g.over {
+		return
+	}
+	if input.Resign {
+		g.finish(Resigned)
+		return
+	}
+	g.tick++
+	g.advance(input)
+	if cause := g.mode.Check(g); cause != "" && !g.over {
+		g.finish(cause)
+	}
+}
+
+func (g *Game) advance(input Input) {
+	g.updateBoard()
+
+	dropped := g.applyInput(input)
+	g.lastInput = input
+	if dropped || g.over {
+		return
+	}
+
+	g.gravity++
+	if g.gravity >= g.config.gravityTicks(g.level) {
+		g.gravity = 0
+		if g.TryMovePiece(0, 1) {
+			g.pieceShifted()
+		}
+	}
+	g.updateLockDelay()
+}
+
+// applyInput moves and rotates the falling piece for this tick's input and
+// reports whether it was hard dropped.
+func (g *Game) applyInput(input Input) bool {
+	if g.Current == nil {
+		return false
+	}
+	if input.HardDrop && !g.lastInput.HardDrop {
+		g.HardDrop()
+		return true
+	}
+	last := g.lastInput
+	handling := g.config.Handling
+
+	if input.Rotate && !last.Rotate {
+		g.TryRotatePiece(TurnCW)
+	}
+	if input.RotateCCW && !last.RotateCCW {
+		g.TryRotatePiece(TurnCCW)
+	}
+	if input.Rotate180 && !last.Rotate180 {
+		g.TryRotatePiece(Turn180)
+	}
+	if input.Hold && !last.Hold {
+		g.HoldPiece()
+	}
+
+	if g.leftRepeat.fire(last.Left, input.Left, handling.DelayTicks, handling.RepeatTicks) {
+		g.movePiece(-1, 0)
+	}
+	if g.rightRepeat.fire(last.Right, input.Right, handling.DelayTicks, handling.RepeatTicks) {
+		g.movePiece(1, 0)
+	}
+	if g.dropRepeat.fire(last.SoftDrop, input.SoftDrop, handling.SoftDropTicks, handling.SoftDropTicks) {
+		if g.movePiece(0, 1) {
+			g.addDropScore(SoftDropPoints)
+		}
+	}
+	return false
+}
+
+func (g *Game) movePiece(deltaX, deltaY int) bool {
+	if !g.TryMovePiece(deltaX, deltaY) {
+		return false
+	}
+	g.pieceShifted()
+	if g.onMoved != nil {
+		g.onMoved(g.Current)
+	}
+	return true
+}
+
+// updateBoard advances every block animation and storm by one tick and
+// resolves whatever reactions they lead to.
+func (g *Game) updateBoard() {
+	board := g.Board
+
+	anyBlocksFinishedArcing := board.UpdateArcingBlocks()
+
+	anyBlocksLanded := board.UpdateFallingBlocks()
+
+	anyBlocksFinished := board.UpdateWobblingBlocks()
+
+	board.UpdateElectricalStorms()
+	board.ClearInvalidStorms()
+
+	newNeutralBlocks := board.UpdateStormTimers()
+	if g.mode.Forgiving() {
+		newNeutralBlocks = slices.DeleteFunc(newNeutralBlocks, func(b Block) bool {
+			return board.columnTop(b.X) < zenSafeRows
+		})
+	}
+	for _, neutralBlock := range newNeutralBlocks {
+		board.AddNeutralBlock(neutralBlock)
+		if g.onNeutral != nil {
+			g.onNeutral(neutralBlock)
+		}
+	}
+
+	if len(newNeutralBlocks) > 0 {
+		board.processBlockFalling()
+	}
+
+	// Blocks only fall outside a cascade when a storm has thrown a neutral
+	// block among them.
+	if anyBlocksFinishedArcing {
+		g.checkReactions(fromStorm)
+	} else if anyBlocksLanded {
+		if g.scoring.Chain > 0 {
+			g.checkReactions(fromCascade)
+		} else {
+			g.checkReactions(fromStorm)
+		}
+	}
+
+	if anyBlocksFinished {
+		removed := board.RemoveFinishedWobblingBlocks()
+		if len(removed) > 0 {
+			if g.onRemoved != nil {
+				g.onRemoved(removed)
+			}
+			g.cleared += len(removed)
+			g.level = g.config.levelFor(g.cleared)
+
+			board.processBlockFalling()
+
+			g.checkReactions(fromCascade)
+		}
+	}
+
+	g.endChainIfSettled()
+}
+
+// addDropScore adds points for dropping. They count toward the score but
+// aren't announced like reaction points.
+func (g *Game) addDropScore(points int) {
+	g.Score += points
+}
+
+// checkReactions starts any reactions and storms the board has earned,
+// scores the reactions as set off by source and reports whether there were
+// any.
+func (g *Game) checkReactions(source reactionSource) bool {
+	blocks := g.Board.StartNewReactions()
+	if len(blocks) > 0 {
+		reaction := g.scoreReaction(blocks, source)
+		g.Score += reaction.Points
+		g.sendGarbage(garbageFor(reaction))
+		if g.onReaction != nil {
+			g.onReaction(blocks, reaction)
+		}
+	}
+	for _, column := range g.Board.CheckForElectricalStorms() {
+		g.sendGarbage(StormGarbage)
+		if g.onStorm != nil {
+			g.onStorm(column)
+		}
+	}
+	return len(blocks) > 0
+}
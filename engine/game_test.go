@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQueueFeedsCurrentPiece(t *testing.T) {
+	for _, preview := range []int{MinPreview, 3, MaxPreview} {
+		config := DefaultConfig()
+		config.Preview = preview
+		g := NewGame(NewRNG(11), config)
+		for range 20 {
+			if len(g.Queue) != preview {
+				t.Fatalf("queue holds %d pieces, want %d", len(g.Queue), preview)
+			}
+			next := g.Queue[0]
+			g.SpawnPiece()
+			if g.Current.Type != next.Type || !slices.Equal(g.Current.Blocks, next.Blocks) {
+				t.Fatalf("spawned %+v, want the front of the queue %+v", g.Current, next)
+			}
+		}
+	}
+}
+
+func TestHoldOncePerPiece(t *testing.T) {
+	g := NewGame(NewRNG(5), DefaultConfig())
+	first := g.Current.Copy()
+	next := g.Queue[0]
+	g.TryRotatePiece(TurnCW)
+
+	if !g.HoldPiece() {
+		t.Fatal("first hold refused")
+	}
+	if g.Hold.Rotation != 0 || !slices.Equal(g.Hold.Blocks, first.Blocks) {
+		t.Errorf("held %+v, want the spawn orientation with the same charges %+v", g.Hold, first)
+	}
+	if g.Current.Type != next.Type {
+		t.Errorf("current piece is %d after holding into an empty slot, want the next piece %d", g.Current.Type, next.Type)
+	}
+	if g.HoldPiece() {
+		t.Fatal("second hold before the piece locked was allowed")
+	}
+
+	g.LockPiece()
+	if !g.HoldPiece() {
+		t.Fatal("hold refused after a lock")
+	}
+	if g.Current.Type != first.Type || !slices.Equal(g.Current.Blocks, first.Blocks) {
+		t.Errorf("swapped in %+v, want the held piece %+v", g.Current, first)
+	}
+	if g.Current.X != g.Board.SpawnColumn() || g.Current.Y != 0 {
+		t.Errorf("held piece came back at (%d, %d), want the spawn point", g.Current.X, g.Current.Y)
+	}
+}
+
+// play steps g with a fixed pattern of input that locks pieces regularly.
+func play(g *Game, from, ticks int) {
+	for i := from; i < from+ticks && !g.IsOver(); i++ {
+		g.Step(Input{
+			Left:     i%60 < 15,
+			Right:    i%90 > 70,
+			SoftDrop: i%7 == 0,
+			Rotate:   i%19 == 0,
+			Hold:     i%150 == 0,
+		})
+	}
+}
+
+// neutralize makes the falling piece all neutral so locking it can't start
+// a reaction and muddy the score.
+func neutralize(g *Game) {
+	for i := range g.Current.Blocks {
+		g.Current.Blocks[i].BlockType = NeutralBlock
+	}
+}
+
+func TestHardDrop(t *testing.T) {
+	g := NewGame(NewRNG(8), DefaultConfig())
+	neutralize(g)
+	landing := g.Board.CalculateDropPosition(g.Current)
+	want := landing.Y - g.Current.Y
+
+	var got int
+	g.SetHardDropCallback(func(dropHeight int) { got = dropHeight })
+	g.Step(Input{HardDrop: true})
+
+	if got != want {
+		t.Errorf("hard drop reported %d rows, want %d", got, want)
+	}
+	if g.Score != want*HardDropPoints {
+		t.Errorf("score %d after hard drop, want %d", g.Score, want*HardDropPoints)
+	}
+	if n := len(g.Board.GetPlacedBlocks()); n != len(landing.Blocks) {
+		t.Errorf("%d blocks on the board after hard drop, want %d", n, len(landing.Blocks))
+	}
+	for _, block := range landing.Blocks {
+		if !slices.ContainsFunc(g.Board.GetPlacedBlocks(), func(b Block) bool {
+			return b.X == landing.X+block.X && b.Y == landing.Y+block.Y
+		}) {
+			t.Errorf("no block locked at (%d, %d)", landing.X+block.X, landing.Y+block.Y)
+		}
+	}
+
+	// Holding the key doesn't drop the next piece too.
+	next := g.Current
+	g.Step(Input{HardDrop: true})
+	if g.Current != next {
+		t.Error("holding hard drop dropped a second piece")
+	}
+}
+
+func TestSoftDropPoints(t *testing.T) {
+	g := NewGame(NewRNG(8), DefaultConfig())
+	startY := g.Current.Y
+	for range 30 {
+		g.Step(Input{SoftDrop: true})
+	}
+	rows := g.Current.Y - startY
+	if rows == 0 {
+		t.Fatal("soft drop didn't move the piece")
+	}
+	if g.Score != rows*SoftDropPoints {
+		t.Errorf("score %d after soft dropping %d rows, want %d", g.Score, rows, rows*SoftDropPoints)
+	}
+}
+
+// groundedGame returns a game whose falling piece is resting on the floor.
+func groundedGame(t *testing.T, config Config) *Game {
+	t.Helper()
+	g := NewGame(NewRNG(8), config)
+	neutralize(g)
+	g.Current.Y = g.Board.CalculateDropPosition(g.Current).Y
+	g.lowestY = g.Current.Y
+	return g
+}
+
+func TestLockDelay(t *testing.T) {
+	config := DefaultConfig()
+	g := groundedGame(t, config)
+	piece := g.Current
+	for range config.LockDelayTicks - 1 {
+		g.Step(Input{})
+	}
+	if g.Current != piece {
+		t.Fatal("piece locked before the lock delay ran out")
+	}
+	g.Step(Input{})
+	if g.Current == piece {
+		t.Fatal("piece didn't lock when the lock delay ran out")
+	}
+}
+
+func TestLockDelayResetLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.LockDelayTicks = 10
+	config.LockResets = 3
+	g := groundedGame(t, config)
+	piece := g.Current
+
+	// Tapping left and right every few ticks keeps the piece alive, but
+	// only for as many resets as allowed.
+	ticks := 0
+	for g.Current == piece && ticks < 200 {
+		g.Step(Input{Left: ticks%10 == 5, Right: ticks%10 == 0})
+		ticks++
+	}
+	if g.Current == piece {
+		t.Fatal("moving the piece kept it from ever locking")
+	}
+	if limit := config.LockDelayTicks * (config.LockResets + 1); ticks > limit {
+		t.Errorf("piece locked after %d ticks, want no more than %d", ticks, limit)
+	}
+	if ticks <= config.LockDelayTicks {
+		t.Errorf("piece locked after %d ticks; moving it should have reset the delay", ticks)
+	}
+}
+
+func TestReactionAndStormCallbacks(t *testing.T) {
+	g := NewGame(NewRNG(4), DefaultConfig())
+	bottom := g.Board.Height - 1
+	g.Board.SetPlacedBlocks([]Block{
+		{X: 0, Y: bottom, BlockType: PositiveBlock},
+		{X: 1, Y: bottom, BlockType: NegativeBlock},
+		{X: 2, Y: bottom, BlockType: PositiveBlock},
+		{X: 3, Y: bottom, BlockType: NegativeBlock},
+		{X: 8, Y: bottom, BlockType: NegativeBlock},
+		{X: 8, Y: bottom - 1, BlockType: NegativeBlock},
+		{X: 8, Y: bottom - 2, BlockType: NegativeBlock},
+		{X: 8, Y: bottom - 3, BlockType: NegativeBlock},
+	})
+
+	var reacted, points int
+	var storms []int
+	g.SetReactionStartedCallback(func(blocks []Block, reaction Reaction) {
+		reacted += len(blocks)
+		points += reaction.Points
+	})
+	g.SetStormIgnitedCallback(func(column int) { storms = append(storms, column) })
+	g.checkReactions(fromLock)
+
+	if reacted != 4 || points != 10 {
+		t.Errorf("reaction callback saw %d blocks for %d points, want 4 for 10", reacted, points)
+	}
+	if !slices.Equal(storms, []int{8}) {
+		t.Errorf("storms ignited in columns %v, want [8]", storms)
+	}
+	if g.Score != 10 {
+		t.Errorf("score %d, want 10", g.Score)
+	}
+
+	// Nothing new has happened, so a second check reports nothing.
+	reacted, storms = 0, nil
+	g.checkReactions(fromLock)
+	if reacted != 0 || len(storms) != 0 {
+		t.Errorf("second check reported %d blocks and storms %v", reacted, storms)
+	}
+}
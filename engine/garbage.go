@@ -0,0 +1,104 @@
+package engine
+
+import "math/rand/v2"
+
+// Garbage is the versus attack: rows of charged blocks, each with one gap,
+// that rise into the opponent's board from below. Big reactions and storms
+// send it, and garbage a player sends first cancels any waiting to rise
+// into their own board.
+const (
+	// GarbageBlocksPerRow is how many blocks beyond the smallest reaction
+	// of four send each row of garbage.
+	GarbageBlocksPerRow = 2
+	// StormGarbage is the rows sent for each storm ignited.
+	StormGarbage = 1
+	// MaxGarbagePerLock is the most garbage that rises after a single
+	// lock; the rest waits for the next.
+	MaxGarbagePerLock = 4
+)
+
+// garbageFor returns the rows of garbage a reaction sends: one for every
+// two blocks beyond four, and one more for each link of a chain and each
+// row past the first.
+func garbageFor(reaction Reaction) int {
+	rows := max((reaction.Blocks-4)/GarbageBlocksPerRow, 0)
+	return rows + max(reaction.Chain-1, 0) + max(reaction.Rows-1, 0)
+}
+
+// sendGarbage spends rows first on cancelling garbage waiting to rise into
+// this board and queues the rest for the opponent.
+func (g *Game) sendGarbage(rows int) {
+	cancelled := min(rows, g.garbageIn)
+	g.garbageIn -= cancelled
+	g.garbageOut += rows - cancelled
+}
+
+// ReceiveGarbage adds rows to the garbage waiting to rise into the board.
+func (g *Game) ReceiveGarbage(rows int) {
+	g.garbageIn += rows
+}
+
+// IncomingGarbage returns the rows waiting to rise into the board, for the
+// garbage meter.
+func (g *Game) IncomingGarbage() int {
+	return g.garbageIn
+}
+
+// TakeOutgoingGarbage returns the rows this game has sent since it was last
+// asked, for the caller to pass to the opponent.
+func (g *Game) TakeOutgoingGarbage() int {
+	rows := g.garbageOut
+	g.garbageOut = 0
+	return rows
+}
+
+// raiseGarbage pushes waiting garbage up into the board, as much as one
+// lock allows.
+func (g *Game) raiseGarbage() {
+	rows := min(g.garbageIn, MaxGarbagePerLock)
+	if rows == 0 {
+		return
+	}
+	g.garbageIn -= rows
+	g.Board.RaiseGarbage(rows, g.rng.Storm)
+	if g.onGarbage != nil {
+		g.onGarbage(rows)
+	}
+}
+
+// RaiseGarbage lifts every block by rows and fills the rows freed at the
+// bottom with garbage. All the rows share one gap. Each row is mostly one
+// charge with every fourth block the other, which never sums to zero over
+// four or more blocks, and the majority flips from row to row so garbage
+// never stacks four alike in a column.
+func (b *Board) RaiseGarbage(rows int, r *rand.Rand) {
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		block.Y -= rows
+		block.FallStartY -= float64(rows)
+		block.FallTargetY -= float64(rows)
+		block.ArcStartY -= float64(rows)
+		block.ArcTargetY -= float64(rows)
+	}
+
+	gap := r.IntN(b.Width)
+	phase := r.IntN(4)
+	flip := r.IntN(2)
+	for row := range rows {
+		y := b.Height - rows + row
+		majority, minority := PositiveBlock, NegativeBlock
+		if (row+flip)%2 == 1 {
+			majority, minority = minority, majority
+		}
+		for x := range b.Width {
+			if x == gap {
+				continue
+			}
+			kind := majority
+			if (x+row+phase)%4 == 0 {
+				kind = minority
+			}
+			b.placedBlocks = append(b.placedBlocks, Block{X: x, Y: y, BlockType: kind})
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestGarbageFor(t *testing.T) {
+	tests := []struct {
+		reaction Reaction
+		want     int
+	}{
+		{Reaction{Blocks: 4, Rows: 1, Chain: 1}, 0},
+		{Reaction{Blocks: 6, Rows: 1, Chain: 1}, 1},
+		{Reaction{Blocks: 10, Rows: 1, Chain: 1}, 3},
+		{Reaction{Blocks: 4, Rows: 1, Chain: 3}, 2},
+		{Reaction{Blocks: 8, Rows: 2, Chain: 2}, 4},
+	}
+	for _, tt := range tests {
+		if got := garbageFor(tt.reaction); got != tt.want {
+			t.Errorf("garbageFor(%+v) = %d, want %d", tt.reaction, got, tt.want)
+		}
+	}
+}
+
+// TestGarbageIsInert checks that however it is dealt, garbage neither
+// reacts nor storms by itself and leaves one shared gap in every row.
+func TestGarbageIsInert(t *testing.T) {
+	for seed := range uint64(50) {
+		b := NewBoard(DefaultWidth, DefaultHeight, nil)
+		b.RaiseGarbage(8, rand.New(rand.NewPCG(seed, 0)))
+
+		if blocks := b.findBlocksToRemove(); len(blocks) > 0 {
+			t.Fatalf("seed %d: garbage reacts by itself:\n%s", seed, drawBlocks(b, b.placedBlocks))
+		}
+		if blocks := b.findVerticalElectricalStorms(); len(blocks) > 0 {
+			t.Fatalf("seed %d: garbage storms by itself:\n%s", seed, drawBlocks(b, b.placedBlocks))
+		}
+		columns := b.columns()
+		gaps := 0
+		for x := range columns {
+			if columns[x][b.Height-1] < 0 {
+				gaps++
+				for y := b.Height - 8; y < b.Height; y++ {
+					if columns[x][y] >= 0 {
+						t.Fatalf("seed %d: gap in column %d is filled at row %d", seed, x, y)
+					}
+				}
+			}
+		}
+		if gaps != 1 {
+			t.Fatalf("seed %d: garbage has %d gaps, want 1", seed, gaps)
+		}
+	}
+}
+
+func TestGarbageCancels(t *testing.T) {
+	g := NewGame(NewRNG(1), DefaultConfig())
+	g.ReceiveGarbage(3)
+	g.sendGarbage(2)
+	if g.IncomingGarbage() != 1 || g.TakeOutgoingGarbage() != 0 {
+		t.Fatalf("sending 2 against 3 incoming left %d incoming", g.IncomingGarbage())
+	}
+	g.sendGarbage(4)
+	if in, out := g.IncomingGarbage(), g.TakeOutgoingGarbage(); in != 0 || out != 3 {
+		t.Errorf("sending 4 against 1 incoming left %d incoming and %d outgoing, want 0 and 3", in, out)
+	}
+}
+
+func TestGarbageRisesOnQuietLock(t *testing.T) {
+	v := NewVersus(4, DefaultConfig())
+	attacker, defender := v.Games[0], v.Games[1]
+	attacker.sendGarbage(MaxGarbagePerLock + 2)
+	v.Exchange()
+	if defender.IncomingGarbage() != MaxGarbagePerLock+2 {
+		t.Fatalf("defender has %d rows incoming, want %d", defender.IncomingGarbage(), MaxGarbagePerLock+2)
+	}
+
+	var raised int
+	defender.SetGarbageRaisedCallback(func(rows int) { raised = rows })
+	neutralize(defender)
+	defender.HardDrop()
+	if raised != MaxGarbagePerLock || defender.IncomingGarbage() != 2 {
+		t.Errorf("a quiet lock raised %d rows leaving %d, want %d leaving 2", raised, defender.IncomingGarbage(), MaxGarbagePerLock)
+	}
+	if v.Over() {
+		t.Errorf("match over after one lock")
+	}
+}
+
+func TestVersusWinner(t *testing.T) {
+	v := NewVersus(4, DefaultConfig())
+	if v.Winner() != -1 {
+		t.Fatalf("winner %d before the match ended", v.Winner())
+	}
+	v.Games[0].finish(TopOut)
+	if !v.Over() || v.Winner() != 1 {
+		t.Errorf("player 0 topped out: over %v, winner %d, want player 1", v.Over(), v.Winner())
+	}
+}
@@ -0,0 +1,65 @@
+package engine
+
+// Input is the state of every logical control during one tick. Each field is
+// true while the control is held; the game works out presses and auto-repeat
+// itself, so a recorded stream of Inputs replays exactly.
+type Input struct {
+	Left     bool
+	Right    bool
+	SoftDrop bool
+	HardDrop bool
+	// Rotate turns the piece clockwise.
+	Rotate    bool
+	RotateCCW bool
+	Rotate180 bool
+	Hold      bool
+	// Pause is carried so replays can reproduce pauses; the game itself
+	// ignores it and the caller simply stops stepping while paused.
+	Pause bool
+	// Resign ends the game at the player's request. It is offered while
+	// paused, so the caller steps the game with it even then.
+	Resign bool
+}
+
+// Handling controls how held movement keys repeat, in ticks.
+type Handling struct {
+	// DelayTicks is how long a held left or right waits before it starts
+	// repeating.
+	DelayTicks int `json:"delayTicks"`
+	// RepeatTicks is the gap between repeats once a held key is repeating.
+	RepeatTicks int `json:"repeatTicks"`
+	// SoftDropTicks is the gap between rows while soft drop is held.
+	SoftDropTicks int `json:"softDropTicks"`
+}
+
+func DefaultHandling() Handling {
+	return Handling{
+		DelayTicks:    12,
+		RepeatTicks:   6,
+		SoftDropTicks: 3,
+	}
+}
+
+// repeatTimer tracks auto-repeat for one held control.
+type repeatTimer struct {
+	ticksLeft int
+}
+
+// fire reports whether a control should act this tick given whether it was
+// held last tick and is held now.
+func (rt *repeatTimer) fire(wasHeld, held bool, delay, repeat int) bool {
+	if !held {
+		rt.ticksLeft = 0
+		return false
+	}
+	if !wasHeld {
+		rt.ticksLeft = delay
+		return true
+	}
+	rt.ticksLeft--
+	if rt.ticksLeft <= 0 {
+		rt.ticksLeft = repeat
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LeaderboardVersion is the version of the leaderboard format. Bump it when
+// Leaderboard or ScoreEntry changes shape.
+const LeaderboardVersion = 1
+
+// LeaderboardSize is how many entries each mode keeps.
+const LeaderboardSize = 10
+
+// MaxNameLength is the longest name, in characters, a ScoreEntry may carry.
+const MaxNameLength = 12
+
+// ScoreEntry is one finished game on the leaderboard.
+type ScoreEntry struct {
+	Name      string `json:"name"`
+	Score     int    `json:"score"`
+	Level     int    `json:"level"`
+	Ticks     int    `json:"ticks"`
+	BestChain int    `json:"bestChain"`
+	BestCombo int    `json:"bestCombo"`
+	Seed      int64  `json:"seed"`
+}
+
+// Duration is how long the game ran in simulated time.
+func (e ScoreEntry) Duration() time.Duration {
+	return time.Duration(e.Ticks) * time.Second / TicksPerSecond
+}
+
+// Leaderboard holds the best games for each mode: highest score first, or
+// for the tables RankedByTime, fastest first.
+type Leaderboard struct {
+	Version int                     `json:"version"`
+	Modes   map[string][]ScoreEntry `json:"modes"`
+	// LastName is the name most recently entered, offered again next time.
+	LastName string `json:"lastName"`
+}
+
+func NewLeaderboard() *Leaderboard {
+	return &Leaderboard{
+		Version: LeaderboardVersion,
+		Modes:   make(map[string][]ScoreEntry),
+	}
+}
+
+// Entries returns the table for mode, best first.
+func (l *Leaderboard) Entries(mode string) []ScoreEntry {
+	return l.Modes[mode]
+}
+
+// Qualifies reports whether entry would earn a place in mode's table.
+func (l *Leaderboard) Qualifies(mode string, entry ScoreEntry) bool {
+	if RankedByTime(mode) {
+		if entry.Ticks <= 0 {
+			return false
+		}
+	} else if entry.Score <= 0 {
+		return false
+	}
+	entries := l.Modes[mode]
+	return len(entries) < LeaderboardSize || beats(mode, entry, entries[len(entries)-1])
+}
+
+// beats reports whether a ranks above b in mode's table.
+func beats(mode string, a, b ScoreEntry) bool {
+	if RankedByTime(mode) {
+		return a.Ticks < b.Ticks
+	}
+	return a.Score > b.Score
+}
+
+// Add places entry in mode's table and returns its rank from zero, or -1 if
+// it didn't make the table. An entry ranks below earlier ones that it ties
+// with.
+func (l *Leaderboard) Add(mode string, entry ScoreEntry) int {
+	if !l.Qualifies(mode, entry) {
+		return -1
+	}
+	if name := []rune(entry.Name); len(name) > MaxNameLength {
+		entry.Name = string(name[:MaxNameLength])
+	}
+
+	entries := l.Modes[mode]
+	rank := len(entries)
+	for i, existing := range entries {
+		if beats(mode, entry, existing) {
+			rank = i
+			break
+		}
+	}
+	entries = append(entries, ScoreEntry{})
+	copy(entries[rank+1:], entries[rank:])
+	entries[rank] = entry
+	if len(entries) > LeaderboardSize {
+		entries = entries[:LeaderboardSize]
+	}
+	l.Modes[mode] = entries
+	return rank
+}
+
+func WriteLeaderboard(w io.Writer, l *Leaderboard) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+func ReadLeaderboard(r io.Reader) (*Leaderboard, error) {
+	l := NewLeaderboard()
+	if err := json.NewDecoder(r).Decode(l); err != nil {
+		return nil, err
+	}
+	if l.Version != LeaderboardVersion {
+		return nil, fmt.Errorf("unsupported leaderboard version %d", l.Version)
+	}
+	if l.Modes == nil {
+		l.Modes = make(map[string][]ScoreEntry)
+	}
+	return l, nil
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestLeaderboardKeepsBestScores(t *testing.T) {
+	l := NewLeaderboard()
+	for score := 1; score <= LeaderboardSize; score++ {
+		if rank := l.Add("marathon", ScoreEntry{Score: score * 100}); rank != 0 {
+			t.Fatalf("score %d ranked %d, want 0", score*100, rank)
+		}
+	}
+
+	if l.Qualifies("marathon", ScoreEntry{Score: 100}) {
+		t.Error("a score equal to the lowest on a full table qualified")
+	}
+	if rank := l.Add("marathon", ScoreEntry{Name: "TIE", Score: 500}); rank != 6 {
+		t.Errorf("a tying score ranked %d, want 6 below the earlier 500", rank)
+	}
+
+	entries := l.Entries("marathon")
+	if len(entries) != LeaderboardSize {
+		t.Fatalf("table holds %d entries, want %d", len(entries), LeaderboardSize)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Score > entries[i-1].Score {
+			t.Fatalf("entries out of order: %d above %d", entries[i-1].Score, entries[i].Score)
+		}
+	}
+	if last := entries[len(entries)-1].Score; last != 200 {
+		t.Errorf("lowest kept score %d, want 200", last)
+	}
+
+	if !l.Qualifies("zen", ScoreEntry{Score: 1}) {
+		t.Error("a score didn't qualify for an empty table")
+	}
+	if l.Qualifies("zen", ScoreEntry{}) {
+		t.Error("a score of zero qualified")
+	}
+}
+
+func TestLeaderboardRanksSprintsByTime(t *testing.T) {
+	l := NewLeaderboard()
+	table := LeaderboardTable(Config{Mode: SprintMode, SprintGoal: 40})
+	for _, ticks := range []int{3000, 1000, 2000} {
+		l.Add(table, ScoreEntry{Score: 10, Ticks: ticks})
+	}
+	if rank := l.Add(table, ScoreEntry{Score: 1, Ticks: 1500}); rank != 1 {
+		t.Errorf("a 1500-tick sprint with a low score ranked %d, want 1", rank)
+	}
+	var got []int
+	for _, e := range l.Entries(table) {
+		got = append(got, e.Ticks)
+	}
+	if want := []int{1000, 1500, 2000, 3000}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sprint table ordered %v, want %v", got, want)
+	}
+	if l.Qualifies(table, ScoreEntry{Score: 500}) {
+		t.Error("a sprint with no time qualified")
+	}
+}
+
+func TestLeaderboardRoundTrip(t *testing.T) {
+	l := NewLeaderboard()
+	l.LastName = "ADA"
+	l.Add("marathon", ScoreEntry{
+		Name: "ADA", Score: 1234, Level: 3, Ticks: 3600,
+		BestChain: 3, BestCombo: 2, Seed: 42,
+	})
+
+	var buf bytes.Buffer
+	if err := WriteLeaderboard(&buf, l); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadLeaderboard(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("read back %+v, want %+v", got, l)
+	}
+	if d := got.Entries("marathon")[0].Duration().Seconds(); d != 60 {
+		t.Errorf("3600 ticks lasted %v seconds, want 60", d)
+	}
+}
@@ -0,0 +1,29 @@
+package engine
+
+// DefaultGravityCurve is how many ticks a piece hangs before each row it
+// falls, indexed by level from level 1. Levels past the end of the curve
+// keep its last value.
+var DefaultGravityCurve = []int{60, 53, 46, 40, 34, 29, 24, 20, 16, 13, 10, 8, 6, 5, 4, 3, 2, 1}
+
+// DefaultBlocksPerLevel is how many blocks must be neutralized to go up a
+// level.
+const DefaultBlocksPerLevel = 20
+
+// gravityTicks returns the gravity interval for level.
+func (c Config) gravityTicks(level int) int {
+	curve := c.GravityCurve
+	if len(curve) == 0 {
+		curve = DefaultGravityCurve
+	}
+	i := min(max(level-1, 0), len(curve)-1)
+	return max(curve[i], 1)
+}
+
+// levelFor returns the level reached after neutralizing blocks.
+func (c Config) levelFor(blocks int) int {
+	perLevel := c.BlocksPerLevel
+	if perLevel <= 0 {
+		perLevel = DefaultBlocksPerLevel
+	}
+	return 1 + blocks/perLevel
+}
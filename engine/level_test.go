@@ -0,0 +1,61 @@
+package engine
+
+import "testing"
+
+func TestGravityTicks(t *testing.T) {
+	config := Config{GravityCurve: []int{30, 20, 10}}
+	tests := []struct {
+		level int
+		want  int
+	}{
+		{0, 30},
+		{1, 30},
+		{2, 20},
+		{3, 10},
+		{50, 10},
+	}
+	for _, tt := range tests {
+		if got := config.gravityTicks(tt.level); got != tt.want {
+			t.Errorf("gravityTicks(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+	if got := (Config{}).gravityTicks(1); got != DefaultGravityCurve[0] {
+		t.Errorf("gravityTicks with no curve = %d, want the default %d", got, DefaultGravityCurve[0])
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	config := Config{BlocksPerLevel: 10}
+	for _, tt := range []struct{ blocks, want int }{{0, 1}, {9, 1}, {10, 2}, {35, 4}} {
+		if got := config.levelFor(tt.blocks); got != tt.want {
+			t.Errorf("levelFor(%d) = %d, want %d", tt.blocks, got, tt.want)
+		}
+	}
+}
+
+func TestClearingBlocksRaisesLevel(t *testing.T) {
+	config := DefaultConfig()
+	config.BlocksPerLevel = 4
+	g := NewGame(NewRNG(2), config)
+	neutralize(g)
+	bottom := g.Board.Height - 1
+	g.Board.SetPlacedBlocks([]Block{
+		{X: 0, Y: bottom, BlockType: PositiveBlock},
+		{X: 1, Y: bottom, BlockType: NegativeBlock},
+		{X: 2, Y: bottom, BlockType: PositiveBlock},
+		{X: 3, Y: bottom, BlockType: NegativeBlock},
+	})
+	g.Board.CheckForNewReactions()
+
+	for range WobbleTicks {
+		g.Step(Input{})
+	}
+	if g.BlocksCleared() != 4 || g.Level() != 2 {
+		t.Fatalf("cleared %d blocks, level %d; want 4 blocks, level 2", g.BlocksCleared(), g.Level())
+	}
+
+	row := []Block{{X: 0, Y: bottom}, {X: 1, Y: bottom}, {X: 2, Y: bottom}, {X: 3, Y: bottom}}
+	if got := g.scoreReaction(row, fromLock).Points; got != 20 {
+		t.Errorf("a four block reaction at level 2 scored %d, want 20", got)
+	}
+}
@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GameMode is what a game is played for: when it ends besides the stack
+// reaching the top, whether reaching the top ends it at all, which endings
+// count toward the leaderboard, and what the HUD shows of its progress.
+type GameMode interface {
+	Kind() ModeKind
+	// Check returns why the game ends after the tick just played, or ""
+	// while it goes on.
+	Check(g *Game) EndCause
+	// Forgiving reports whether topping out clears the stack rather than
+	// ending the game.
+	Forgiving() bool
+	// Ranked reports whether a game that ended for cause counts toward
+	// the leaderboard.
+	Ranked(cause EndCause) bool
+	// Status returns a heading and value for the HUD, such as the blocks
+	// a sprint still has to clear.
+	Status(g *Game) (label, value string)
+}
+
+// ModeKind names a GameMode.
+type ModeKind string
+
+const (
+	// ClassicMode is endless play until the stack reaches the top.
+	ClassicMode ModeKind = "classic"
+	// SprintMode is a race to neutralize a set number of blocks.
+	SprintMode ModeKind = "sprint"
+	// UltraMode is the best score in a fixed time.
+	UltraMode ModeKind = "ultra"
+	// ZenMode is endless play without pressure: topping out clears the
+	// stack and storms never throw blocks where they would top you out.
+	ZenMode ModeKind = "zen"
+)
+
+// ModeKinds lists every mode that can be chosen.
+var ModeKinds = []ModeKind{ClassicMode, SprintMode, UltraMode, ZenMode}
+
+const (
+	// GoalReached ends a sprint that cleared its blocks.
+	GoalReached EndCause = "goalReached"
+	// TimeUp ends an ultra game when its time runs out.
+	TimeUp EndCause = "timeUp"
+	// Resigned is a game the player chose to end.
+	Resigned EndCause = "resigned"
+)
+
+// Defaults for the mode settings in Config.
+const (
+	DefaultSprintGoal   = 100
+	DefaultUltraSeconds = 180
+	// zenSafeRows is how far from the top a Zen storm won't throw a block.
+	zenSafeRows = 4
+)
+
+func ParseMode(name string) (ModeKind, error) {
+	kind := ModeKind(name)
+	if !slices.Contains(ModeKinds, kind) {
+		return "", fmt.Errorf("unknown mode %q (want one of %v)", name, ModeKinds)
+	}
+	return kind, nil
+}
+
+// NewGameMode creates the mode config asks for. Unknown kinds, and configs
+// from before there were modes, play classic; a goal or time limit left
+// unset gets the default.
+func NewGameMode(config Config) GameMode {
+	if config.Puzzle != nil {
+		return puzzleMode{moves: config.Puzzle.MoveLimit()}
+	}
+	switch config.Mode {
+	case SprintMode:
+		goal := config.SprintGoal
+		if goal <= 0 {
+			goal = DefaultSprintGoal
+		}
+		return sprintMode{goal: goal}
+	case UltraMode:
+		seconds := config.UltraSeconds
+		if seconds <= 0 {
+			seconds = DefaultUltraSeconds
+		}
+		return ultraMode{limit: seconds * TicksPerSecond}
+	case ZenMode:
+		return zenMode{}
+	}
+	return classicMode{}
+}
+
+// LeaderboardTable names the leaderboard table for games under config.
+// Sprints and ultras keep a table for each goal and time limit, and
+// classic games keep the "marathon" table they had before there were
+// modes.
+func LeaderboardTable(config Config) string {
+	switch mode := NewGameMode(config).(type) {
+	case sprintMode:
+		return fmt.Sprintf("%s-%d", SprintMode, mode.goal)
+	case ultraMode:
+		return fmt.Sprintf("%s-%ds", UltraMode, mode.limit/TicksPerSecond)
+	case zenMode:
+		return string(ZenMode)
+	case puzzleMode:
+		return string(PuzzleMode)
+	}
+	return "marathon"
+}
+
+// RankedByTime reports whether a leaderboard table ranks the fastest games
+// first rather than the highest scores.
+func RankedByTime(table string) bool {
+	return strings.HasPrefix(table, string(SprintMode)+"-")
+}
+
+type classicMode struct{}
+
+func (classicMode) Kind() ModeKind             { return ClassicMode }
+func (classicMode) Check(*Game) EndCause       { return "" }
+func (classicMode) Forgiving() bool            { return false }
+func (classicMode) Ranked(cause EndCause) bool { return cause == TopOut || cause == BlockOut }
+
+func (classicMode) Status(g *Game) (string, string) {
+	return "LEVEL", fmt.Sprintf("%d", g.Level())
+}
+
+type sprintMode struct {
+	goal int
+}
+
+func (sprintMode) Kind() ModeKind  { return SprintMode }
+func (sprintMode) Forgiving() bool { return false }
+
+// Ranked counts only finished sprints: the table is of times, and a sprint
+// that topped out has none.
+func (sprintMode) Ranked(cause EndCause) bool { return cause == GoalReached }
+
+func (m sprintMode) Check(g *Game) EndCause {
+	if g.BlocksCleared() >= m.goal {
+		return GoalReached
+	}
+	return ""
+}
+
+func (m sprintMode) Status(g *Game) (string, string) {
+	return fmt.Sprintf("%d LEFT", max(m.goal-g.BlocksCleared(), 0)), FormatTicks(g.Tick(), true)
+}
+
+type ultraMode struct {
+	limit int
+}
+
+func (ultraMode) Kind() ModeKind  { return UltraMode }
+func (ultraMode) Forgiving() bool { return false }
+
+func (ultraMode) Ranked(cause EndCause) bool {
+	return cause == TimeUp || cause == TopOut || cause == BlockOut
+}
+
+func (m ultraMode) Check(g *Game) EndCause {
+	if g.Tick() >= m.limit {
+		return TimeUp
+	}
+	return ""
+}
+
+func (m ultraMode) Status(g *Game) (string, string) {
+	return "TIME LEFT", FormatTicks(max(m.limit-g.Tick(), 0), false)
+}
+
+type zenMode struct{}
+
+func (zenMode) Kind() ModeKind       { return ZenMode }
+func (zenMode) Check(*Game) EndCause { return "" }
+func (zenMode) Forgiving() bool      { return true }
+
+// Ranked counts the only way a Zen game ends, the player calling it a day.
+func (zenMode) Ranked(cause EndCause) bool { return cause == Resigned }
+
+func (zenMode) Status(g *Game) (string, string) {
+	return "ZEN", FormatTicks(g.Tick(), false)
+}
+
+// FormatTicks shows a span of ticks as minutes and seconds, with
+// hundredths if precise.
+func FormatTicks(ticks int, precise bool) string {
+	seconds := ticks / TicksPerSecond
+	if !precise {
+		return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+	}
+	hundredths := ticks % TicksPerSecond * 100 / TicksPerSecond
+	return fmt.Sprintf("%d:%02d.%02d", seconds/60, seconds%60, hundredths)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import "testing"
+
+func TestUltraEndsWhenTimeRunsOut(t *testing.T) {
+	config := DefaultConfig()
+	config.Mode = UltraMode
+	config.UltraSeconds = 2
+	g := NewGame(NewRNG(4), config)
+	for !g.IsOver() {
+		g.Step(Input{})
+	}
+	if g.EndCause() != TimeUp || g.Tick() != 2*TicksPerSecond {
+		t.Errorf("ultra ended with %q at tick %d, want %q at %d", g.EndCause(), g.Tick(), TimeUp, 2*TicksPerSecond)
+	}
+	if !g.Mode().Ranked(g.EndCause()) {
+		t.Error("an ultra game that ran out of time wasn't ranked")
+	}
+}
+
+func TestSprintEndsAtGoal(t *testing.T) {
+	config := DefaultConfig()
+	config.Mode = SprintMode
+	config.SprintGoal = 8
+	r := Simulate(6, config, NewBot(DefaultBotWeights(), 1), 5*60*TicksPerSecond)
+	if r.End != GoalReached || r.Cleared < 8 {
+		t.Fatalf("sprint ended with %q after clearing %d blocks, want %q after 8", r.End, r.Cleared, GoalReached)
+	}
+	if (sprintMode{goal: 8}).Ranked(TopOut) {
+		t.Error("a sprint that topped out was ranked")
+	}
+}
+
+// TestZenForgivesToppingOut drops a piece on a column stacked to the top,
+// which ends a classic game.
+func TestZenForgivesToppingOut(t *testing.T) {
+	for _, kind := range []ModeKind{ClassicMode, ZenMode} {
+		config := DefaultConfig()
+		config.Mode = kind
+		g := NewGame(NewRNG(8), config)
+		var column []Block
+		for y := 2; y < g.Board.Height; y++ {
+			column = append(column, Block{X: g.Board.SpawnColumn(), Y: y, BlockType: PositiveBlock})
+		}
+		g.Board.SetPlacedBlocks(column)
+		g.Step(Input{HardDrop: true})
+
+		switch {
+		case kind == ClassicMode && g.EndCause() != TopOut:
+			t.Errorf("classic game ended with %q after topping out, want %q", g.EndCause(), TopOut)
+		case kind == ZenMode && g.IsOver():
+			t.Errorf("zen game ended with %q", g.EndCause())
+		case kind == ZenMode && len(g.Board.GetPlacedBlocks()) != 0:
+			t.Errorf("zen left %d blocks after topping out, want the stack cleared", len(g.Board.GetPlacedBlocks()))
+		}
+	}
+
+	config := DefaultConfig()
+	config.Mode = ZenMode
+	g := NewGame(NewRNG(8), config)
+	g.Step(Input{Resign: true})
+	if g.EndCause() != Resigned || !g.Mode().Ranked(Resigned) {
+		t.Errorf("resigning ended zen with %q", g.EndCause())
+	}
+}
+
+func TestModeDefaults(t *testing.T) {
+	if _, err := ParseMode("marathon"); err == nil {
+		t.Error("parsed an unknown mode")
+	}
+	for _, kind := range ModeKinds {
+		if got, err := ParseMode(string(kind)); err != nil || got != kind {
+			t.Errorf("ParseMode(%q) = %q, %v", kind, got, err)
+		}
+	}
+
+	tables := map[string]Config{
+		"marathon":   {},
+		"sprint-100": {Mode: SprintMode},
+		"sprint-40":  {Mode: SprintMode, SprintGoal: 40},
+		"ultra-120s": {Mode: UltraMode, UltraSeconds: 120},
+		"zen":        {Mode: ZenMode},
+	}
+	for want, config := range tables {
+		if got := LeaderboardTable(config); got != want {
+			t.Errorf("%+v keeps scores in %q, want %q", config, got, want)
+		}
+	}
+	if !RankedByTime("sprint-40") || RankedByTime("ultra-120s") {
+		t.Error("only sprint tables should rank by time")
+	}
+}
@@ -0,0 +1,440 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Netplay runs a versus match between two machines in lockstep. Both play
+// the same deterministic match; all that crosses the wire is each player's
+// input for every tick. Local input is sent a few ticks before it is played,
+// so the opponent's usually arrives in time and a tick only waits when the
+// network is slower than the delay.
+//
+// Every message is a frame: a four-byte big-endian length, then the payload,
+// whose first byte says what it is. The joining peer says hello with its
+// protocol and rules versions; the host answers with the seed, input delay
+// and rules for the match, or with the reason it won't play.
+const (
+	NetProtocolVersion = 1
+	DefaultNetPort     = 7777
+	// DefaultInputDelay is how many ticks ahead input is sent: 50ms at 60
+	// ticks a second, plenty for a LAN.
+	DefaultInputDelay = 3
+
+	// maxNetFrame bounds a frame, so a corrupt length can't ask for a huge
+	// allocation.
+	maxNetFrame = 64 << 10
+	// netChecksumInterval is how often, in ticks, the peers compare their
+	// matches to catch a desync.
+	netChecksumInterval = TicksPerSecond
+	// netTimeout is how long the opponent may go quiet before they are
+	// taken to have gone.
+	netTimeout = 10 * time.Second
+)
+
+const (
+	netHello byte = iota + 1
+	netWelcome
+	netReject
+	netInput
+	netChecksum
+	netBye
+)
+
+var (
+	ErrPeerLeft = errors.New("opponent left the match")
+	ErrDesync   = errors.New("the two games have drifted apart")
+)
+
+// NetSession is one side of a networked match.
+type NetSession struct {
+	Match *Versus
+	// Local is the board this machine plays: 0 for the host, 1 for the
+	// peer that joined.
+	Local int
+	Delay int
+	// Step plays a tick of the match on both players' input. It is
+	// Match.Step unless the caller steps the games itself, as the game
+	// scene does to keep its effects in step.
+	Step func(inputs [2]Input)
+
+	conn net.Conn
+	// pending holds local input sent but not yet played, by tick.
+	pending map[int]Input
+	// sums holds local checksums the opponent hasn't confirmed, by tick.
+	sums map[int]uint64
+	sent int
+	tick int
+	// saidBye is set once the opponent has been told this side is done.
+	saidBye bool
+
+	// The reader goroutine fills these, and closes done when it stops.
+	done       chan struct{}
+	mu         sync.Mutex
+	remote     map[int]Input
+	remoteSums map[int]uint64
+	err        error
+}
+
+// HostNetMatch starts a match with the peer on conn once they say hello,
+// dealt from seed under config. The host plays board 0. A peer on other
+// versions is told why it can't play and hung up on.
+func HostNetMatch(conn net.Conn, seed int64, config Config, delay int) (*NetSession, error) {
+	conn.SetDeadline(time.Now().Add(netTimeout))
+	payload, err := readNetFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(payload)
+	kind, _ := r.ReadByte()
+	protocol, err1 := binary.ReadUvarint(r)
+	rules, err2 := binary.ReadUvarint(r)
+	if kind != netHello || err1 != nil || err2 != nil {
+		return nil, errors.New("peer did not say hello")
+	}
+	if protocol != NetProtocolVersion || rules != RulesVersion {
+		reason := fmt.Sprintf("version mismatch: host has protocol %d rules %d, you have protocol %d rules %d",
+			NetProtocolVersion, RulesVersion, protocol, rules)
+		var b bytes.Buffer
+		b.WriteByte(netReject)
+		writeNetString(&b, reason)
+		writeNetFrame(conn, b.Bytes())
+		conn.Close()
+		return nil, errors.New(reason)
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteByte(netWelcome)
+	writeVarint(&b, seed)
+	writeUvarint(&b, uint64(delay))
+	writeNetString(&b, string(configJSON))
+	if err := writeNetFrame(conn, b.Bytes()); err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return newNetSession(conn, NewVersus(seed, config), 0, delay)
+}
+
+// JoinNetMatch says hello to the host on conn and starts the match it
+// offers. The joining peer plays board 1.
+func JoinNetMatch(conn net.Conn) (*NetSession, error) {
+	conn.SetDeadline(time.Now().Add(netTimeout))
+	var b bytes.Buffer
+	b.WriteByte(netHello)
+	writeUvarint(&b, NetProtocolVersion)
+	writeUvarint(&b, RulesVersion)
+	if err := writeNetFrame(conn, b.Bytes()); err != nil {
+		return nil, err
+	}
+
+	payload, err := readNetFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(payload)
+	switch kind, _ := r.ReadByte(); kind {
+	case netWelcome:
+	case netReject:
+		reason, err := readNetString(r)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("host refused: %s", reason)
+	default:
+		return nil, errors.New("host did not answer hello")
+	}
+	seed, err := binary.ReadVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	delay, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	configJSON, err := readNetString(r)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parseReplayConfig(configJSON)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return newNetSession(conn, NewVersus(seed, config), 1, int(delay))
+}
+
+// newNetSession starts reading from the opponent and sends the empty input
+// that covers the ticks before the delay.
+func newNetSession(conn net.Conn, match *Versus, local, delay int) (*NetSession, error) {
+	s := &NetSession{
+		Match:      match,
+		Local:      local,
+		Delay:      delay,
+		Step:       match.Step,
+		conn:       conn,
+		done:       make(chan struct{}),
+		pending:    make(map[int]Input),
+		sums:       make(map[int]uint64),
+		remote:     make(map[int]Input),
+		remoteSums: make(map[int]uint64),
+	}
+	go s.read()
+	for s.sent < delay {
+		if err := s.sendInput(Input{}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Tick returns the next tick to be played.
+func (s *NetSession) Tick() int {
+	return s.tick
+}
+
+// Update sends this tick's local input and plays the next tick if the
+// opponent's input for it has arrived, reporting whether it did. Pausing
+// isn't possible online, so the pause control is dropped, and resigning
+// with it. An error means the match can't go on.
+func (s *NetSession) Update(local Input) (bool, error) {
+	if s.Match.Over() {
+		return false, nil
+	}
+	local.Pause = false
+	local.Resign = false
+	if s.sent <= s.tick+s.Delay {
+		if err := s.sendInput(local); err != nil {
+			return false, s.broken(err)
+		}
+	}
+
+	s.mu.Lock()
+	remote, ok := s.remote[s.tick]
+	delete(s.remote, s.tick)
+	err := s.err
+	s.mu.Unlock()
+	if !ok {
+		// Input already received is played out before a lost connection
+		// is reported.
+		return false, err
+	}
+
+	var inputs [2]Input
+	inputs[s.Local] = s.pending[s.tick]
+	inputs[1-s.Local] = remote
+	delete(s.pending, s.tick)
+	s.Step(inputs)
+	s.tick++
+
+	if s.tick%netChecksumInterval == 0 || s.Match.Over() {
+		sum, err := s.checksum()
+		if err != nil {
+			return true, err
+		}
+		s.sums[s.tick] = sum
+		var b bytes.Buffer
+		b.WriteByte(netChecksum)
+		writeUvarint(&b, uint64(s.tick))
+		binary.Write(&b, binary.BigEndian, sum)
+		if err := s.send(b.Bytes()); err != nil {
+			return true, s.broken(err)
+		}
+	}
+	if s.Match.Over() {
+		// Nothing more will be sent, so say so rather than leave the
+		// opponent waiting out the timeout.
+		s.bye()
+	}
+	return true, s.compareSums()
+}
+
+// Close tells the opponent this side is leaving and hangs up.
+func (s *NetSession) Close() error {
+	s.bye()
+	return s.conn.Close()
+}
+
+func (s *NetSession) bye() {
+	if !s.saidBye {
+		s.saidBye = true
+		s.send([]byte{netBye})
+	}
+}
+
+// broken returns why the connection failed once writing to it has. The
+// reader usually knows better, such as that the opponent said goodbye
+// before hanging up, so it is given a moment to finish.
+func (s *NetSession) broken(err error) error {
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	return err
+}
+
+func (s *NetSession) sendInput(input Input) error {
+	var b bytes.Buffer
+	b.WriteByte(netInput)
+	writeUvarint(&b, uint64(s.sent))
+	writeUvarint(&b, input.bits())
+	if err := s.send(b.Bytes()); err != nil {
+		return err
+	}
+	s.pending[s.sent] = input
+	s.sent++
+	return nil
+}
+
+func (s *NetSession) send(payload []byte) error {
+	s.conn.SetWriteDeadline(time.Now().Add(netTimeout))
+	return writeNetFrame(s.conn, payload)
+}
+
+// read takes the opponent's messages until the connection fails or they
+// leave, and records why it stopped.
+func (s *NetSession) read() {
+	err := s.readMessages()
+	if errors.Is(err, io.EOF) {
+		err = ErrPeerLeft
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.done)
+}
+
+func (s *NetSession) readMessages() error {
+	for {
+		s.conn.SetReadDeadline(time.Now().Add(netTimeout))
+		payload, err := readNetFrame(s.conn)
+		if err != nil {
+			return err
+		}
+		r := bytes.NewReader(payload)
+		kind, _ := r.ReadByte()
+		switch kind {
+		case netInput:
+			tick, err1 := binary.ReadUvarint(r)
+			bits, err2 := binary.ReadUvarint(r)
+			if err1 != nil || err2 != nil {
+				return errors.New("corrupt input from opponent")
+			}
+			s.mu.Lock()
+			s.remote[int(tick)] = inputFromBits(bits)
+			s.mu.Unlock()
+		case netChecksum:
+			tick, err1 := binary.ReadUvarint(r)
+			var sum uint64
+			err2 := binary.Read(r, binary.BigEndian, &sum)
+			if err1 != nil || err2 != nil {
+				return errors.New("corrupt checksum from opponent")
+			}
+			s.mu.Lock()
+			s.remoteSums[int(tick)] = sum
+			s.mu.Unlock()
+		case netBye:
+			return ErrPeerLeft
+		default:
+			return fmt.Errorf("unexpected message %d from opponent", kind)
+		}
+	}
+}
+
+// compareSums checks the opponent's checksums against the ones played here.
+func (s *NetSession) compareSums() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for tick, theirs := range s.remoteSums {
+		ours, ok := s.sums[tick]
+		if !ok {
+			continue
+		}
+		if ours != theirs {
+			return fmt.Errorf("%w at tick %d", ErrDesync, tick)
+		}
+		delete(s.sums, tick)
+		delete(s.remoteSums, tick)
+	}
+	return nil
+}
+
+// checksum hashes the whole match, as a save would record it.
+func (s *NetSession) checksum() (uint64, error) {
+	h := fnv.New64a()
+	for _, g := range s.Match.Games {
+		state, err := g.Snapshot()
+		if err != nil {
+			return 0, err
+		}
+		if err := json.NewEncoder(h).Encode(state); err != nil {
+			return 0, err
+		}
+	}
+	return h.Sum64(), nil
+}
+
+func writeNetFrame(w io.Writer, payload []byte) error {
+	if len(payload) > maxNetFrame {
+		return fmt.Errorf("message of %d bytes is too long to send", len(payload))
+	}
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[4:], payload)
+	_, err := w.Write(frame)
+	return err
+}
+
+func readNetFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(header[:])
+	if n == 0 || n > maxNetFrame {
+		return nil, fmt.Errorf("corrupt message length %d", n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func writeNetString(b *bytes.Buffer, s string) {
+	writeUvarint(b, uint64(len(s)))
+	b.WriteString(s)
+}
+
+func readNetString(r *bytes.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(r.Len()) {
+		return "", errors.New("corrupt string in message")
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,242 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	for _, payload := range [][]byte{{netBye}, bytes.Repeat([]byte{netInput}, 300)} {
+		if err := writeNetFrame(&buf, payload); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readNetFrame(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Errorf("frame of %d bytes came back as %d", len(payload), len(got))
+		}
+	}
+
+	if err := writeNetFrame(&buf, make([]byte, maxNetFrame+1)); err == nil {
+		t.Errorf("wrote a frame over the limit")
+	}
+	buf.Reset()
+	buf.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	if _, err := readNetFrame(&buf); err == nil {
+		t.Errorf("read a frame with a corrupt length")
+	}
+}
+
+// connectNet starts a match between two sessions over a real TCP
+// connection on localhost.
+func connectNet(t *testing.T, seed int64) (host, guest *NetSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	type result struct {
+		s   *NetSession
+		err error
+	}
+	hosted := make(chan result)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			hosted <- result{nil, err}
+			return
+		}
+		s, err := HostNetMatch(conn, seed, DefaultConfig(), DefaultInputDelay)
+		hosted <- result{s, err}
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	guest, err = JoinNetMatch(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := <-hosted
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	t.Cleanup(func() {
+		r.s.Close()
+		guest.Close()
+	})
+	return r.s, guest
+}
+
+// playNet runs s with a bot at its board until it has played ticks or the
+// match ends.
+func playNet(s *NetSession, ticks int) error {
+	bot := NewBot(DefaultBotWeights(), 2)
+	deadline := time.Now().Add(30 * time.Second)
+	for s.Tick() < ticks && !s.Match.Over() {
+		advanced, err := s.Update(bot.Input(s.Match.Games[s.Local].View()))
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			if time.Now().After(deadline) {
+				return errors.New("match stalled")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// TestNetLockstep plays two bots against each other across localhost and
+// checks both machines saw the same match, and the same match a local
+// versus plays from their inputs.
+func TestNetLockstep(t *testing.T) {
+	host, guest := connectNet(t, 12)
+	if host.Local != 0 || guest.Local != 1 {
+		t.Fatalf("host plays board %d and guest %d, want 0 and 1", host.Local, guest.Local)
+	}
+
+	var inputs [][2]Input
+	step := host.Step
+	host.Step = func(in [2]Input) {
+		inputs = append(inputs, in)
+		step(in)
+	}
+
+	const ticks = 20 * TicksPerSecond
+	errs := make(chan error, 2)
+	for _, s := range []*NetSession{host, guest} {
+		go func() { errs <- playNet(s, ticks) }()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if host.Tick() != guest.Tick() {
+		t.Fatalf("host stopped at tick %d, guest at %d", host.Tick(), guest.Tick())
+	}
+	hostSum, _ := host.checksum()
+	guestSum, _ := guest.checksum()
+	if hostSum != guestSum {
+		t.Fatalf("host and guest finished with different matches")
+	}
+
+	local := NewVersus(12, DefaultConfig())
+	for _, in := range inputs {
+		local.Step(in)
+	}
+	for i, g := range local.Games {
+		if g.Score != host.Match.Games[i].Score || g.Pieces() != host.Match.Games[i].Pieces() {
+			t.Errorf("board %d played locally to score %d after %d pieces, over the network to %d after %d",
+				i, g.Score, g.Pieces(), host.Match.Games[i].Score, host.Match.Games[i].Pieces())
+		}
+	}
+	if local.Games[1].Pieces() == 0 {
+		t.Errorf("the guest's bot never placed a piece")
+	}
+}
+
+func TestNetDesync(t *testing.T) {
+	host, guest := connectNet(t, 5)
+	guest.Match.Games[0].Score += 100
+
+	errs := make(chan error, 2)
+	for _, s := range []*NetSession{host, guest} {
+		go func() { errs <- playNet(s, 3*netChecksumInterval) }()
+	}
+	err := <-errs
+	if !errors.Is(err, ErrDesync) {
+		t.Errorf("tampered match ended with %v, want a desync", err)
+	}
+}
+
+func TestNetPeerLeft(t *testing.T) {
+	host, guest := connectNet(t, 7)
+	guest.Close()
+	if err := playNet(host, 10*TicksPerSecond); !errors.Is(err, ErrPeerLeft) {
+		t.Errorf("host ended with %v after the guest left, want %v", err, ErrPeerLeft)
+	}
+}
+
+func TestNetRejectsOtherVersions(t *testing.T) {
+	hostConn, guestConn := net.Pipe()
+	defer hostConn.Close()
+	defer guestConn.Close()
+
+	hostErr := make(chan error, 1)
+	go func() {
+		_, err := HostNetMatch(hostConn, 1, DefaultConfig(), DefaultInputDelay)
+		hostErr <- err
+	}()
+
+	var b bytes.Buffer
+	b.WriteByte(netHello)
+	writeUvarint(&b, NetProtocolVersion)
+	writeUvarint(&b, RulesVersion+1)
+	if err := writeNetFrame(guestConn, b.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	payload, err := readNetFrame(guestConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload[0] != netReject || !strings.Contains(string(payload), "version mismatch") {
+		t.Errorf("host answered a newer rules version with %q, want a rejection", payload)
+	}
+	if err := <-hostErr; err == nil {
+		t.Errorf("host started a match with a peer on other rules")
+	}
+	if _, err := readNetFrame(guestConn); !errors.Is(err, io.EOF) {
+		t.Errorf("host left the rejected peer's connection open: %v", err)
+	}
+}
+
+// TestNetSaysByeAtEnd checks each side hears the other is done as soon as
+// the match ends, rather than after the timeout.
+func TestNetSaysByeAtEnd(t *testing.T) {
+	host, guest := connectNet(t, 3)
+	var tower []Block
+	for y := range DefaultHeight {
+		tower = append(tower, Block{X: host.Match.Games[1].Board.SpawnColumn(), Y: y, BlockType: NeutralBlock})
+	}
+	for _, s := range []*NetSession{host, guest} {
+		s.Match.Games[1].Board.SetPlacedBlocks(tower)
+	}
+
+	errs := make(chan error, 2)
+	for _, s := range []*NetSession{host, guest} {
+		go func() { errs <- playNet(s, 10*TicksPerSecond) }()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range []*NetSession{host, guest} {
+		if !s.Match.Over() {
+			t.Fatalf("match still going at tick %d", s.Tick())
+		}
+		select {
+		case <-s.done:
+		case <-time.After(netTimeout / 2):
+			t.Fatalf("board %d never heard the match was over", s.Local)
+		}
+		if !errors.Is(s.err, ErrPeerLeft) {
+			t.Errorf("board %d stopped reading with %v, want %v", s.Local, s.err, ErrPeerLeft)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package engine
+
+type PieceType int
+
+const (
+	IPiece PieceType = iota
+	OPiece
+	TPiece
+	SPiece
+	ZPiece
+	JPiece
+	LPiece
+)
+
+// PieceTypes lists every tetromino in spawn-table order.
+var PieceTypes = []PieceType{IPiece, OPiece, TPiece, SPiece, ZPiece, JPiece, LPiece}
+
+type Piece struct {
+	Type     PieceType
+	Blocks   []Block
+	X, Y     int
+	Rotation int
+}
+
+// pieceShapes holds each piece's cells in its spawn orientation, laid out
+// in the box it rotates within.
+var pieceShapes = map[PieceType][]struct{ X, Y int }{
+	IPiece: {{0, 1}, {1, 1}, {2, 1}, {3, 1}},
+	OPiece: {{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+	TPiece: {{1, 0}, {0, 1}, {1, 1}, {2, 1}},
+	SPiece: {{1, 0}, {2, 0}, {0, 1}, {1, 1}},
+	ZPiece: {{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+	JPiece: {{0, 0}, {0, 1}, {1, 1}, {2, 1}},
+	LPiece: {{2, 0}, {0, 1}, {1, 1}, {2, 1}},
+}
+
+// boxSize is the width and height of the square a piece rotates within:
+// 4 for the I, 2 for the O and 3 for the rest.
+func boxSize(pieceType PieceType) int {
+	switch pieceType {
+	case IPiece:
+		return 4
+	case OPiece:
+		return 2
+	}
+	return 3
+}
+
+func GetPieceBlocks(pieceType PieceType, rotation int, genBlockType func() BlockType) []Block {
+	positions := GetPiecePositions(pieceType, rotation)
+	if positions == nil {
+		return nil
+	}
+	blocks := make([]Block, len(positions))
+	for i, pos := range positions {
+		blocks[i] = Block{X: pos.X, Y: pos.Y, BlockType: genBlockType()}
+	}
+	return blocks
+}
+
+func NewPiece(pieceType PieceType, x, y int, genBlockType func() BlockType) *Piece {
+	return &Piece{
+		Type:     pieceType,
+		Blocks:   GetPieceBlocks(pieceType, 0, genBlockType),
+		X:        x,
+		Y:        y,
+		Rotation: 0,
+	}
+}
+
+// Copy returns a deep copy of the piece so callers can trial moves without
+// touching the original.
+func (p *Piece) Copy() *Piece {
+	blocks := make([]Block, len(p.Blocks))
+	copy(blocks, p.Blocks)
+	return &Piece{
+		Type:     p.Type,
+		Blocks:   blocks,
+		X:        p.X,
+		Y:        p.Y,
+		Rotation: p.Rotation,
+	}
+}
+
+// GetPiecePositions returns the cells of a piece turned rotation quarter
+// turns clockwise from spawn, in the same order as its spawn cells.
+func GetPiecePositions(pieceType PieceType, rotation int) []struct{ X, Y int } {
+	shape, ok := pieceShapes[pieceType]
+	if !ok {
+		return nil
+	}
+	size := boxSize(pieceType)
+	positions := make([]struct{ X, Y int }, len(shape))
+	for i, pos := range shape {
+		positions[i] = pos
+		for range normalizeRotation(rotation) {
+			positions[i].X, positions[i].Y = size-1-positions[i].Y, positions[i].X
+		}
+	}
+	return positions
+}
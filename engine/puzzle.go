@@ -0,0 +1,379 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Puzzle is a hand-made level: a starting stack of blocks and a fixed
+// sequence of pieces with fixed charges, to be neutralized within a move
+// limit. Levels are stored as JSON, with the board drawn as rows of text:
+//
+//	{
+//	  "name": "First Contact",
+//	  "board": [
+//	    "....++......",
+//	    "+-..--......"
+//	  ],
+//	  "pieces": [{"type": "O", "charges": "-+-+"}],
+//	  "moves": 1
+//	}
+type Puzzle struct {
+	Name string `json:"name"`
+	// Board is the starting stack, one string per row with the last row on
+	// the floor. '+' and '-' are charged blocks, '0' a neutral block and '.'
+	// an empty cell. Rows may stop short of the board's width.
+	Board  []string      `json:"board"`
+	Pieces []PuzzlePiece `json:"pieces"`
+	// Moves is how many pieces may be placed; zero allows the whole
+	// sequence.
+	Moves int `json:"moves,omitempty"`
+}
+
+// PuzzlePiece is one piece of a puzzle's sequence.
+type PuzzlePiece struct {
+	// Type is the piece's letter: I, O, T, S, Z, J or L.
+	Type string `json:"type"`
+	// Charges has a '+', '-' or '0' for each of the piece's cells, in the
+	// order they appear reading its spawn orientation left to right and top
+	// to bottom.
+	Charges string `json:"charges"`
+}
+
+const (
+	// PuzzleMode is the mode of every game played from a Puzzle. It can't
+	// be chosen on its own, as it needs a level to play.
+	PuzzleMode ModeKind = "puzzle"
+
+	// Solved ends a puzzle with every charged block neutralized.
+	Solved EndCause = "solved"
+	// OutOfMoves ends a puzzle whose pieces ran out first.
+	OutOfMoves EndCause = "outOfMoves"
+)
+
+// pieceLetters names each PieceType by its position.
+const pieceLetters = "IOTSZJL"
+
+var blockSymbols = map[byte]BlockType{'+': PositiveBlock, '-': NegativeBlock, '0': NeutralBlock}
+
+// PieceLetter returns the letter a puzzle uses for t.
+func PieceLetter(t PieceType) string {
+	return pieceLetters[t : t+1]
+}
+
+// BlockSymbol returns the character a puzzle uses for t.
+func BlockSymbol(t BlockType) byte {
+	for symbol, bt := range blockSymbols {
+		if bt == t {
+			return symbol
+		}
+	}
+	return '.'
+}
+
+func parsePieceLetter(letter string) (PieceType, error) {
+	i := strings.Index(pieceLetters, strings.ToUpper(letter))
+	if len(letter) != 1 || i < 0 {
+		return 0, fmt.Errorf("unknown piece %q (want one of %s)", letter, pieceLetters)
+	}
+	return PieceType(i), nil
+}
+
+func parseCharges(s string) ([]BlockType, error) {
+	charges := make([]BlockType, len(s))
+	for i := range len(s) {
+		bt, ok := blockSymbols[s[i]]
+		if !ok {
+			return nil, fmt.Errorf("unknown charge %q (want +, - or 0)", s[i])
+		}
+		charges[i] = bt
+	}
+	return charges, nil
+}
+
+// Validate checks that the puzzle fits the board, has something to solve
+// and deals pieces this build knows.
+func (p *Puzzle) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("puzzle has no name")
+	}
+	if len(p.Board) > DefaultHeight-2 {
+		return fmt.Errorf("puzzle board has %d rows, want at most %d", len(p.Board), DefaultHeight-2)
+	}
+	for i, row := range p.Board {
+		if len(row) > DefaultWidth {
+			return fmt.Errorf("puzzle board row %d is %d cells wide, want at most %d", i+1, len(row), DefaultWidth)
+		}
+		for j := range len(row) {
+			if _, ok := blockSymbols[row[j]]; !ok && row[j] != '.' {
+				return fmt.Errorf("puzzle board row %d has unknown cell %q", i+1, row[j])
+			}
+		}
+	}
+	if len(p.Pieces) == 0 {
+		return errors.New("puzzle has no pieces")
+	}
+	for i, piece := range p.Pieces {
+		t, err := parsePieceLetter(piece.Type)
+		if err != nil {
+			return fmt.Errorf("puzzle piece %d: %w", i+1, err)
+		}
+		if _, err := parseCharges(piece.Charges); err != nil {
+			return fmt.Errorf("puzzle piece %d: %w", i+1, err)
+		}
+		if cells := len(pieceShapes[t]); len(piece.Charges) != cells {
+			return fmt.Errorf("puzzle piece %d has %d charges for %d cells", i+1, len(piece.Charges), cells)
+		}
+	}
+	if p.Moves < 0 || p.Moves > len(p.Pieces) {
+		return fmt.Errorf("puzzle allows %d moves with %d pieces", p.Moves, len(p.Pieces))
+	}
+	if chargedBlocks(p.Blocks()) == 0 {
+		return errors.New("puzzle board has no charged blocks to neutralize")
+	}
+	return nil
+}
+
+// MoveLimit returns how many pieces may be placed.
+func (p *Puzzle) MoveLimit() int {
+	if p.Moves == 0 {
+		return len(p.Pieces)
+	}
+	return p.Moves
+}
+
+// Blocks returns the starting stack as it sits on the board.
+func (p *Puzzle) Blocks() []Block {
+	var blocks []Block
+	top := DefaultHeight - len(p.Board)
+	for i, row := range p.Board {
+		for x := range len(row) {
+			if bt, ok := blockSymbols[row[x]]; ok {
+				blocks = append(blocks, Block{X: x, Y: top + i, BlockType: bt})
+			}
+		}
+	}
+	return blocks
+}
+
+// SetBlocks draws blocks into the puzzle's board, trimming empty rows off
+// the top.
+func (p *Puzzle) SetBlocks(blocks []Block) {
+	rows := make([][]byte, DefaultHeight)
+	for y := range rows {
+		rows[y] = []byte(strings.Repeat(".", DefaultWidth))
+	}
+	top := DefaultHeight
+	for _, b := range blocks {
+		if b.X < 0 || b.X >= DefaultWidth || b.Y < 0 || b.Y >= DefaultHeight {
+			continue
+		}
+		rows[b.Y][b.X] = BlockSymbol(b.BlockType)
+		top = min(top, b.Y)
+	}
+	p.Board = nil
+	for _, row := range rows[top:] {
+		p.Board = append(p.Board, strings.TrimRight(string(row), "."))
+	}
+}
+
+// Piece returns the piece in its spawn orientation, with its cells charged,
+// for showing the sequence.
+func (pp PuzzlePiece) Piece() (*Piece, error) {
+	t, err := parsePieceLetter(pp.Type)
+	if err != nil {
+		return nil, err
+	}
+	charges, err := parseCharges(pp.Charges)
+	if err != nil {
+		return nil, err
+	}
+	if len(charges) != len(pieceShapes[t]) {
+		return nil, fmt.Errorf("%d charges for %d cells", len(charges), len(pieceShapes[t]))
+	}
+	return NewPiece(t, 0, 0, func() BlockType {
+		bt := charges[0]
+		charges = charges[1:]
+		return bt
+	}), nil
+}
+
+func chargedBlocks(blocks []Block) int {
+	n := 0
+	for _, b := range blocks {
+		if b.BlockType != NeutralBlock {
+			n++
+		}
+	}
+	return n
+}
+
+func ReadPuzzle(r io.Reader) (*Puzzle, error) {
+	var p Puzzle
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func WritePuzzle(w io.Writer, p *Puzzle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// sequence deals a puzzle's pieces in order, starting over when they run
+// out so the preview always has pieces to show. The move limit ends the
+// puzzle before any repeat is played.
+type sequence struct {
+	types []PieceType
+	// left are the pieces still to be dealt before starting over.
+	left []PieceType
+}
+
+func (s *sequence) Next() PieceType {
+	if len(s.left) == 0 {
+		s.left = s.types
+	}
+	t := s.left[0]
+	s.left = s.left[1:]
+	return t
+}
+
+func (s *sequence) State() []PieceType { return append([]PieceType(nil), s.left...) }
+
+func (s *sequence) SetState(state []PieceType) { s.left = state }
+
+// sequenceCharges hands out the charges of the pieces a sequence deals,
+// running through the puzzle in step with it.
+type sequenceCharges struct {
+	charges []BlockType
+	left    []BlockType
+}
+
+func (s *sequenceCharges) Charges(n, _ int) []BlockType {
+	if len(s.left) < n {
+		s.left = s.charges
+	}
+	charges := s.left[:n:n]
+	s.left = s.left[n:]
+	return charges
+}
+
+func (s *sequenceCharges) State() []BlockType { return append([]BlockType(nil), s.left...) }
+
+func (s *sequenceCharges) SetState(state []BlockType) { s.left = state }
+
+// dealers returns what deals a puzzle's pieces and their charges.
+func (p *Puzzle) dealers() (Randomizer, ChargeGenerator) {
+	pieces, charges := &sequence{}, &sequenceCharges{}
+	for _, piece := range p.Pieces {
+		t, _ := parsePieceLetter(piece.Type)
+		cells, _ := parseCharges(piece.Charges)
+		pieces.types = append(pieces.types, t)
+		charges.charges = append(charges.charges, cells...)
+	}
+	return pieces, charges
+}
+
+// newDealers returns what deals the pieces and charges of a game under
+// config: a puzzle's sequence, or the chosen randomizer and charges.
+func newDealers(config Config, rng *RNG) (Randomizer, ChargeGenerator) {
+	if config.Puzzle != nil {
+		return config.Puzzle.dealers()
+	}
+	return NewRandomizer(config.Randomizer, rng.Piece), NewChargeGenerator(config.Charges, rng.Charge)
+}
+
+// puzzleMode is won by clearing every charged block from the board, and
+// lost by running out of pieces first.
+type puzzleMode struct {
+	moves int
+}
+
+func (puzzleMode) Kind() ModeKind       { return PuzzleMode }
+func (puzzleMode) Forgiving() bool      { return false }
+func (puzzleMode) Ranked(EndCause) bool { return false }
+
+// Check waits for the reactions set off by the last piece to play out
+// before calling the puzzle lost. Placing a piece past the limit loses at
+// once.
+func (m puzzleMode) Check(g *Game) EndCause {
+	switch {
+	case g.Pieces() > m.moves:
+		return OutOfMoves
+	case chargedBlocks(g.Board.placedBlocks) == 0:
+		return Solved
+	case g.Pieces() == m.moves && g.Board.quiet():
+		return OutOfMoves
+	}
+	return ""
+}
+
+func (m puzzleMode) Status(g *Game) (string, string) {
+	return "MOVES LEFT", fmt.Sprintf("%d", max(m.moves-g.Pieces(), 0))
+}
+
+// quiet reports whether every block has come to rest, with no reaction,
+// fall or storm throw still playing out.
+func (b *Board) quiet() bool {
+	for _, block := range b.placedBlocks {
+		if block.IsWobbling || block.IsFalling || block.IsArcing {
+			return false
+		}
+	}
+	return true
+}
+
+// PuzzleProgressVersion is the version of the puzzle progress format.
+const PuzzleProgressVersion = 1
+
+// PuzzleProgress records the puzzles a player has solved.
+type PuzzleProgress struct {
+	Version int `json:"version"`
+	// Solved holds the fewest moves each solved puzzle took, by name.
+	Solved map[string]int `json:"solved"`
+}
+
+func NewPuzzleProgress() *PuzzleProgress {
+	return &PuzzleProgress{
+		Version: PuzzleProgressVersion,
+		Solved:  make(map[string]int),
+	}
+}
+
+// Record notes the puzzle solved in moves, keeping the best solution, and
+// reports whether it beat the one before.
+func (pp *PuzzleProgress) Record(name string, moves int) bool {
+	if best, ok := pp.Solved[name]; ok && best <= moves {
+		return false
+	}
+	pp.Solved[name] = moves
+	return true
+}
+
+func WritePuzzleProgress(w io.Writer, pp *PuzzleProgress) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(pp)
+}
+
+func ReadPuzzleProgress(r io.Reader) (*PuzzleProgress, error) {
+	pp := NewPuzzleProgress()
+	if err := json.NewDecoder(r).Decode(pp); err != nil {
+		return nil, err
+	}
+	if pp.Version != PuzzleProgressVersion {
+		return nil, fmt.Errorf("unsupported puzzle progress version %d", pp.Version)
+	}
+	if pp.Solved == nil {
+		pp.Solved = make(map[string]int)
+	}
+	return pp, nil
+}
@@ -0,0 +1,254 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// move is one step of a puzzle solution: turns clockwise from spawn,
+// then the column the piece's leftmost cell is dropped in.
+type move struct {
+	turns, column int
+}
+
+// solutions solve the built-in puzzles in assets/puzzles, by name.
+var solutions = map[string][]move{
+	"First Contact": {{0, 2}},
+	"Double Decker": {{0, 2}},
+	"The Gap":       {{1, 1}},
+	"Insulation":    {{0, 3}},
+	"Aftershock":    {{0, 4}, {0, 2}},
+}
+
+func newPuzzleGame(p *Puzzle) *Game {
+	config := DefaultConfig()
+	config.Puzzle = p
+	return NewGame(NewRNG(1), config)
+}
+
+// place hard drops the falling piece as m says, then lets the board settle.
+func place(t *testing.T, g *Game, m move) {
+	t.Helper()
+	for range m.turns {
+		g.TryRotatePiece(TurnCW)
+	}
+	left := g.Current.X + g.Current.Blocks[0].X
+	for _, b := range g.Current.Blocks {
+		left = min(left, g.Current.X+b.X)
+	}
+	for dx := m.column - left; dx != 0; {
+		step := 1
+		if dx < 0 {
+			step = -1
+		}
+		if !g.TryMovePiece(step, 0) {
+			t.Fatalf("piece stuck at column %d on the way to %d", m.column-dx, m.column)
+		}
+		dx -= step
+	}
+	g.HardDrop()
+	for range 5 * TicksPerSecond {
+		if g.IsOver() {
+			return
+		}
+		g.Step(Input{})
+		if g.Board.quiet() {
+			return
+		}
+	}
+	t.Fatal("board never settled")
+}
+
+func TestBuiltinPuzzlesSolve(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "assets", "puzzles", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no built-in puzzles found: %v", err)
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := ReadPuzzle(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		solution, ok := solutions[p.Name]
+		if !ok {
+			t.Errorf("no solution to check %q against", p.Name)
+			continue
+		}
+
+		g := newPuzzleGame(p)
+		for _, m := range solution {
+			place(t, g, m)
+		}
+		if g.EndCause() != Solved || g.Pieces() != len(solution) {
+			t.Errorf("%q ended with %q after %d moves, want solved in %d", p.Name, g.EndCause(), g.Pieces(), len(solution))
+		}
+	}
+}
+
+func TestPuzzleOutOfMoves(t *testing.T) {
+	g := newPuzzleGame(&Puzzle{
+		Name:   "Miss",
+		Board:  []string{"++"},
+		Pieces: []PuzzlePiece{{Type: "I", Charges: "--+-"}, {Type: "O", Charges: "++++"}},
+		Moves:  1,
+	})
+	place(t, g, move{0, 8})
+	if g.EndCause() != OutOfMoves {
+		t.Errorf("missed puzzle ended with %q, want %q", g.EndCause(), OutOfMoves)
+	}
+	if g.Mode().Ranked(OutOfMoves) || g.Mode().Ranked(Solved) {
+		t.Error("puzzles should never be ranked")
+	}
+}
+
+// TestPuzzleDealsSequence checks pieces come in order with their charges,
+// starting over when the sequence runs out, and carry on from the same
+// place after a save.
+func TestPuzzleDealsSequence(t *testing.T) {
+	p := &Puzzle{
+		Name:  "Sequence",
+		Board: []string{"+"},
+		Pieces: []PuzzlePiece{
+			{Type: "T", Charges: "+-0+"},
+			{Type: "s", Charges: "--++"},
+			{Type: "J", Charges: "0000"},
+		},
+	}
+	config := DefaultConfig()
+	config.Preview = MinPreview
+	config.Puzzle = p
+	g := NewGame(NewRNG(1), config)
+
+	var dealt []string
+	for range 7 {
+		cells := make([]byte, len(g.Current.Blocks))
+		for i, b := range g.Current.Blocks {
+			cells[i] = BlockSymbol(b.BlockType)
+		}
+		dealt = append(dealt, PieceLetter(g.Current.Type)+string(cells))
+		if len(dealt) == 4 {
+			state, err := g.Snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, err = RestoreGame(state); err != nil {
+				t.Fatal(err)
+			}
+		}
+		g.SpawnPiece()
+	}
+	want := []string{"T+-0+", "S--++", "J0000", "T+-0+", "S--++", "J0000", "T+-0+"}
+	if !reflect.DeepEqual(dealt, want) {
+		t.Errorf("dealt %v, want %v", dealt, want)
+	}
+}
+
+func TestPuzzleFormat(t *testing.T) {
+	p := &Puzzle{
+		Name:   "Format",
+		Board:  []string{"0", "+-..-", "-+0++"},
+		Pieces: []PuzzlePiece{{Type: "L", Charges: "+-+-"}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WritePuzzle(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadPuzzle(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.SetBlocks(got.Blocks())
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("puzzle changed in a round trip:\ngot  %+v\nwant %+v", got, p)
+	}
+	piece, err := p.Pieces[0].Piece()
+	if err != nil || piece.Type != LPiece || piece.Blocks[0].BlockType != PositiveBlock || piece.Blocks[3].BlockType != NegativeBlock {
+		t.Errorf("L +-+- became %+v, %v", piece, err)
+	}
+
+	broken := map[string]Puzzle{
+		"too wide":       {Name: "x", Board: []string{strings.Repeat("+", DefaultWidth+1)}, Pieces: p.Pieces},
+		"unknown cell":   {Name: "x", Board: []string{"+x"}, Pieces: p.Pieces},
+		"nothing to do":  {Name: "x", Board: []string{"00"}, Pieces: p.Pieces},
+		"unknown piece":  {Name: "x", Board: p.Board, Pieces: []PuzzlePiece{{Type: "Q", Charges: "++++"}}},
+		"short charges":  {Name: "x", Board: p.Board, Pieces: []PuzzlePiece{{Type: "T", Charges: "++"}}},
+		"too many moves": {Name: "x", Board: p.Board, Pieces: p.Pieces, Moves: 2},
+		"no pieces":      {Name: "x", Board: p.Board},
+		"no name":        {Board: p.Board, Pieces: p.Pieces},
+	}
+	for name, puzzle := range broken {
+		if err := puzzle.Validate(); err == nil {
+			t.Errorf("%s: puzzle passed validation", name)
+		}
+	}
+}
+
+func TestPuzzleSetBlocks(t *testing.T) {
+	bottom := DefaultHeight - 1
+	blocks := []Block{
+		{X: 4, Y: bottom, BlockType: NegativeBlock},
+		{X: 0, Y: bottom - 2, BlockType: NeutralBlock},
+		{X: 1, Y: bottom, BlockType: PositiveBlock},
+		{X: DefaultWidth, Y: bottom, BlockType: PositiveBlock},
+	}
+	p := &Puzzle{}
+	p.SetBlocks(blocks)
+	// Empty rows above the stack are trimmed, and so are the ends of rows;
+	// the empty row inside the stack stays. The block off the board is
+	// dropped.
+	if want := []string{"0", "", ".+..-"}; !reflect.DeepEqual(p.Board, want) {
+		t.Errorf("board drawn as %q, want %q", p.Board, want)
+	}
+	want := []Block{blocks[1], blocks[2], blocks[0]}
+	if got := p.Blocks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks came back as %+v, want %+v", got, want)
+	}
+
+	p.SetBlocks(nil)
+	if len(p.Board) != 0 || len(p.Blocks()) != 0 {
+		t.Errorf("an empty stack drew the board %q", p.Board)
+	}
+
+	// A block in the rows the pieces spawn in is kept, but makes the
+	// board too tall to play.
+	p.Name, p.Pieces = "Hidden", []PuzzlePiece{{Type: "O", Charges: "++++"}}
+	p.SetBlocks(append(blocks[:3:3], Block{X: 2, Y: 1, BlockType: PositiveBlock}))
+	if got := p.Blocks(); len(got) != 4 || got[0].Y != 1 {
+		t.Errorf("hidden row block came back as %+v", got)
+	}
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "rows") {
+		t.Errorf("puzzle with a block in the hidden rows gave %v, want too many rows", err)
+	}
+}
+
+func TestPuzzleProgressKeepsBest(t *testing.T) {
+	pp := NewPuzzleProgress()
+	if !pp.Record("Aftershock", 2) || pp.Record("Aftershock", 3) || !pp.Record("Aftershock", 1) {
+		t.Error("progress didn't keep only improvements")
+	}
+
+	var buf bytes.Buffer
+	if err := WritePuzzleProgress(&buf, pp); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadPuzzleProgress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, pp) {
+		t.Errorf("read back %+v, want %+v", got, pp)
+	}
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// Randomizer deals the sequence of piece types.
+type Randomizer interface {
+	Next() PieceType
+	// State returns whatever the randomizer remembers between pieces, such
+	// as what is left in the bag, so a saved game deals the same pieces.
+	State() []PieceType
+	SetState(state []PieceType)
+}
+
+// RandomizerKind names a Randomizer implementation.
+type RandomizerKind string
+
+const (
+	PureRandom    RandomizerKind = "random"
+	SevenBag      RandomizerKind = "7bag"
+	FourteenBag   RandomizerKind = "14bag"
+	HistoryRandom RandomizerKind = "tgm"
+)
+
+// RandomizerKinds lists every randomizer that can be chosen.
+var RandomizerKinds = []RandomizerKind{PureRandom, SevenBag, FourteenBag, HistoryRandom}
+
+func ParseRandomizer(name string) (RandomizerKind, error) {
+	kind := RandomizerKind(name)
+	if !slices.Contains(RandomizerKinds, kind) {
+		return "", fmt.Errorf("unknown randomizer %q (want one of %v)", name, RandomizerKinds)
+	}
+	return kind, nil
+}
+
+// NewRandomizer creates the randomizer of the given kind drawing from r.
+// Unknown kinds get the 7-bag.
+func NewRandomizer(kind RandomizerKind, r *rand.Rand) Randomizer {
+	switch kind {
+	case PureRandom:
+		return &randomRandomizer{rand: r}
+	case FourteenBag:
+		return &bagRandomizer{rand: r, copies: 2}
+	case HistoryRandom:
+		return &historyRandomizer{rand: r}
+	}
+	return &bagRandomizer{rand: r, copies: 1}
+}
+
+// randomRandomizer picks every piece uniformly, droughts and all.
+type randomRandomizer struct {
+	rand *rand.Rand
+}
+
+func (rr *randomRandomizer) Next() PieceType {
+	return PieceTypes[rr.rand.IntN(len(PieceTypes))]
+}
+
+func (rr *randomRandomizer) State() []PieceType { return nil }
+
+func (rr *randomRandomizer) SetState([]PieceType) {}
+
+// bagRandomizer shuffles copies of every piece into a bag and deals it out
+// before refilling, so no piece is ever more than two bags away.
+type bagRandomizer struct {
+	rand   *rand.Rand
+	copies int
+	bag    []PieceType
+}
+
+func (br *bagRandomizer) Next() PieceType {
+	if len(br.bag) == 0 {
+		for range br.copies {
+			br.bag = append(br.bag, PieceTypes...)
+		}
+		br.rand.Shuffle(len(br.bag), func(i, j int) {
+			br.bag[i], br.bag[j] = br.bag[j], br.bag[i]
+		})
+	}
+	next := br.bag[0]
+	br.bag = br.bag[1:]
+	return next
+}
+
+func (br *bagRandomizer) State() []PieceType {
+	return slices.Clone(br.bag)
+}
+
+func (br *bagRandomizer) SetState(state []PieceType) {
+	br.bag = slices.Clone(state)
+}
+
+// historyLength is how many of the last pieces the history randomizer
+// tries not to repeat, and historyRerolls how many times it tries.
+const (
+	historyLength  = 4
+	historyRerolls = 6
+)
+
+// historyRandomizer works like the TGM games: it remembers the last few
+// pieces and rerolls a few times to avoid repeating them. The first piece
+// is never an S, Z or O, so the opening is never forced to overhang.
+type historyRandomizer struct {
+	rand    *rand.Rand
+	history []PieceType
+}
+
+func (hr *historyRandomizer) Next() PieceType {
+	if hr.history == nil {
+		first := []PieceType{IPiece, TPiece, JPiece, LPiece}
+		next := first[hr.rand.IntN(len(first))]
+		hr.history = []PieceType{ZPiece, SPiece, SPiece, next}
+		return next
+	}
+	var next PieceType
+	for range historyRerolls {
+		next = PieceTypes[hr.rand.IntN(len(PieceTypes))]
+		if !slices.Contains(hr.history, next) {
+			break
+		}
+	}
+	hr.history = append(hr.history[1:], next)
+	return next
+}
+
+func (hr *historyRandomizer) State() []PieceType {
+	return slices.Clone(hr.history)
+}
+
+func (hr *historyRandomizer) SetState(state []PieceType) {
+	if len(state) != historyLength {
+		hr.history = nil
+		return
+	}
+	hr.history = slices.Clone(state)
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func deal(r Randomizer, n int) []PieceType {
+	pieces := make([]PieceType, n)
+	for i := range pieces {
+		pieces[i] = r.Next()
+	}
+	return pieces
+}
+
+func TestBagRandomizers(t *testing.T) {
+	tests := []struct {
+		kind   RandomizerKind
+		copies int
+	}{
+		{SevenBag, 1},
+		{FourteenBag, 2},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			size := len(PieceTypes) * tt.copies
+			pieces := deal(NewRandomizer(tt.kind, rand.New(rand.NewPCG(1, 2))), size*50)
+			for start := 0; start < len(pieces); start += size {
+				counts := make(map[PieceType]int)
+				for _, p := range pieces[start : start+size] {
+					counts[p]++
+				}
+				for _, p := range PieceTypes {
+					if counts[p] != tt.copies {
+						t.Fatalf("bag at %d dealt piece %d %d times, want %d", start, p, counts[p], tt.copies)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryRandomizer(t *testing.T) {
+	for seed := range uint64(50) {
+		pieces := deal(NewRandomizer(HistoryRandom, rand.New(rand.NewPCG(seed, 0))), 200)
+		if slices.Contains([]PieceType{SPiece, ZPiece, OPiece}, pieces[0]) {
+			t.Errorf("seed %d: first piece %d is S, Z or O", seed, pieces[0])
+		}
+		repeats := 0
+		for i := 1; i < len(pieces); i++ {
+			if pieces[i] == pieces[i-1] {
+				repeats++
+			}
+		}
+		// Six rerolls make a straight repeat rare; pure random would
+		// average about 28 in 200.
+		if repeats > 10 {
+			t.Errorf("seed %d: %d back-to-back repeats", seed, repeats)
+		}
+	}
+}
+
+func TestRandomizerStateResumes(t *testing.T) {
+	for _, kind := range RandomizerKinds {
+		t.Run(string(kind), func(t *testing.T) {
+			src := rand.NewPCG(7, 7)
+			r := NewRandomizer(kind, rand.New(src))
+			deal(r, 10)
+
+			srcState, err := src.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			resumedSrc := rand.NewPCG(0, 0)
+			if err := resumedSrc.UnmarshalBinary(srcState); err != nil {
+				t.Fatal(err)
+			}
+			resumed := NewRandomizer(kind, rand.New(resumedSrc))
+			resumed.SetState(r.State())
+
+			if want, got := deal(r, 30), deal(resumed, 30); !slices.Equal(got, want) {
+				t.Errorf("resumed randomizer dealt %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseRandomizer(t *testing.T) {
+	for _, kind := range RandomizerKinds {
+		if got, err := ParseRandomizer(string(kind)); err != nil || got != kind {
+			t.Errorf("ParseRandomizer(%q) = %q, %v", kind, got, err)
+		}
+	}
+	if _, err := ParseRandomizer("shuffle"); err == nil {
+		t.Error("ParseRandomizer accepted an unknown name")
+	}
+}
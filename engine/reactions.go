@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// CheckAndProcessReactions resolves every reaction on the board instantly,
+// without the wobble animation, and returns the points earned.
+func (b *Board) CheckAndProcessReactions() int {
+	totalScore := 0
+	for {
+		blocksToRemove := b.findBlocksToRemove()
+		if len(blocksToRemove) == 0 {
+			break
+		}
+		reactionScore := b.calculateReactionScore(len(blocksToRemove))
+		totalScore += reactionScore
+		b.removeBlocks(blocksToRemove)
+		b.processBlockFalling()
+	}
+	return totalScore
+}
+
+func (b *Board) calculateReactionScore(blocksRemoved int) int {
+	if blocksRemoved < 4 {
+		return 0
+	}
+	score := 10
+	for i := 4; i < blocksRemoved; i++ {
+		score *= 2
+	}
+	return score
+}
+
+func (b *Board) findBlocksToRemove() []Block {
+	var blocksToRemove []Block
+	rowMap := make(map[int][]Block)
+	for _, block := range b.placedBlocks {
+		rowMap[block.Y] = append(rowMap[block.Y], block)
+	}
+	for _, rowBlocks := range rowMap {
+		if len(rowBlocks) < 3 {
+			continue
+		}
+		for i := 0; i < len(rowBlocks); i++ {
+			for j := i + 1; j < len(rowBlocks); j++ {
+				if rowBlocks[i].X > rowBlocks[j].X {
+					rowBlocks[i], rowBlocks[j] = rowBlocks[j], rowBlocks[i]
+				}
+			}
+		}
+		clusters := b.findClustersInRow(rowBlocks)
+		for _, cluster := range clusters {
+			if len(cluster) >= 3 {
+				zeroSumBlocks := b.findZeroSumSubsequence(cluster)
+				blocksToRemove = append(blocksToRemove, zeroSumBlocks...)
+			}
+		}
+	}
+	return blocksToRemove
+}
+
+func (b *Board) findClustersInRow(rowBlocks []Block) [][]Block {
+	var clusters [][]Block
+	var currentCluster []Block
+	for i, block := range rowBlocks {
+		if block.BlockType == NeutralBlock {
+			if len(currentCluster) >= 3 {
+				clusters = append(clusters, currentCluster)
+			}
+			currentCluster = nil
+		} else if i > 0 && block.X != rowBlocks[i-1].X+1 {
+			if len(currentCluster) >= 3 {
+				clusters = append(clusters, currentCluster)
+			}
+			currentCluster = []Block{block}
+		} else {
+			currentCluster = append(currentCluster, block)
+		}
+	}
+	if len(currentCluster) >= 3 {
+		clusters = append(clusters, currentCluster)
+	}
+	return clusters
+}
+
+func (b *Board) findZeroSumSubsequence(cluster []Block) []Block {
+	for length := len(cluster); length >= 3; length-- {
+		for start := 0; start <= len(cluster)-length; start++ {
+			subsequence := cluster[start : start+length]
+			sum := 0
+			for _, block := range subsequence {
+				sum += block.BlockType.Charge()
+			}
+			if sum == 0 {
+				return subsequence
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Board) removeBlocks(blocksToRemove []Block) {
+	if len(blocksToRemove) == 0 {
+		return
+	}
+	removeMap := make(map[string]bool)
+	for _, block := range blocksToRemove {
+		key := fmt.Sprintf("%d,%d", block.X, block.Y)
+		removeMap[key] = true
+	}
+	var remainingBlocks []Block
+	for _, block := range b.placedBlocks {
+		key := fmt.Sprintf("%d,%d", block.X, block.Y)
+		if !removeMap[key] {
+			remainingBlocks = append(remainingBlocks, block)
+		}
+	}
+	b.placedBlocks = remainingBlocks
+}
+
+func (b *Board) processBlockFalling() {
+	for i := 0; i < len(b.placedBlocks); i++ {
+		for j := i + 1; j < len(b.placedBlocks); j++ {
+			if b.placedBlocks[i].Y < b.placedBlocks[j].Y {
+				b.placedBlocks[i], b.placedBlocks[j] = b.placedBlocks[j], b.placedBlocks[i]
+			}
+		}
+	}
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		if !block.IsFalling {
+			b.StartBlockFall(block)
+		}
+	}
+}
+
+func (b *Board) UpdateWobblingBlocks() bool {
+	anyBlocksFinished := false
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		if block.IsWobbling {
+			block.WobbleTicks++
+			block.WobblePhase += TickSeconds * WobbleFrequency * 2 * math.Pi
+			if block.WobbleTicks >= b.Timing.WobbleTicks {
+				block.ShowPowSprite = false
+				anyBlocksFinished = true
+			}
+		}
+	}
+	return anyBlocksFinished
+}
+
+// RemoveFinishedWobblingBlocks takes every block whose wobble has run its
+// course off the board and returns them.
+func (b *Board) RemoveFinishedWobblingBlocks() []Block {
+	var blocksToRemove []Block
+	var remainingBlocks []Block
+	for _, block := range b.placedBlocks {
+		if block.IsWobbling && block.WobbleTicks >= b.Timing.WobbleTicks {
+			block.ShowPowSprite = false
+			blocksToRemove = append(blocksToRemove, block)
+		} else {
+			remainingBlocks = append(remainingBlocks, block)
+		}
+	}
+	if len(blocksToRemove) == 0 {
+		return nil
+	}
+	b.placedBlocks = remainingBlocks
+	return blocksToRemove
+}
+
+func (b *Board) StartBlockWobbling(blocksToWobble []Block) {
+	if len(blocksToWobble) == 0 {
+		return
+	}
+	wobbleMap := make(map[string]bool)
+	for _, block := range blocksToWobble {
+		key := fmt.Sprintf("%d,%d", block.X, block.Y)
+		wobbleMap[key] = true
+	}
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		key := fmt.Sprintf("%d,%d", block.X, block.Y)
+		if wobbleMap[key] && !block.IsWobbling {
+			block.IsWobbling = true
+			block.WobbleTicks = 0
+			block.WobblePhase = 0
+			block.ShowPowSprite = true
+		}
+	}
+}
+
+func (b *Board) CheckForNewReactions() int {
+	return b.calculateReactionScore(len(b.StartNewReactions()))
+}
+
+// StartNewReactions sets every newly neutralized block wobbling and returns
+// them.
+func (b *Board) StartNewReactions() []Block {
+	blocksToWobble := b.findNonWobblingBlocksToRemove()
+	if len(blocksToWobble) == 0 {
+		return nil
+	}
+	b.StartBlockWobbling(blocksToWobble)
+	return blocksToWobble
+}
+
+func (b *Board) findNonWobblingBlocksToRemove() []Block {
+	var blocksToRemove []Block
+	rowMap := make(map[int][]Block)
+	for _, block := range b.placedBlocks {
+		if !block.IsWobbling {
+			rowMap[block.Y] = append(rowMap[block.Y], block)
+		}
+	}
+	for _, rowBlocks := range rowMap {
+		if len(rowBlocks) < 3 {
+			continue
+		}
+		for i := 0; i < len(rowBlocks); i++ {
+			for j := i + 1; j < len(rowBlocks); j++ {
+				if rowBlocks[i].X > rowBlocks[j].X {
+					rowBlocks[i], rowBlocks[j] = rowBlocks[j], rowBlocks[i]
+				}
+			}
+		}
+		clusters := b.findClustersInRow(rowBlocks)
+		for _, cluster := range clusters {
+			if len(cluster) >= 3 {
+				zeroSumBlocks := b.findZeroSumSubsequence(cluster)
+				blocksToRemove = append(blocksToRemove, zeroSumBlocks...)
+			}
+		}
+	}
+	return blocksToRemove
+}
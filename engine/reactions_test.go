@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCalculateReactionScore(t *testing.T) {
+	tests := []struct {
+		removed int
+		want    int
+	}{
+		{0, 0},
+		{3, 0},
+		{4, 10},
+		{5, 20},
+		{6, 40},
+		{8, 160},
+	}
+	b := NewBoard(DefaultWidth, DefaultHeight, nil)
+	for _, tt := range tests {
+		if got := b.calculateReactionScore(tt.removed); got != tt.want {
+			t.Errorf("calculateReactionScore(%d) = %d, want %d", tt.removed, got, tt.want)
+		}
+	}
+}
+
+// TestRuleFixtures runs every board in testdata/rules through the reaction
+// and storm rules and compares the result with the matching .golden file.
+func TestRuleFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "rules", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures found")
+	}
+	for _, path := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(path), ".txt")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := runRules(t, string(data))
+			checkGolden(t, strings.TrimSuffix(path, ".txt")+".golden", got)
+		})
+	}
+}
+
+// runRules describes what each rule makes of a fixture board.
+func runRules(t *testing.T, fixture string) string {
+	var sb strings.Builder
+
+	b := parseBoard(t, fixture)
+	removed := b.findBlocksToRemove()
+	fmt.Fprintf(&sb, "findBlocksToRemove: %d blocks, score %d\n", len(removed), b.calculateReactionScore(len(removed)))
+	sb.WriteString(drawBlocks(b, removed))
+
+	wobbling := b.findNonWobblingBlocksToRemove()
+	fmt.Fprintf(&sb, "\nfindNonWobblingBlocksToRemove: %d blocks, score %d\n", len(wobbling), b.calculateReactionScore(len(wobbling)))
+	sb.WriteString(drawBlocks(b, wobbling))
+
+	storms := b.findVerticalElectricalStorms()
+	var columns []int
+	for _, block := range storms {
+		if !slices.Contains(columns, block.X) {
+			columns = append(columns, block.X)
+		}
+	}
+	slices.Sort(columns)
+	fmt.Fprintf(&sb, "\nfindVerticalElectricalStorms: columns %v\n", columns)
+	sb.WriteString(drawBlocks(b, storms))
+
+	// Cascade: clear reactions and let the blocks above fall until the
+	// board is quiet.
+	sb.WriteString("\ncascade:\n")
+	total := 0
+	for step := 1; ; step++ {
+		blocks := b.findBlocksToRemove()
+		if len(blocks) == 0 {
+			break
+		}
+		score := b.calculateReactionScore(len(blocks))
+		total += score
+		b.removeBlocks(blocks)
+		b.processBlockFalling()
+		settle(t, b)
+		fmt.Fprintf(&sb, "step %d: %d blocks, score %d\n", step, len(blocks), score)
+		sb.WriteString(drawBlocks(b, b.placedBlocks))
+	}
+	fmt.Fprintf(&sb, "total score %d\n", total)
+
+	return sb.String()
+}
@@ -0,0 +1,310 @@
+package engine
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RulesVersion identifies the game rules a replay was recorded under. Bump it
+// whenever a change would make an old replay play out differently.
+const RulesVersion = 6
+
+const (
+	replayMagic         = "UNRP"
+	replayFormatVersion = 5
+)
+
+var ErrRulesVersion = errors.New("replay was recorded under different game rules")
+
+const (
+	inputLeft = 1 << iota
+	inputRight
+	inputSoftDrop
+	inputRotate
+	inputPause
+	inputRotateCCW
+	inputRotate180
+	inputHold
+	inputHardDrop
+	inputResign
+)
+
+// Replay is everything needed to play a game back exactly: the seed it was
+// dealt from, the rules it was played under and the input for every tick,
+// paused ticks included.
+type Replay struct {
+	RulesVersion int
+	Seed         int64
+	Config       Config
+	Inputs       []Input
+}
+
+func NewReplay(seed int64, config Config) *Replay {
+	return &Replay{
+		RulesVersion: RulesVersion,
+		Seed:         seed,
+		Config:       config,
+	}
+}
+
+func (r *Replay) Record(input Input) {
+	r.Inputs = append(r.Inputs, input)
+}
+
+func (in Input) bits() uint64 {
+	var b uint64
+	if in.Left {
+		b |= inputLeft
+	}
+	if in.Right {
+		b |= inputRight
+	}
+	if in.SoftDrop {
+		b |= inputSoftDrop
+	}
+	if in.HardDrop {
+		b |= inputHardDrop
+	}
+	if in.Rotate {
+		b |= inputRotate
+	}
+	if in.Pause {
+		b |= inputPause
+	}
+	if in.RotateCCW {
+		b |= inputRotateCCW
+	}
+	if in.Rotate180 {
+		b |= inputRotate180
+	}
+	if in.Hold {
+		b |= inputHold
+	}
+	if in.Resign {
+		b |= inputResign
+	}
+	return b
+}
+
+func inputFromBits(b uint64) Input {
+	return Input{
+		Left:      b&inputLeft != 0,
+		Right:     b&inputRight != 0,
+		SoftDrop:  b&inputSoftDrop != 0,
+		HardDrop:  b&inputHardDrop != 0,
+		Rotate:    b&inputRotate != 0,
+		RotateCCW: b&inputRotateCCW != 0,
+		Rotate180: b&inputRotate180 != 0,
+		Hold:      b&inputHold != 0,
+		Pause:     b&inputPause != 0,
+		Resign:    b&inputResign != 0,
+	}
+}
+
+// WriteReplay writes r as a gzip'd stream: a short header followed by the
+// inputs run-length encoded, since a held key repeats for many ticks.
+func WriteReplay(w io.Writer, r *Replay) error {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+
+	bw.WriteString(replayMagic)
+	writeUvarint(bw, replayFormatVersion)
+	writeUvarint(bw, uint64(r.RulesVersion))
+	writeVarint(bw, r.Seed)
+	configJSON, err := json.Marshal(r.Config)
+	if err != nil {
+		return err
+	}
+	writeString(bw, string(configJSON))
+	writeUvarint(bw, uint64(len(r.Inputs)))
+
+	for i := 0; i < len(r.Inputs); {
+		bits := r.Inputs[i].bits()
+		run := 1
+		for i+run < len(r.Inputs) && r.Inputs[i+run].bits() == bits {
+			run++
+		}
+		writeUvarint(bw, bits)
+		writeUvarint(bw, uint64(run))
+		i += run
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func ReadReplay(rd io.Reader) (*Replay, error) {
+	zr, err := gzip.NewReader(rd)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	format, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if format != replayFormatVersion {
+		return nil, fmt.Errorf("unsupported replay format %d", format)
+	}
+	rules, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if rules != RulesVersion {
+		return nil, fmt.Errorf("%w: recorded under v%d, running v%d", ErrRulesVersion, rules, RulesVersion)
+	}
+	seed, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, err
+	}
+	configJSON, err := readString(br)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parseReplayConfig(configJSON)
+	if err != nil {
+		return nil, err
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{
+		RulesVersion: int(rules),
+		Seed:         seed,
+		Config:       config,
+		Inputs:       make([]Input, 0, min(count, maxReplayPrealloc)),
+	}
+	for uint64(len(r.Inputs)) < count {
+		bits, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		run, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if run == 0 || uint64(len(r.Inputs))+run > count {
+			return nil, errors.New("corrupt replay input stream")
+		}
+		input := inputFromBits(bits)
+		for ; run > 0; run-- {
+			r.Inputs = append(r.Inputs, input)
+		}
+	}
+	return r, nil
+}
+
+func SaveReplay(path string, r *Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteReplay(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+func writeUvarint(w io.ByteWriter, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	for _, b := range buf[:n] {
+		w.WriteByte(b)
+	}
+}
+
+func writeVarint(w io.ByteWriter, v int64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	for _, b := range buf[:n] {
+		w.WriteByte(b)
+	}
+}
+
+func writeString(w *bufio.Writer, s string) {
+	writeUvarint(w, uint64(len(s)))
+	w.WriteString(s)
+}
+
+// maxReplayConfig bounds the config in a replay header, so a corrupt length
+// can't ask for a huge allocation. A puzzle's pieces are kept in the
+// config, so it leaves room for far more than any puzzle deals.
+const maxReplayConfig = 1 << 20
+
+// maxReplayPrealloc caps the inputs allocated up front from a replay's
+// count, for the same reason; longer replays grow as they are read.
+const maxReplayPrealloc = 1 << 20
+
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > maxReplayConfig {
+		return "", errors.New("corrupt replay header")
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// parseReplayConfig reads the game config stored in a replay header as JSON,
+// so new settings don't need a new replay format. Settings a replay predates
+// keep their defaults.
+func parseReplayConfig(data string) (Config, error) {
+	config := DefaultConfig()
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		return Config{}, fmt.Errorf("corrupt replay config: %w", err)
+	}
+	if _, err := ParseRandomizer(string(config.Randomizer)); err != nil {
+		return Config{}, err
+	}
+	if _, err := ParseCharges(string(config.Charges)); err != nil {
+		return Config{}, err
+	}
+	if config.Mode != "" {
+		if _, err := ParseMode(string(config.Mode)); err != nil {
+			return Config{}, err
+		}
+	}
+	if config.Puzzle != nil {
+		if err := config.Puzzle.Validate(); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := config.Timing.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReplayRoundTrip(t *testing.T) {
+	r := NewReplay(-42, Config{
+		Randomizer: HistoryRandom,
+		Charges:    ChargeBag,
+		Handling:   Handling{DelayTicks: 8, RepeatTicks: 2, SoftDropTicks: 1},
+	})
+	for i := range 500 {
+		r.Record(Input{
+			Left:      i%40 < 10,
+			SoftDrop:  i%90 > 80,
+			Rotate:    i%17 == 0,
+			RotateCCW: i%23 == 0,
+			Rotate180: i%31 == 0,
+			Pause:     i > 450,
+			Resign:    i == 499,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReplay(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("replay changed in a round trip:\ngot  %+v\nwant %+v", got.Config, r.Config)
+	}
+}
+
+func TestReplayRoundTripLargePuzzle(t *testing.T) {
+	p := &Puzzle{Name: "Marathon", Board: []string{"+-+-+-+-+-", "-+-+-+-+-+"}, Moves: 150}
+	for i := range 200 {
+		if i%2 == 0 {
+			p.Pieces = append(p.Pieces, PuzzlePiece{Type: "T", Charges: "+-+-"})
+		} else {
+			p.Pieces = append(p.Pieces, PuzzlePiece{Type: "I", Charges: "--++"})
+		}
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	config.Puzzle = p
+	r := NewReplay(1, config)
+	for i := range 300 {
+		r.Record(Input{HardDrop: i%3 == 0})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReplay(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("puzzle changed in a round trip:\ngot  %+v\nwant %+v", got.Config.Puzzle, r.Config.Puzzle)
+	}
+}
+
+func TestReplayConfigDefaultsMissingSettings(t *testing.T) {
+	config, err := parseReplayConfig(`{"randomizer":"tgm","charges":"bag"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultConfig()
+	want.Randomizer, want.Charges = HistoryRandom, ChargeBag
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("parsed %+v, want %+v", config, want)
+	}
+}
+
+func TestReplayPlaysBackExactly(t *testing.T) {
+	r := NewReplay(99, DefaultConfig())
+	live := NewGame(NewRNG(r.Seed), r.Config)
+	for i := 0; i < 3000 && !live.IsOver(); i++ {
+		input := Input{Left: i%50 < 20, Right: i%70 > 50, SoftDrop: i%11 == 0, Rotate: i%13 == 0}
+		r.Record(input)
+		live.Step(input)
+	}
+
+	played := NewGame(NewRNG(r.Seed), r.Config)
+	for _, input := range r.Inputs {
+		played.Step(input)
+	}
+	if played.Score != live.Score || !reflect.DeepEqual(played.Board.GetPlacedBlocks(), live.Board.GetPlacedBlocks()) {
+		t.Errorf("replay ended on score %d, live game on %d", played.Score, live.Score)
+	}
+}
+
+func TestReplayPlaysBackResignation(t *testing.T) {
+	r := NewReplay(7, DefaultConfig())
+	for i := range 200 {
+		// The resignation comes while paused, as it does from the pause screen.
+		r.Record(Input{Left: i < 50, HardDrop: i == 60, Pause: i >= 150, Resign: i == 199})
+	}
+	var buf bytes.Buffer
+	if err := WriteReplay(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	played := NewGame(NewRNG(got.Seed), got.Config)
+	for i, input := range got.Inputs {
+		if played.IsOver() {
+			t.Fatalf("game ended with %q at input %d, before the resignation", played.EndCause(), i)
+		}
+		played.Step(input)
+	}
+	if played.EndCause() != Resigned {
+		t.Errorf("replay ended with %q, want %q", played.EndCause(), Resigned)
+	}
+	if played.Tick() != 199 {
+		t.Errorf("resignation played a tick: game reached tick %d, want 199", played.Tick())
+	}
+}
+
+// replayStream gzips a hand-built replay: the fields header writes, then
+// the runs given as bits and length pairs.
+func replayStream(header func(bw *bufio.Writer), runs ...uint64) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	bw := bufio.NewWriter(zw)
+	header(bw)
+	for _, v := range runs {
+		writeUvarint(bw, v)
+	}
+	bw.Flush()
+	zw.Close()
+	return buf.Bytes()
+}
+
+// replayHeader writes a header for count inputs, recorded under rules with
+// config.
+func replayHeader(rules uint64, config string, count uint64) func(bw *bufio.Writer) {
+	return func(bw *bufio.Writer) {
+		bw.WriteString(replayMagic)
+		writeUvarint(bw, replayFormatVersion)
+		writeUvarint(bw, rules)
+		writeVarint(bw, 1)
+		writeString(bw, config)
+		writeUvarint(bw, count)
+	}
+}
+
+func TestReplayRejectsCorruptInput(t *testing.T) {
+	var good bytes.Buffer
+	r := NewReplay(5, DefaultConfig())
+	for i := range 100 {
+		r.Record(Input{Left: i%10 < 3})
+	}
+	if err := WriteReplay(&good, r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadReplay(bytes.NewReader(replayStream(replayHeader(RulesVersion, "{}", 3), 0, 3))); err != nil {
+		t.Fatalf("hand-built replay rejected: %v", err)
+	}
+
+	corrupt := map[string][]byte{
+		"not gzip":  []byte("UNRP"),
+		"truncated": good.Bytes()[:good.Len()/2],
+		"bad magic": replayStream(func(bw *bufio.Writer) { bw.WriteString("NOPE") }),
+		"future format": replayStream(func(bw *bufio.Writer) {
+			bw.WriteString(replayMagic)
+			writeUvarint(bw, replayFormatVersion+1)
+		}),
+		"runs overflow":    replayStream(replayHeader(RulesVersion, "{}", 3), 0, 4),
+		"empty run":        replayStream(replayHeader(RulesVersion, "{}", 3), 0, 0),
+		"inputs cut short": replayStream(replayHeader(RulesVersion, "{}", 1<<62), 0, 3),
+		"bad config":       replayStream(replayHeader(RulesVersion, "{not json", 0)),
+		"huge config": replayStream(func(bw *bufio.Writer) {
+			bw.WriteString(replayMagic)
+			writeUvarint(bw, replayFormatVersion)
+			writeUvarint(bw, RulesVersion)
+			writeVarint(bw, 1)
+			writeUvarint(bw, maxReplayConfig+1)
+		}),
+	}
+	for name, data := range corrupt {
+		if _, err := ReadReplay(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: replay was read", name)
+		}
+	}
+
+	_, err := ReadReplay(bytes.NewReader(replayStream(replayHeader(RulesVersion-1, "{}", 0))))
+	if !errors.Is(err, ErrRulesVersion) {
+		t.Errorf("replay from older rules gave %v, want %v", err, ErrRulesVersion)
+	}
+}
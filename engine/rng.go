@@ -0,0 +1,42 @@
+package engine
+
+import "math/rand/v2"
+
+// Stream identifiers keep the piece, charge and storm sequences independent
+// of each other, so adding a draw to one never shifts the others.
+const (
+	pieceStream  = 0x9e3779b97f4a7c15
+	chargeStream = 0xbf58476d1ce4e5b9
+	stormStream  = 0x94d049bb133111eb
+)
+
+// RNG is the set of random streams behind every decision a game makes. Two
+// games built from the same seed and fed the same inputs play out the same.
+type RNG struct {
+	seed      int64
+	Piece     *rand.Rand
+	Charge    *rand.Rand
+	Storm     *rand.Rand
+	pieceSrc  *rand.PCG
+	chargeSrc *rand.PCG
+	stormSrc  *rand.PCG
+}
+
+func NewRNG(seed int64) *RNG {
+	pieceSrc := rand.NewPCG(uint64(seed), pieceStream)
+	chargeSrc := rand.NewPCG(uint64(seed), chargeStream)
+	stormSrc := rand.NewPCG(uint64(seed), stormStream)
+	return &RNG{
+		seed:      seed,
+		Piece:     rand.New(pieceSrc),
+		Charge:    rand.New(chargeSrc),
+		Storm:     rand.New(stormSrc),
+		pieceSrc:  pieceSrc,
+		chargeSrc: chargeSrc,
+		stormSrc:  stormSrc,
+	}
+}
+
+func (r *RNG) Seed() int64 {
+	return r.seed
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSameSeedSameGame(t *testing.T) {
+	games := make([]*Game, 3)
+	for i, seed := range []int64{42, 42, 43} {
+		games[i] = NewGame(NewRNG(seed), DefaultConfig())
+		play(games[i], 0, 1800)
+	}
+	a, b, other := games[0], games[1], games[2]
+
+	if a.Score != b.Score || a.Tick() != b.Tick() ||
+		!reflect.DeepEqual(a.Board.GetPlacedBlocks(), b.Board.GetPlacedBlocks()) ||
+		!reflect.DeepEqual(a.Queue, b.Queue) {
+		t.Errorf("two games from seed 42 parted ways: scores %d and %d at ticks %d and %d",
+			a.Score, b.Score, a.Tick(), b.Tick())
+	}
+	if reflect.DeepEqual(a.Board.GetPlacedBlocks(), other.Board.GetPlacedBlocks()) {
+		t.Error("seeds 42 and 43 built the same board")
+	}
+}
+
+// TestRNGStreamsIndependent checks that drawing from one stream leaves the
+// others where they were.
+func TestRNGStreamsIndependent(t *testing.T) {
+	quiet, busy := NewRNG(7), NewRNG(7)
+	for range 100 {
+		busy.Piece.IntN(7)
+	}
+	for range 10 {
+		if quiet.Charge.Uint64() != busy.Charge.Uint64() || quiet.Storm.Uint64() != busy.Storm.Uint64() {
+			t.Fatal("drawing pieces moved the charge or storm streams")
+		}
+	}
+}
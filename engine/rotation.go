@@ -0,0 +1,70 @@
+package engine
+
+// Turn is a rotation in quarter turns clockwise.
+type Turn int
+
+const (
+	TurnCW  Turn = 1
+	Turn180 Turn = 2
+	TurnCCW Turn = 3
+)
+
+func normalizeRotation(rotation int) int {
+	return ((rotation % 4) + 4) % 4
+}
+
+// RotatePiece turns the piece within its box without checking the board.
+// Each cell keeps its charge as it moves.
+func RotatePiece(piece *Piece, turn Turn) {
+	size := boxSize(piece.Type)
+	for range normalizeRotation(int(turn)) {
+		for i := range piece.Blocks {
+			block := &piece.Blocks[i]
+			block.X, block.Y = size-1-block.Y, block.X
+		}
+	}
+	piece.Rotation = normalizeRotation(piece.Rotation + int(turn))
+}
+
+// kick is a wall kick offset, written as in the SRS tables with y pointing
+// up. tryKicks flips it for the board, where y points down.
+type kick struct{ X, Y int }
+
+// jlstzKicks and iKicks are the standard SRS wall kicks, indexed by the
+// rotation state turned from and then to (0 spawn, 1 right, 2 flipped,
+// 3 left). The first kick of each is no kick at all.
+var jlstzKicks = [4][4][]kick{
+	0: {1: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}, 3: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}}},
+	1: {0: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}}, 2: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}}},
+	2: {1: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}, 3: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}}},
+	3: {2: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}}, 0: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}}},
+}
+
+var iKicks = [4][4][]kick{
+	0: {1: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}}, 3: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}}},
+	1: {0: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}}, 2: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}}},
+	2: {1: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}}, 3: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}}},
+	3: {2: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}}, 0: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}}},
+}
+
+// halfTurnKicks are used for 180 degree turns, which SRS leaves out. They
+// follow the common SRS+ tables and are shared by every piece.
+var halfTurnKicks = [4][]kick{
+	0: {{0, 0}, {0, 1}, {1, 1}, {-1, 1}, {1, 0}, {-1, 0}},
+	1: {{0, 0}, {1, 0}, {1, 2}, {1, 1}, {0, 2}, {0, 1}},
+	2: {{0, 0}, {0, -1}, {-1, -1}, {1, -1}, {-1, 0}, {1, 0}},
+	3: {{0, 0}, {-1, 0}, {-1, 2}, {-1, 1}, {0, 2}, {0, 1}},
+}
+
+func kicksFor(pieceType PieceType, from int, turn Turn) []kick {
+	to := normalizeRotation(from + int(turn))
+	switch {
+	case pieceType == OPiece:
+		return []kick{{0, 0}}
+	case turn == Turn180:
+		return halfTurnKicks[from]
+	case pieceType == IPiece:
+		return iKicks[from][to]
+	}
+	return jlstzKicks[from][to]
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func chargedPiece(pieceType PieceType, x, y int) *Piece {
+	charges := []BlockType{PositiveBlock, NegativeBlock, NeutralBlock, PositiveBlock}
+	i := 0
+	return NewPiece(pieceType, x, y, func() BlockType {
+		bt := charges[i%len(charges)]
+		i++
+		return bt
+	})
+}
+
+func TestRotationRoundTrips(t *testing.T) {
+	b := NewBoard(DefaultWidth, DefaultHeight, nil)
+	for _, pieceType := range PieceTypes {
+		for _, turns := range [][]Turn{
+			{TurnCW, TurnCCW},
+			{TurnCCW, TurnCW},
+			{Turn180, Turn180},
+			{TurnCW, TurnCW, TurnCW, TurnCW},
+			{TurnCW, TurnCW, Turn180},
+		} {
+			piece := chargedPiece(pieceType, 4, 8)
+			want := piece.Copy()
+			for _, turn := range turns {
+				if !b.TryRotatePiece(piece, turn) {
+					t.Fatalf("piece %d: turn %d failed on an empty board", pieceType, turn)
+				}
+			}
+			if piece.X != want.X || piece.Y != want.Y || piece.Rotation != want.Rotation ||
+				!slices.Equal(piece.Blocks, want.Blocks) {
+				t.Errorf("piece %d after %v = %+v, want %+v", pieceType, turns, piece, want)
+			}
+		}
+	}
+}
+
+func TestRotationKeepsCharges(t *testing.T) {
+	piece := chargedPiece(TPiece, 0, 0)
+	RotatePiece(piece, TurnCW)
+	want := []Block{
+		{X: 2, Y: 1, BlockType: PositiveBlock},
+		{X: 1, Y: 0, BlockType: NegativeBlock},
+		{X: 1, Y: 1, BlockType: NeutralBlock},
+		{X: 1, Y: 2, BlockType: PositiveBlock},
+	}
+	if !slices.Equal(piece.Blocks, want) {
+		t.Errorf("T turned clockwise = %v, want %v", piece.Blocks, want)
+	}
+}
+
+func TestWallKicks(t *testing.T) {
+	tests := []struct {
+		name      string
+		pieceType PieceType
+		rotation  int
+		x, y      int
+		turn      Turn
+		wantX     int
+		wantY     int
+	}{
+		// A vertical I against the left wall lies down by kicking right.
+		{"I off left wall", IPiece, 1, -2, 5, TurnCW, 0, 5},
+		// A vertical I against the right wall kicks left.
+		{"I off right wall", IPiece, 3, DefaultWidth - 2, 5, TurnCCW, DefaultWidth - 4, 5},
+		{"T off right wall", TPiece, 3, DefaultWidth - 2, 5, TurnCW, DefaultWidth - 3, 5},
+		{"J off left wall", JPiece, 1, -1, 5, TurnCCW, 0, 5},
+		// Lying flat on the floor, an I kicks up to stand.
+		{"I off floor", IPiece, 0, 4, DefaultHeight - 2, TurnCW, 5, DefaultHeight - 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBoard(DefaultWidth, DefaultHeight, nil)
+			piece := chargedPiece(tt.pieceType, tt.x, tt.y)
+			RotatePiece(piece, Turn(tt.rotation))
+			if !b.TryRotatePiece(piece, tt.turn) {
+				t.Fatal("rotation failed")
+			}
+			if piece.X != tt.wantX || piece.Y != tt.wantY {
+				t.Errorf("piece at (%d, %d), want (%d, %d)", piece.X, piece.Y, tt.wantX, tt.wantY)
+			}
+			if !b.IsValidPosition(piece, 0, 0) {
+				t.Error("piece was kicked somewhere it doesn't fit")
+			}
+		})
+	}
+}
+
+func TestBlockedRotationLeavesPiece(t *testing.T) {
+	b := parseBoard(t, `
+.....
+.+.+.
+.+.+.
+.+.+.
+.+.+.
+`)
+	// A vertical I in a one-wide well can't turn at all.
+	piece := chargedPiece(IPiece, 0, 1)
+	RotatePiece(piece, TurnCW)
+	want := piece.Copy()
+	if b.TryRotatePiece(piece, TurnCW) {
+		t.Fatal("rotation succeeded inside a one-wide well")
+	}
+	if piece.X != want.X || piece.Y != want.Y || piece.Rotation != want.Rotation ||
+		!slices.Equal(piece.Blocks, want.Blocks) {
+		t.Errorf("piece changed to %+v", piece)
+	}
+}
@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SaveVersion is the version of the save format written by Snapshot. Bump it
+// when SaveState changes shape.
+const SaveVersion = 10
+
+// SaveState is a complete, serializable copy of a game in progress, down to
+// blocks caught mid-wobble or mid-fall and the position of every random
+// stream, so a resumed game continues exactly where it stopped.
+type SaveState struct {
+	Version      int          `json:"version"`
+	RulesVersion int          `json:"rulesVersion"`
+	Config       Config       `json:"config"`
+	Tick         int          `json:"tick"`
+	Score        int          `json:"score"`
+	Level        int          `json:"level"`
+	Cleared      int          `json:"cleared"`
+	Pieces       int          `json:"pieces"`
+	Scoring      ScoringState `json:"scoring"`
+	GarbageIn    int          `json:"garbageIn"`
+	GarbageOut   int          `json:"garbageOut"`
+	Gravity      int          `json:"gravity"`
+	Current      *Piece       `json:"current"`
+	Queue        []*Piece     `json:"queue"`
+	Hold         *Piece       `json:"hold"`
+	HoldUsed     bool         `json:"holdUsed"`
+	LockTicks    int          `json:"lockTicks"`
+	LockResets   int          `json:"lockResets"`
+	LowestY      int          `json:"lowestY"`
+	Blocks       []Block      `json:"blocks"`
+	Storms       []Storm      `json:"storms"`
+	RNG          RNGState     `json:"rng"`
+	Randomizer   []PieceType  `json:"randomizer"`
+	Charges      []BlockType  `json:"charges"`
+	LastInput    Input        `json:"lastInput"`
+	LeftRepeat   int          `json:"leftRepeat"`
+	RightRepeat  int          `json:"rightRepeat"`
+	DropRepeat   int          `json:"dropRepeat"`
+}
+
+// RNGState holds the position of each random stream.
+type RNGState struct {
+	Seed   int64  `json:"seed"`
+	Piece  []byte `json:"piece"`
+	Charge []byte `json:"charge"`
+	Storm  []byte `json:"storm"`
+}
+
+func (r *RNG) State() (RNGState, error) {
+	piece, err := r.pieceSrc.MarshalBinary()
+	if err != nil {
+		return RNGState{}, err
+	}
+	charge, err := r.chargeSrc.MarshalBinary()
+	if err != nil {
+		return RNGState{}, err
+	}
+	storm, err := r.stormSrc.MarshalBinary()
+	if err != nil {
+		return RNGState{}, err
+	}
+	return RNGState{Seed: r.seed, Piece: piece, Charge: charge, Storm: storm}, nil
+}
+
+// RestoreRNG rebuilds the random streams at the position recorded in state.
+func RestoreRNG(state RNGState) (*RNG, error) {
+	r := NewRNG(state.Seed)
+	if err := r.pieceSrc.UnmarshalBinary(state.Piece); err != nil {
+		return nil, err
+	}
+	if err := r.chargeSrc.UnmarshalBinary(state.Charge); err != nil {
+		return nil, err
+	}
+	if err := r.stormSrc.UnmarshalBinary(state.Storm); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetPlacedBlocks replaces every block on the board.
+func (b *Board) SetPlacedBlocks(blocks []Block) {
+	b.placedBlocks = append(make([]Block, 0, len(blocks)), blocks...)
+}
+
+// GetStorms returns a copy of every active storm, ordered by column.
+func (b *Board) GetStorms() []Storm {
+	storms := make([]Storm, 0, len(b.activeStorms))
+	for _, column := range sortedColumns(b.activeStorms) {
+		storms = append(storms, *b.activeStorms[column])
+	}
+	return storms
+}
+
+// SetStorms replaces every active storm.
+func (b *Board) SetStorms(storms []Storm) {
+	b.activeStorms = make(map[int]*Storm, len(storms))
+	for _, storm := range storms {
+		b.activeStorms[storm.Column] = &storm
+	}
+}
+
+// Snapshot captures the game so it can be saved and resumed later.
+func (g *Game) Snapshot() (*SaveState, error) {
+	rngState, err := g.rng.State()
+	if err != nil {
+		return nil, err
+	}
+	state := &SaveState{
+		Version:      SaveVersion,
+		RulesVersion: RulesVersion,
+		Config:       g.config,
+		Tick:         g.tick,
+		Score:        g.Score,
+		Level:        g.level,
+		Cleared:      g.cleared,
+		Pieces:       g.pieces,
+		Scoring:      g.scoring,
+		GarbageIn:    g.garbageIn,
+		GarbageOut:   g.garbageOut,
+		Gravity:      g.gravity,
+		Blocks:       append([]Block(nil), g.Board.GetPlacedBlocks()...),
+		Storms:       g.Board.GetStorms(),
+		RNG:          rngState,
+		Randomizer:   g.randomizer.State(),
+		Charges:      g.charges.State(),
+		HoldUsed:     g.holdUsed,
+		LockTicks:    g.lockTicks,
+		LockResets:   g.lockResets,
+		LowestY:      g.lowestY,
+		LastInput:    g.lastInput,
+		LeftRepeat:   g.leftRepeat.ticksLeft,
+		RightRepeat:  g.rightRepeat.ticksLeft,
+		DropRepeat:   g.dropRepeat.ticksLeft,
+	}
+	if g.Current != nil {
+		state.Current = g.Current.Copy()
+	}
+	for _, piece := range g.Queue {
+		state.Queue = append(state.Queue, piece.Copy())
+	}
+	if g.Hold != nil {
+		state.Hold = g.Hold.Copy()
+	}
+	return state, nil
+}
+
+// RestoreGame rebuilds a game from a snapshot.
+func RestoreGame(state *SaveState) (*Game, error) {
+	if state.Version != SaveVersion {
+		return nil, fmt.Errorf("unsupported save version %d", state.Version)
+	}
+	if state.RulesVersion != RulesVersion {
+		return nil, fmt.Errorf("save was made under rules v%d, running v%d", state.RulesVersion, RulesVersion)
+	}
+	if err := state.Config.Timing.Validate(); err != nil {
+		return nil, err
+	}
+	rng, err := RestoreRNG(state.RNG)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Game{
+		Board:      NewBoard(DefaultWidth, DefaultHeight, rng.Storm),
+		Current:    state.Current,
+		Queue:      state.Queue,
+		Hold:       state.Hold,
+		holdUsed:   state.HoldUsed,
+		lockTicks:  state.LockTicks,
+		lockResets: state.LockResets,
+		lowestY:    state.LowestY,
+		Score:      state.Score,
+		config:     state.Config,
+		mode:       NewGameMode(state.Config),
+		level:      state.Level,
+		cleared:    state.Cleared,
+		pieces:     state.Pieces,
+		scoring:    state.Scoring,
+		garbageIn:  state.GarbageIn,
+		garbageOut: state.GarbageOut,
+		rng:        rng,
+		tick:       state.Tick,
+		gravity:    state.Gravity,
+		lastInput:  state.LastInput,
+	}
+	g.Board.Timing = state.Config.Timing.orDefault()
+	g.randomizer, g.charges = newDealers(state.Config, rng)
+	g.randomizer.SetState(state.Randomizer)
+	g.charges.SetState(state.Charges)
+	g.leftRepeat.ticksLeft = state.LeftRepeat
+	g.rightRepeat.ticksLeft = state.RightRepeat
+	g.dropRepeat.ticksLeft = state.DropRepeat
+	g.Board.SetPlacedBlocks(state.Blocks)
+	g.Board.SetStorms(state.Storms)
+	return g, nil
+}
+
+func WriteSave(w io.Writer, state *SaveState) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(state)
+}
+
+func ReadSave(r io.Reader) (*SaveState, error) {
+	var state SaveState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
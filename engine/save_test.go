@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSaveResumesExactly(t *testing.T) {
+	config := DefaultConfig()
+	config.Charges = ChargeBag
+	config.Randomizer = HistoryRandom
+	g := NewGame(NewRNG(21), config)
+	play(g, 0, 600)
+	if g.IsOver() {
+		t.Fatal("game ended before it could be saved")
+	}
+
+	state, err := g.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteSave(&buf, state); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadSave(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := RestoreGame(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	play(g, 600, 600)
+	play(resumed, 600, 600)
+	if resumed.Score != g.Score || resumed.Tick() != g.Tick() ||
+		!reflect.DeepEqual(resumed.Board.GetPlacedBlocks(), g.Board.GetPlacedBlocks()) ||
+		!reflect.DeepEqual(resumed.Queue, g.Queue) || !reflect.DeepEqual(resumed.Hold, g.Hold) {
+		t.Errorf("resumed game ended on score %d at tick %d, original on %d at tick %d",
+			resumed.Score, resumed.Tick(), g.Score, g.Tick())
+	}
+}
+
+func TestRestoreRejectsOtherVersions(t *testing.T) {
+	g := NewGame(NewRNG(3), DefaultConfig())
+	play(g, 0, 120)
+	for name, change := range map[string]func(*SaveState){
+		"save version":  func(s *SaveState) { s.Version = SaveVersion + 1 },
+		"rules version": func(s *SaveState) { s.RulesVersion = RulesVersion - 1 },
+	} {
+		state, err := g.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		change(state)
+		if _, err := RestoreGame(state); err == nil {
+			t.Errorf("restored a save with another %s", name)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+// Bonuses added to a reaction's points before the level multiplier.
+const (
+	// ComboBonus is paid for each consecutive locked piece, after the
+	// first, that sets off a reaction.
+	ComboBonus = 50
+	// StormAssistBonus is paid when a neutral block thrown by a storm sets
+	// off a reaction.
+	StormAssistBonus = 100
+)
+
+// Reaction describes a set of blocks neutralized together and what it
+// scored.
+type Reaction struct {
+	Blocks int
+	// Rows is how many rows the reaction spans; reactions in several rows
+	// at once score for each of them.
+	Rows int
+	// Chain is how deep in a cascade the reaction came: 1 for a reaction
+	// set off by a piece or a storm, 2 for the one the blocks falling after
+	// it set off, and so on.
+	Chain int
+	// Combo counts the consecutive locked pieces that have each set off a
+	// reaction. It is zero for reactions that no piece set off.
+	Combo         int
+	StormAssisted bool
+	Points        int
+}
+
+// ScoringState is the running chain and combo, and the best of each so far.
+type ScoringState struct {
+	Chain    int `json:"chain"`
+	Combo    int `json:"combo"`
+	MaxChain int `json:"maxChain"`
+	MaxCombo int `json:"maxCombo"`
+}
+
+// reactionSource is what set a reaction off.
+type reactionSource int
+
+const (
+	fromLock reactionSource = iota
+	fromCascade
+	fromStorm
+)
+
+// scoreReaction advances the chain and combo for a reaction of blocks set
+// off by source and works out its points at the current level.
+func (g *Game) scoreReaction(blocks []Block, source reactionSource) Reaction {
+	s := &g.scoring
+	reaction := Reaction{
+		Blocks:        len(blocks),
+		Rows:          countRows(blocks),
+		StormAssisted: source == fromStorm,
+	}
+
+	if source == fromLock {
+		s.Chain = 1
+		s.Combo++
+		reaction.Combo = s.Combo
+	} else {
+		s.Chain++
+	}
+	reaction.Chain = s.Chain
+	s.MaxChain = max(s.MaxChain, s.Chain)
+	s.MaxCombo = max(s.MaxCombo, s.Combo)
+
+	points := g.Board.calculateReactionScore(reaction.Blocks) * reaction.Chain * reaction.Rows
+	if reaction.Combo > 1 {
+		points += ComboBonus * (reaction.Combo - 1)
+	}
+	if reaction.StormAssisted {
+		points += StormAssistBonus
+	}
+	reaction.Points = points * g.level
+	return reaction
+}
+
+// endChainIfSettled ends the chain once nothing on the board is still
+// wobbling, falling or in flight, as no further cascade can follow.
+func (g *Game) endChainIfSettled() {
+	if g.scoring.Chain == 0 {
+		return
+	}
+	for _, block := range g.Board.placedBlocks {
+		if block.IsWobbling || block.IsFalling || block.IsArcing {
+			return
+		}
+	}
+	g.scoring.Chain = 0
+}
+
+func countRows(blocks []Block) int {
+	rows := make(map[int]bool)
+	for _, block := range blocks {
+		rows[block.Y] = true
+	}
+	return len(rows)
+}
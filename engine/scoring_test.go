@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+// fixtureGame starts a game with the fixture at path laid along the bottom
+// of its board.
+func fixtureGame(t *testing.T, path string) *Game {
+	t.Helper()
+	text, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := parseBoard(t, string(text))
+
+	g := NewGame(NewRNG(1), DefaultConfig())
+	neutralize(g)
+	offset := g.Board.Height - fixture.Height
+	blocks := fixture.GetPlacedBlocks()
+	for i := range blocks {
+		blocks[i].Y += offset
+	}
+	g.Board.SetPlacedBlocks(blocks)
+	return g
+}
+
+func TestChainDeepensThroughCascade(t *testing.T) {
+	g := fixtureGame(t, "testdata/rules/cascade_chain.txt")
+
+	var chains []int
+	g.SetReactionStartedCallback(func(blocks []Block, reaction Reaction) {
+		chains = append(chains, reaction.Chain)
+	})
+	g.checkReactions(fromLock)
+	for range 10 * WobbleTicks {
+		g.Step(Input{})
+	}
+
+	if !slices.Equal(chains, []int{1, 2, 3}) {
+		t.Errorf("reactions scored at chain depths %v, want [1 2 3]", chains)
+	}
+	if s := g.Scoring(); s.Chain != 0 || s.MaxChain != 3 {
+		t.Errorf("after settling chain is %d with best %d, want 0 and 3", s.Chain, s.MaxChain)
+	}
+}
+
+func TestReactionPoints(t *testing.T) {
+	bottom := DefaultHeight - 1
+	oneRow := []Block{{X: 0, Y: bottom}, {X: 1, Y: bottom}, {X: 2, Y: bottom}, {X: 3, Y: bottom}}
+	twoRows := []Block{{X: 0, Y: bottom}, {X: 1, Y: bottom}, {X: 0, Y: bottom - 1}, {X: 1, Y: bottom - 1}}
+
+	tests := []struct {
+		name    string
+		scoring ScoringState
+		blocks  []Block
+		source  reactionSource
+		want    Reaction
+	}{
+		{
+			name:   "first lock",
+			blocks: oneRow,
+			source: fromLock,
+			want:   Reaction{Blocks: 4, Rows: 1, Chain: 1, Combo: 1, Points: 10},
+		},
+		{
+			name:    "third lock in a row",
+			scoring: ScoringState{Combo: 2},
+			blocks:  oneRow,
+			source:  fromLock,
+			want:    Reaction{Blocks: 4, Rows: 1, Chain: 1, Combo: 3, Points: 10 + 2*ComboBonus},
+		},
+		{
+			name:    "third link of a chain",
+			scoring: ScoringState{Chain: 2, Combo: 1},
+			blocks:  oneRow,
+			source:  fromCascade,
+			want:    Reaction{Blocks: 4, Rows: 1, Chain: 3, Points: 30},
+		},
+		{
+			name:   "two rows at once",
+			blocks: twoRows,
+			source: fromLock,
+			want:   Reaction{Blocks: 4, Rows: 2, Chain: 1, Combo: 1, Points: 20},
+		},
+		{
+			name:   "storm assisted",
+			blocks: oneRow,
+			source: fromStorm,
+			want:   Reaction{Blocks: 4, Rows: 1, Chain: 1, StormAssisted: true, Points: 10 + StormAssistBonus},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(NewRNG(1), DefaultConfig())
+			g.scoring = tt.scoring
+			if got := g.scoreReaction(tt.blocks, tt.source); got != tt.want {
+				t.Errorf("scoreReaction = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComboBreaksOnQuietLock(t *testing.T) {
+	g := NewGame(NewRNG(3), DefaultConfig())
+	g.scoring.Combo = 4
+	neutralize(g)
+	g.HardDrop()
+	if s := g.Scoring(); s.Combo != 0 {
+		t.Errorf("combo %d after a lock without a reaction, want 0", s.Combo)
+	}
+}
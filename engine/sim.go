@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"math"
+	"slices"
+)
+
+// TimeLimit marks a simulated game that was stopped at its tick limit
+// rather than lost.
+const TimeLimit EndCause = "timeLimit"
+
+// SimResult is how one simulated game went.
+type SimResult struct {
+	Seed      int64 `json:"seed"`
+	Score     int   `json:"score"`
+	Level     int   `json:"level"`
+	Ticks     int   `json:"ticks"`
+	Pieces    int   `json:"pieces"`
+	Reactions int   `json:"reactions"`
+	// Cleared is how many blocks were neutralized.
+	Cleared  int `json:"cleared"`
+	MaxChain int `json:"maxChain"`
+	Storms   int `json:"storms"`
+	// Neutrals is how many neutral blocks storms dropped.
+	Neutrals int      `json:"neutrals"`
+	End      EndCause `json:"end"`
+}
+
+func (r SimResult) ReactionsPerPiece() float64 {
+	if r.Pieces == 0 {
+		return 0
+	}
+	return float64(r.Reactions) / float64(r.Pieces)
+}
+
+func (r SimResult) Minutes() float64 {
+	return TicksToSeconds(r.Ticks) / 60
+}
+
+// Simulate plays a game from seed under config with controller at the
+// controls, as fast as it will run, until it ends or reaches maxTicks.
+func Simulate(seed int64, config Config, controller Controller, maxTicks int) SimResult {
+	g := NewGame(NewRNG(seed), config)
+	r := SimResult{Seed: seed}
+	g.SetReactionStartedCallback(func([]Block, Reaction) { r.Reactions++ })
+	g.SetStormIgnitedCallback(func(int) { r.Storms++ })
+	g.SetNeutralSpawnedCallback(func(Block) { r.Neutrals++ })
+
+	for g.Tick() < maxTicks && !g.IsOver() {
+		g.Step(controller.Input(g.View()))
+	}
+
+	r.Score = g.Score
+	r.Level = g.Level()
+	r.Ticks = g.Tick()
+	r.Pieces = g.Pieces()
+	r.Cleared = g.BlocksCleared()
+	r.MaxChain = g.Scoring().MaxChain
+	r.End = g.EndCause()
+	if r.End == "" {
+		r.End = TimeLimit
+	}
+	return r
+}
+
+// Distribution summarizes a set of values by its spread.
+type Distribution struct {
+	Min    float64 `json:"min"`
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+// distributionOf returns the spread of values, with percentiles taken by
+// nearest rank.
+func distributionOf(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[min(max(i, 0), len(sorted)-1)]
+	}
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return Distribution{
+		Min:    sorted[0],
+		P25:    rank(0.25),
+		Median: rank(0.5),
+		P75:    rank(0.75),
+		P90:    rank(0.9),
+		Max:    sorted[len(sorted)-1],
+		Mean:   sum / float64(len(sorted)),
+	}
+}
+
+// SimSummary is the statistics of a batch of simulated games.
+type SimSummary struct {
+	Games             int          `json:"games"`
+	Score             Distribution `json:"score"`
+	Minutes           Distribution `json:"minutes"`
+	Pieces            Distribution `json:"pieces"`
+	ReactionsPerPiece Distribution `json:"reactionsPerPiece"`
+	// StormsPerMinute and NeutralsPerMinute are over all the games'
+	// playing time together.
+	StormsPerMinute   float64          `json:"stormsPerMinute"`
+	NeutralsPerMinute float64          `json:"neutralsPerMinute"`
+	Ends              map[EndCause]int `json:"ends"`
+}
+
+func Summarize(results []SimResult) SimSummary {
+	s := SimSummary{Games: len(results), Ends: make(map[EndCause]int)}
+	var scores, minutes, pieces, perPiece []float64
+	storms, neutrals, totalMinutes := 0, 0, 0.0
+	for _, r := range results {
+		scores = append(scores, float64(r.Score))
+		minutes = append(minutes, r.Minutes())
+		pieces = append(pieces, float64(r.Pieces))
+		perPiece = append(perPiece, r.ReactionsPerPiece())
+		storms += r.Storms
+		neutrals += r.Neutrals
+		totalMinutes += r.Minutes()
+		s.Ends[r.End]++
+	}
+	s.Score = distributionOf(scores)
+	s.Minutes = distributionOf(minutes)
+	s.Pieces = distributionOf(pieces)
+	s.ReactionsPerPiece = distributionOf(perPiece)
+	if totalMinutes > 0 {
+		s.StormsPerMinute = float64(storms) / totalMinutes
+		s.NeutralsPerMinute = float64(neutrals) / totalMinutes
+	}
+	return s
+}
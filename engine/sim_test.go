@@ -0,0 +1,78 @@
+package engine
+
+import "testing"
+
+func TestSimulateIsRepeatable(t *testing.T) {
+	run := func() SimResult {
+		return Simulate(9, DefaultConfig(), NewBot(DefaultBotWeights(), 1), 30*TicksPerSecond)
+	}
+	first, again := run(), run()
+	if first != again {
+		t.Errorf("same seed simulated differently:\n%+v\n%+v", first, again)
+	}
+	if first.Pieces == 0 || first.End == "" {
+		t.Errorf("simulation recorded nothing: %+v", first)
+	}
+}
+
+func TestSimulateStopsAtTimeLimit(t *testing.T) {
+	r := Simulate(3, DefaultConfig(), NewBot(DefaultBotWeights(), 1), 100)
+	if r.Ticks != 100 || r.End != TimeLimit {
+		t.Errorf("got %d ticks ending %q, want 100 ending %q", r.Ticks, r.End, TimeLimit)
+	}
+}
+
+func TestEndCauses(t *testing.T) {
+	g := NewGame(NewRNG(2), DefaultConfig())
+	neutralize(g)
+	g.Current.Y = 0
+	g.Current.Blocks[0].BlockType = PositiveBlock
+	g.Current.Blocks[0].Y = 0
+	g.LockPiece()
+	if g.EndCause() != TopOut {
+		t.Errorf("locking into the top row ended the game with %q, want %q", g.EndCause(), TopOut)
+	}
+
+	g = NewGame(NewRNG(2), DefaultConfig())
+	var blocks []Block
+	for x := range g.Board.Width {
+		for y := range 2 {
+			blocks = append(blocks, Block{X: x, Y: y, BlockType: PositiveBlock})
+		}
+	}
+	g.Board.SetPlacedBlocks(blocks)
+	g.SpawnPiece()
+	if g.EndCause() != BlockOut {
+		t.Errorf("spawning into a full board ended the game with %q, want %q", g.EndCause(), BlockOut)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var results []SimResult
+	for i := 1; i <= 10; i++ {
+		results = append(results, SimResult{
+			Score:     i * 100,
+			Ticks:     60 * TicksPerSecond,
+			Pieces:    10,
+			Reactions: i,
+			Storms:    2,
+			End:       TopOut,
+		})
+	}
+	results[9].End = TimeLimit
+
+	s := Summarize(results)
+	want := Distribution{Min: 100, P25: 300, Median: 500, P75: 800, P90: 900, Max: 1000, Mean: 550}
+	if s.Score != want {
+		t.Errorf("score distribution %+v, want %+v", s.Score, want)
+	}
+	if s.ReactionsPerPiece.Mean != 0.55 {
+		t.Errorf("mean reactions per piece %v, want 0.55", s.ReactionsPerPiece.Mean)
+	}
+	if s.StormsPerMinute != 2 {
+		t.Errorf("%v storms per minute, want 2", s.StormsPerMinute)
+	}
+	if s.Ends[TopOut] != 9 || s.Ends[TimeLimit] != 1 {
+		t.Errorf("ends %v, want 9 top outs and 1 time limit", s.Ends)
+	}
+}
@@ -0,0 +1,285 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+type Storm struct {
+	Column       int
+	Timer        int
+	NextDrop     int
+	IsActive     bool
+	IsWarning    bool
+	WarningTicks int
+}
+
+func (b *Board) findVerticalElectricalStorms() []Block {
+	var stormBlocks []Block
+	columnMap := make(map[int][]Block)
+	for _, block := range b.placedBlocks {
+		if !block.IsWobbling && block.BlockType != NeutralBlock {
+			columnMap[block.X] = append(columnMap[block.X], block)
+		}
+	}
+	for _, columnBlocks := range columnMap {
+		if len(columnBlocks) < 4 {
+			continue
+		}
+		for i := 0; i < len(columnBlocks); i++ {
+			for j := i + 1; j < len(columnBlocks); j++ {
+				if columnBlocks[i].Y > columnBlocks[j].Y {
+					columnBlocks[i], columnBlocks[j] = columnBlocks[j], columnBlocks[i]
+				}
+			}
+		}
+		stormSequences := b.findVerticalStormSequences(columnBlocks)
+		for _, sequence := range stormSequences {
+			stormBlocks = append(stormBlocks, sequence...)
+		}
+	}
+	return stormBlocks
+}
+
+func (b *Board) findVerticalStormSequences(columnBlocks []Block) [][]Block {
+	var sequences [][]Block
+	var currentSequence []Block
+	var currentType BlockType = -1
+	for i, block := range columnBlocks {
+		if block.BlockType == currentType && (i == 0 || block.Y == columnBlocks[i-1].Y+1) {
+			currentSequence = append(currentSequence, block)
+		} else {
+			if len(currentSequence) >= 4 {
+				sequences = append(sequences, currentSequence)
+			}
+			currentSequence = []Block{block}
+			currentType = block.BlockType
+		}
+	}
+	if len(currentSequence) >= 4 {
+		sequences = append(sequences, currentSequence)
+	}
+	return sequences
+}
+
+func (b *Board) StartElectricalStorm(stormBlocks []Block) {
+	if len(stormBlocks) == 0 {
+		return
+	}
+	stormMap := make(map[string]bool)
+	for _, block := range stormBlocks {
+		key := fmt.Sprintf("%d,%d", block.X, block.Y)
+		stormMap[key] = true
+	}
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		key := fmt.Sprintf("%d,%d", block.X, block.Y)
+		if stormMap[key] {
+			if !block.IsInStorm {
+				block.IsInStorm = true
+				block.StormTicks = 0
+				block.StormPhase = 0
+				block.SparkPhase = 0
+			}
+		}
+	}
+}
+
+func (b *Board) UpdateElectricalStorms() {
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		if block.IsInStorm {
+			block.StormTicks++
+			block.StormPhase += TickSeconds * StormFrequency * 2 * math.Pi
+			block.SparkPhase += TickSeconds * SparkFrequency * 2 * math.Pi
+		}
+	}
+}
+
+func (b *Board) ClearInvalidStorms() {
+	validStormBlocks := b.findVerticalElectricalStorms()
+	validStormMap := make(map[string]bool)
+	for _, block := range validStormBlocks {
+		key := fmt.Sprintf("%d,%d", block.X, block.Y)
+		validStormMap[key] = true
+	}
+	// Track columns and block types with <4 blocks
+	columnTypeCount := make(map[[2]int]int)
+	for _, block := range b.placedBlocks {
+		if block.IsInStorm {
+			columnTypeCount[[2]int{block.X, int(block.BlockType)}]++
+		}
+	}
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		if block.IsInStorm {
+			key := fmt.Sprintf("%d,%d", block.X, block.Y)
+			if !validStormMap[key] || columnTypeCount[[2]int{block.X, int(block.BlockType)}] < 4 {
+				block.IsInStorm = false
+				block.StormTicks = 0
+				block.StormPhase = 0
+				block.SparkPhase = 0
+			}
+		}
+	}
+}
+
+// CheckForElectricalStorms starts storms in any column that has earned one
+// and returns the columns that ignited.
+func (b *Board) CheckForElectricalStorms() []int {
+	stormBlocks := b.findVerticalElectricalStorms()
+	if len(stormBlocks) == 0 {
+		return nil
+	}
+	b.StartElectricalStorm(stormBlocks)
+	return b.UpdateActiveStorms()
+}
+
+func (b *Board) generateStormTimer() int {
+	return b.Timing.StormMinTicks + b.stormRand.IntN(b.Timing.StormMaxTicks-b.Timing.StormMinTicks+1)
+}
+
+func (b *Board) UpdateStormTimers() []Block {
+	var newNeutralBlocks []Block
+	for _, column := range sortedColumns(b.activeStorms) {
+		storm := b.activeStorms[column]
+		if storm.IsActive {
+			storm.Timer++
+			ticksUntilSpawn := storm.NextDrop - storm.Timer
+			if ticksUntilSpawn <= b.Timing.WarningTicks && !storm.IsWarning {
+				storm.IsWarning = true
+				storm.WarningTicks = 0
+			}
+			if storm.IsWarning {
+				storm.WarningTicks++
+			}
+			if storm.Timer >= storm.NextDrop {
+				storm.IsWarning = false
+				storm.WarningTicks = 0
+				highestStormBlock := b.FindHighestStormBlock(storm.Column)
+				if highestStormBlock == nil {
+					storm.Timer = 0
+					storm.NextDrop = b.generateStormTimer()
+					continue
+				}
+				targetColumn := b.stormRand.IntN(b.Width)
+				neutralBlock := Block{
+					X:         targetColumn,
+					Y:         0,
+					BlockType: NeutralBlock,
+					IsArcing:  true,
+					IsFalling: false,
+				}
+				StartBlockArc(&neutralBlock,
+					float64(highestStormBlock.X), float64(highestStormBlock.Y),
+					float64(targetColumn), 0.0)
+				positionFree := true
+				for _, placedBlock := range b.placedBlocks {
+					if placedBlock.X == targetColumn && placedBlock.Y == 0 {
+						positionFree = false
+						break
+					}
+				}
+				if positionFree {
+					newNeutralBlocks = append(newNeutralBlocks, neutralBlock)
+				}
+				storm.Timer = 0
+				storm.NextDrop = b.generateStormTimer()
+			}
+		}
+	}
+	return newNeutralBlocks
+}
+
+// UpdateActiveStorms matches the active storms to the blocks in a storm
+// and returns the columns that gained one.
+func (b *Board) UpdateActiveStorms() []int {
+	var ignited []int
+	stormColumns := make(map[int]bool)
+	for _, block := range b.placedBlocks {
+		if block.IsInStorm {
+			stormColumns[block.X] = true
+		}
+	}
+	for _, column := range sortedColumns(stormColumns) {
+		if _, exists := b.activeStorms[column]; !exists {
+			b.activeStorms[column] = &Storm{
+				Column:   column,
+				Timer:    0,
+				NextDrop: b.generateStormTimer(),
+				IsActive: true,
+			}
+			ignited = append(ignited, column)
+		}
+	}
+	for column, storm := range b.activeStorms {
+		if !stormColumns[column] {
+			storm.IsActive = false
+			delete(b.activeStorms, column)
+		}
+	}
+	return ignited
+}
+
+// sortedColumns returns the keys of a per-column map in ascending order.
+// Storms draw from the random stream as they are visited, so the visiting
+// order has to be fixed for a seed to replay the same game.
+func sortedColumns[V any](columns map[int]V) []int {
+	keys := make([]int, 0, len(columns))
+	for column := range columns {
+		keys = append(keys, column)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// columnTop returns the row of the highest block in a column, or the
+// board's height if the column is empty.
+func (b *Board) columnTop(column int) int {
+	top := b.Height
+	for _, block := range b.placedBlocks {
+		if block.X == column && !block.IsArcing && block.Y < top {
+			top = block.Y
+		}
+	}
+	return top
+}
+
+func (b *Board) FindHighestStormBlock(column int) *Block {
+	var highestBlock *Block
+	highestY := 999
+	for i := range b.placedBlocks {
+		block := &b.placedBlocks[i]
+		if block.X == column && block.IsInStorm && block.Y < highestY {
+			highestY = block.Y
+			highestBlock = block
+		}
+	}
+	return highestBlock
+}
+
+// StormWarning describes a storm column that is about to throw a neutral
+// block.
+type StormWarning struct {
+	Column        int
+	WarningTicks  int
+	HighestBlockY int
+}
+
+func (b *Board) GetStormWarnings() []StormWarning {
+	var warnings []StormWarning
+	for _, storm := range b.activeStorms {
+		if storm.IsWarning {
+			highestBlock := b.FindHighestStormBlock(storm.Column)
+			if highestBlock != nil {
+				warnings = append(warnings, StormWarning{
+					Column:        storm.Column,
+					WarningTicks:  storm.WarningTicks,
+					HighestBlockY: highestBlock.Y,
+				})
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,54 @@
+package engine
+
+// Versus is a two-player match. Both games are dealt from the same seed, so
+// the players see the same pieces, and the garbage each sends is passed to
+// the other after every tick. The match ends when either game does.
+type Versus struct {
+	Games [2]*Game
+}
+
+func NewVersus(seed int64, config Config) *Versus {
+	config.Mode = ClassicMode
+	return &Versus{Games: [2]*Game{
+		NewGame(NewRNG(seed), config),
+		NewGame(NewRNG(seed), config),
+	}}
+}
+
+// Step advances both games by one tick, each on its player's input, then
+// exchanges garbage.
+func (v *Versus) Step(inputs [2]Input) {
+	if v.Over() {
+		return
+	}
+	for i, g := range v.Games {
+		g.Step(inputs[i])
+	}
+	v.Exchange()
+}
+
+// Exchange passes the garbage each game has sent to the other. Callers that
+// step the games themselves call it after every tick.
+func (v *Versus) Exchange() {
+	first, second := v.Games[0], v.Games[1]
+	toSecond := first.TakeOutgoingGarbage()
+	toFirst := second.TakeOutgoingGarbage()
+	first.ReceiveGarbage(toFirst)
+	second.ReceiveGarbage(toSecond)
+}
+
+func (v *Versus) Over() bool {
+	return v.Games[0].IsOver() || v.Games[1].IsOver()
+}
+
+// Winner returns the player still standing once the match is over, or -1
+// while it goes on or if both players went out on the same tick.
+func (v *Versus) Winner() int {
+	switch first, second := v.Games[0].IsOver(), v.Games[1].IsOver(); {
+	case first && !second:
+		return 1
+	case second && !first:
+		return 0
+	}
+	return -1
+}
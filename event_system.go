@@ -1,55 +1,212 @@
 package main
 
-type EventType int
-
-const (
-	EventBlocksRemoved EventType = iota
-	EventPiecePlaced
-	EventHardDrop
-	EventLineCleared
-	EventGameOver
+import (
+	"reflect"
+	"slices"
 )
 
-type GameEvent struct {
-	Type EventType
-	Data interface{}
+// Event is implemented by every payload that travels on the bus. Listeners
+// subscribe to a concrete event type and receive it already typed.
+type Event interface {
+	isEvent()
+}
+
+// The piece events carry a copy of the piece as it stood when the event
+// was published; the live piece has usually moved on by the time the queue
+// is flushed.
+type PieceSpawnedEvent struct {
+	Piece TetrisPiece
+}
+
+type PieceMovedEvent struct {
+	Piece TetrisPiece
 }
 
-type BlocksRemovedData struct {
-	Count     int
-	Positions []Position
+type PieceRotatedEvent struct {
+	Piece TetrisPiece
 }
 
-type PiecePlacedData struct {
+type PieceLockedEvent struct {
+	Piece TetrisPiece
+	// Position is the middle of the piece's bottom edge, where it meets
+	// the stack.
 	Position Position
 }
 
-type HardDropData struct {
+type HardDropEvent struct {
 	DropHeight int
 }
 
+type ReactionStartedEvent struct {
+	Count         int
+	Points        int
+	Rows          int
+	Chain         int
+	Combo         int
+	StormAssisted bool
+	Positions     []Position
+}
+
+// ReactionFinishedEvent is sent as the neutralized blocks leave the board.
+type ReactionFinishedEvent struct {
+	Count  int
+	Blocks []RemovedBlock
+}
+
+type RemovedBlock struct {
+	Position  Position
+	BlockType BlockType
+}
+
+type StormIgnitedEvent struct {
+	Column int
+}
+
+// GarbageRaisedEvent is published when versus garbage rises into the
+// board from below.
+type GarbageRaisedEvent struct {
+	Rows int
+}
+
+type NeutralDroppedEvent struct {
+	Position Position
+}
+
+type ScoreChangedEvent struct {
+	Score int
+	Delta int
+}
+
+type GameOverEvent struct {
+	Score int
+	Level int
+}
+
+func (PieceSpawnedEvent) isEvent()     {}
+func (PieceMovedEvent) isEvent()       {}
+func (PieceRotatedEvent) isEvent()     {}
+func (PieceLockedEvent) isEvent()      {}
+func (HardDropEvent) isEvent()         {}
+func (ReactionStartedEvent) isEvent()  {}
+func (ReactionFinishedEvent) isEvent() {}
+func (StormIgnitedEvent) isEvent()     {}
+func (GarbageRaisedEvent) isEvent()    {}
+func (NeutralDroppedEvent) isEvent()   {}
+func (ScoreChangedEvent) isEvent()     {}
+func (GameOverEvent) isEvent()         {}
+
 type Position struct {
 	X, Y float64
 }
 
+// Listener priorities. Higher priorities hear an event first; listeners
+// of equal priority hear it in the order they subscribed.
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+type listener struct {
+	priority int
+	order    int
+	handle   func(Event)
+	removed  bool
+}
+
+// EventSystem carries events from the game to its effects. Publish queues
+// an event and Flush delivers the queue, so a listener never runs while the
+// engine is part way through a scan of the board.
 type EventSystem struct {
-	listeners map[EventType][]func(GameEvent)
+	listeners map[reflect.Type][]*listener
+	queue     []Event
+	nextOrder int
+	flushing  bool
 }
 
 func NewEventSystem() *EventSystem {
 	return &EventSystem{
-		listeners: make(map[EventType][]func(GameEvent)),
+		listeners: make(map[reflect.Type][]*listener),
+	}
+}
+
+// Subscription is returned by Subscribe and removes its listener when
+// cancelled.
+type Subscription struct {
+	bus       *EventSystem
+	eventType reflect.Type
+	listener  *listener
+}
+
+// Unsubscribe stops the listener hearing any further events, including ones
+// already queued. It is safe to call more than once.
+func (s Subscription) Unsubscribe() {
+	if s.listener == nil || s.listener.removed {
+		return
 	}
+	s.listener.removed = true
+	s.bus.listeners[s.eventType] = slices.DeleteFunc(s.bus.listeners[s.eventType], func(l *listener) bool {
+		return l == s.listener
+	})
+}
+
+// Subscribe registers handle to receive every event of type T at normal
+// priority.
+func Subscribe[T Event](bus *EventSystem, handle func(T)) Subscription {
+	return SubscribeWithPriority(bus, PriorityNormal, handle)
 }
 
-func (es *EventSystem) Subscribe(eventType EventType, callback func(GameEvent)) {
-	es.listeners[eventType] = append(es.listeners[eventType], callback)
+// SubscribeWithPriority registers handle to receive every event of type T,
+// ahead of listeners with a lower priority.
+func SubscribeWithPriority[T Event](bus *EventSystem, priority int, handle func(T)) Subscription {
+	eventType := reflect.TypeFor[T]()
+	l := &listener{
+		priority: priority,
+		order:    bus.nextOrder,
+		handle:   func(event Event) { handle(event.(T)) },
+	}
+	bus.nextOrder++
+
+	listeners := append(bus.listeners[eventType], l)
+	slices.SortStableFunc(listeners, func(a, b *listener) int {
+		if a.priority != b.priority {
+			return b.priority - a.priority
+		}
+		return a.order - b.order
+	})
+	bus.listeners[eventType] = listeners
+
+	return Subscription{bus: bus, eventType: eventType, listener: l}
+}
+
+// Publish queues event for the next Flush.
+func (es *EventSystem) Publish(event Event) {
+	es.queue = append(es.queue, event)
+}
+
+// Flush delivers every queued event in the order it was published. Events
+// published by listeners during the flush are delivered before it returns.
+func (es *EventSystem) Flush() {
+	if es.flushing {
+		return
+	}
+	es.flushing = true
+	defer func() { es.flushing = false }()
+
+	for len(es.queue) > 0 {
+		event := es.queue[0]
+		es.queue = es.queue[1:]
+		es.dispatch(event)
+	}
+	es.queue = nil
 }
 
-func (es *EventSystem) Emit(event GameEvent) {
-	if callbacks, exists := es.listeners[event.Type]; exists {
-		for _, callback := range callbacks {
-			callback(event)
+func (es *EventSystem) dispatch(event Event) {
+	// Listeners may subscribe or unsubscribe as they run, so walk a copy.
+	listeners := slices.Clone(es.listeners[reflect.TypeOf(event)])
+	for _, l := range listeners {
+		if !l.removed {
+			l.handle(event)
 		}
 	}
 }
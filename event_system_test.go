@@ -0,0 +1,96 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSubscribePriorityOrder(t *testing.T) {
+	bus := NewEventSystem()
+	var heard []string
+	hear := func(name string) func(StormIgnitedEvent) {
+		return func(StormIgnitedEvent) { heard = append(heard, name) }
+	}
+	Subscribe(bus, hear("normal 1"))
+	SubscribeWithPriority(bus, PriorityLow, hear("low"))
+	SubscribeWithPriority(bus, PriorityHigh, hear("high"))
+	Subscribe(bus, hear("normal 2"))
+	Subscribe(bus, func(HardDropEvent) { heard = append(heard, "other type") })
+
+	bus.Publish(StormIgnitedEvent{Column: 3})
+	bus.Flush()
+
+	want := []string{"high", "normal 1", "normal 2", "low"}
+	if !slices.Equal(heard, want) {
+		t.Errorf("listeners heard the event in order %v, want %v", heard, want)
+	}
+}
+
+func TestFlushDeliversQueuedEvents(t *testing.T) {
+	bus := NewEventSystem()
+	var columns []int
+	Subscribe(bus, func(data StormIgnitedEvent) {
+		columns = append(columns, data.Column)
+		// Events published while flushing go out before Flush returns.
+		if data.Column == 1 {
+			bus.Publish(StormIgnitedEvent{Column: 9})
+		}
+	})
+
+	bus.Publish(StormIgnitedEvent{Column: 1})
+	bus.Publish(StormIgnitedEvent{Column: 2})
+	if len(columns) != 0 {
+		t.Fatalf("listener heard %v before the flush", columns)
+	}
+	bus.Flush()
+	if want := []int{1, 2, 9}; !slices.Equal(columns, want) {
+		t.Errorf("flush delivered %v, want %v", columns, want)
+	}
+
+	bus.Flush()
+	if len(columns) != 3 {
+		t.Errorf("second flush redelivered events: %v", columns)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := NewEventSystem()
+	heard := 0
+	sub := Subscribe(bus, func(HardDropEvent) { heard++ })
+
+	bus.Publish(HardDropEvent{DropHeight: 1})
+	bus.Flush()
+	bus.Publish(HardDropEvent{DropHeight: 2})
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	bus.Flush()
+
+	if heard != 1 {
+		t.Errorf("listener heard %d events, want only the one before unsubscribing", heard)
+	}
+}
+
+func TestUnsubscribeDuringDispatch(t *testing.T) {
+	bus := NewEventSystem()
+	var heard []string
+	var late Subscription
+	Subscribe(bus, func(GameOverEvent) {
+		heard = append(heard, "first")
+		late.Unsubscribe()
+	})
+	late = Subscribe(bus, func(GameOverEvent) { heard = append(heard, "late") })
+
+	var self Subscription
+	self = SubscribeWithPriority(bus, PriorityHigh, func(GameOverEvent) {
+		heard = append(heard, "once")
+		self.Unsubscribe()
+	})
+
+	bus.Publish(GameOverEvent{})
+	bus.Publish(GameOverEvent{})
+	bus.Flush()
+
+	if want := []string{"once", "first", "first"}; !slices.Equal(heard, want) {
+		t.Errorf("listeners heard %v, want %v", heard, want)
+	}
+}
@@ -1,11 +1,12 @@
 package main
 
 import (
-	"time"
-
-	stopwatch "github.com/RAshkettle/Stopwatch"
+	"fmt"
+	"union/engine"
 )
 
+// GameComponents builds everything a game in progress needs and wires the
+// effects, audio and stats to the events GameLogic publishes.
 type GameComponents struct {
 	Gameboard       *Gameboard
 	BlockManager    *BlockManager
@@ -19,28 +20,27 @@ type GameComponents struct {
 	PauseController *PauseController
 	EventSystem     *EventSystem
 	GameState       *GameState
-	CurrentPiece    *TetrisPiece
-	CurrentType     PieceType
-	NextPiece       *TetrisPiece
-	NextType        PieceType
-	FallTimer       *stopwatch.Stopwatch
+	Stats           *GameStats
+	// Settings are shared with the settings scene and read as effects
+	// play, so changes apply straight away.
+	Settings *Settings
 }
 
-func NewGameComponents() *GameComponents {
+// NewGameComponents assembles the components around game. The audio
+// manager is shared between games, as Ebiten allows only one audio context.
+func NewGameComponents(game *engine.Game, audioManager *AudioManager, settings *Settings) *GameComponents {
 	gameState := NewGameState()
 	eventSystem := NewEventSystem()
-	gameboard := NewGameboard(192, 320)
-	blockManager := NewBlockManager()
-	gameLogic := NewGameLogic(gameboard, blockManager)
+	gameboard := NewGameboard(GameboardWidth, GameboardHeight)
+	blockManager := NewBlockManager(&settings.Blocks)
+	blockManager.SetTiming(game.Board.Timing)
+	gameLogic := NewGameLogic(gameboard, blockManager, game, eventSystem)
 	renderer := NewGameRenderer(gameboard, blockManager)
-	audioManager := NewAudioManager()
 	particleSystem := NewParticleSystem()
 	screenShake := NewScreenShake()
 	scorePopups := NewScorePopupSystem()
-	pauseController := NewPauseController(gameState, audioManager)
-	inputHandler := NewInputHandler(gameLogic, audioManager)
-	fallTimer := stopwatch.NewStopwatch(1 * time.Second)
-	fallTimer.Start()
+	inputHandler := NewInputHandler(settings)
+	pauseController := NewPauseController(gameState, audioManager, inputHandler)
 
 	components := &GameComponents{
 		Gameboard:       gameboard,
@@ -55,12 +55,8 @@ func NewGameComponents() *GameComponents {
 		PauseController: pauseController,
 		EventSystem:     eventSystem,
 		GameState:       gameState,
-		FallTimer:       fallTimer,
-	}
-
-	err := audioManager.Initialize()
-	if err != nil {
-		println("Warning: Could not initialize audio:", err.Error())
+		Stats:           NewGameStats(eventSystem),
+		Settings:        settings,
 	}
 
 	components.setupEventListeners()
@@ -69,26 +65,77 @@ func NewGameComponents() *GameComponents {
 }
 
 func (gc *GameComponents) setupEventListeners() {
-	gc.EventSystem.Subscribe(EventBlocksRemoved, func(event GameEvent) {
-		data := event.Data.(BlocksRemovedData)
+	Subscribe(gc.EventSystem, func(data ReactionFinishedEvent) {
 		gc.AudioManager.PlayBlockBreakMultiple(data.Count)
 		intensity := float64(data.Count) * 2.0
 		duration := 0.2 + float64(data.Count)*0.05
-		gc.ScreenShake.StartShake(intensity, duration)
-		for _, pos := range data.Positions {
-			gc.ParticleSystem.AddExplosion(pos.X, pos.Y, NeutralBlock)
+		gc.shake(intensity, duration)
+		if !gc.Settings.Particles {
+			return
+		}
+		for _, block := range data.Blocks {
+			gc.ParticleSystem.AddExplosion(block.Position.X, block.Position.Y, block.BlockType)
+		}
+	})
+
+	Subscribe(gc.EventSystem, func(data ReactionStartedEvent) {
+		popupX := float64(gc.Gameboard.X + gc.Gameboard.Width/2)
+		popupY := float64(gc.Gameboard.Y + gc.Gameboard.Height/3)
+		gc.ScorePopups.AddScorePopup(popupX, popupY, data.Points)
+
+		// Each callout floats up a line below the last.
+		var callouts []string
+		if data.Chain > 1 {
+			callouts = append(callouts, fmt.Sprintf("CHAIN x%d", data.Chain))
+		}
+		if data.Combo > 1 {
+			callouts = append(callouts, fmt.Sprintf("COMBO x%d", data.Combo))
+		}
+		if data.Rows > 1 {
+			callouts = append(callouts, fmt.Sprintf("%d ROWS", data.Rows))
+		}
+		if data.StormAssisted {
+			callouts = append(callouts, "STORM ASSIST")
+		}
+		for i, callout := range callouts {
+			gc.ScorePopups.AddLabelPopup(popupX, popupY+float64(i+1)*24, callout)
 		}
 	})
 
-	gc.EventSystem.Subscribe(EventPiecePlaced, func(event GameEvent) {
-		data := event.Data.(PiecePlacedData)
-		gc.ParticleSystem.AddDustCloud(data.Position.X, data.Position.Y)
+	Subscribe(gc.EventSystem, func(data PieceLockedEvent) {
+		gc.dust(data.Position)
 	})
 
-	gc.EventSystem.Subscribe(EventHardDrop, func(event GameEvent) {
-		data := event.Data.(HardDropData)
+	Subscribe(gc.EventSystem, func(data NeutralDroppedEvent) {
+		gc.dust(data.Position)
+	})
+
+	Subscribe(gc.EventSystem, func(data HardDropEvent) {
 		intensity := 1.0 + float64(data.DropHeight)*0.5
 		duration := 0.1
-		gc.ScreenShake.StartShake(intensity, duration)
+		gc.shake(intensity, duration)
+	})
+
+	Subscribe(gc.EventSystem, func(data GarbageRaisedEvent) {
+		gc.shake(1.5*float64(data.Rows), 0.15)
+	})
+
+	Subscribe(gc.EventSystem, func(PieceMovedEvent) {
+		gc.AudioManager.PlaySwooshSound()
 	})
 }
+
+// shake starts a screen shake scaled by the shake setting.
+func (gc *GameComponents) shake(intensity, duration float64) {
+	if gc.Settings.ShakeIntensity <= 0 {
+		return
+	}
+	gc.ScreenShake.StartShake(intensity*gc.Settings.ShakeIntensity, duration)
+}
+
+// dust puffs a dust cloud at position unless particles are turned off.
+func (gc *GameComponents) dust(position Position) {
+	if gc.Settings.Particles {
+		gc.ParticleSystem.AddDustCloud(position.X, position.Y)
+	}
+}
@@ -1,564 +1,259 @@
 package main
 
-import (
-	"fmt"
-	"math"
-)
-
-type ExplosionCallback func(worldX, worldY float64, blockType BlockType)
-type AudioCallback func(blocksRemoved int)
-type DustCallback func(worldX, worldY float64)
-type HardDropCallback func(dropHeight int)
+import "union/engine"
 
+// GameLogic drives an engine.Game on behalf of the scene and publishes
+// what happens on the event bus, with grid positions translated into
+// screen space.
 type GameLogic struct {
-	gameboard         *Gameboard
-	blockManager      *BlockManager
-	placedBlocks      []Block
-	explosionCallback ExplosionCallback
-	audioCallback     AudioCallback
-	dustCallback      DustCallback
-	hardDropCallback  HardDropCallback
-	activeStorms      map[int]*Storm
-}
-
-func NewGameLogic(gameboard *Gameboard, blockManager *BlockManager) *GameLogic {
-	return &GameLogic{
+	gameboard    *Gameboard
+	blockManager *BlockManager
+	game         *engine.Game
+	events       *EventSystem
+	wasOver      bool
+}
+
+func NewGameLogic(gameboard *Gameboard, blockManager *BlockManager, game *engine.Game, events *EventSystem) *GameLogic {
+	gl := &GameLogic{
 		gameboard:    gameboard,
 		blockManager: blockManager,
-		placedBlocks: make([]Block, 0),
-		activeStorms: make(map[int]*Storm),
+		game:         game,
+		events:       events,
 	}
+
+	gl.game.SetBlocksRemovedCallback(gl.onBlocksRemoved)
+	gl.game.SetPiecePlacedCallback(gl.onPiecePlaced)
+	gl.game.SetNeutralSpawnedCallback(gl.onNeutralSpawned)
+	gl.game.SetReactionStartedCallback(gl.onReactionStarted)
+	gl.game.SetStormIgnitedCallback(gl.onStormIgnited)
+	gl.game.SetPieceSpawnedCallback(gl.onPieceSpawned)
+	gl.game.SetPieceMovedCallback(gl.onPieceMoved)
+	gl.game.SetPieceRotatedCallback(gl.onPieceRotated)
+	gl.game.SetHardDropCallback(gl.onHardDrop)
+	gl.game.SetGarbageRaisedCallback(gl.onGarbageRaised)
+
+	return gl
 }
 
-func (gl *GameLogic) SetExplosionCallback(callback ExplosionCallback) {
-	gl.explosionCallback = callback
+func (gl *GameLogic) Board() *engine.Board {
+	return gl.game.Board
 }
 
-func (gl *GameLogic) SetAudioCallback(callback AudioCallback) {
-	gl.audioCallback = callback
+func (gl *GameLogic) CurrentPiece() *TetrisPiece {
+	return gl.game.Current
 }
 
-func (gl *GameLogic) SetDustCallback(callback DustCallback) {
-	gl.dustCallback = callback
+// Queue returns the upcoming pieces, next first.
+func (gl *GameLogic) Queue() []*TetrisPiece {
+	return gl.game.Queue
 }
 
-func (gl *GameLogic) SetHardDropCallback(callback HardDropCallback) {
-	gl.hardDropCallback = callback
+func (gl *GameLogic) HeldPiece() *TetrisPiece {
+	return gl.game.Hold
 }
 
-func (gl *GameLogic) IsValidPosition(piece *TetrisPiece, offsetX, offsetY int) bool {
-	blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-	gameboardWidthInBlocks := int(float64(gl.gameboard.Width) / blockSize)
-	gameboardHeightInBlocks := int(float64(gl.gameboard.Height) / blockSize)
-	for _, block := range piece.Blocks {
-		newX := piece.X + block.X + offsetX
-		newY := piece.Y + block.Y + offsetY
-		if newX < 0 || newX >= gameboardWidthInBlocks || newY >= gameboardHeightInBlocks {
-			return false
-		}
-		for _, placedBlock := range gl.placedBlocks {
-			if placedBlock.X == newX && placedBlock.Y == newY {
-				return false
-			}
-		}
-	}
-	return true
+func (gl *GameLogic) CanHold() bool {
+	return gl.game.CanHold()
 }
 
-func (gl *GameLogic) IsValidPositionIgnoreNeutral(piece *TetrisPiece, offsetX, offsetY int) bool {
-	blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-	gameboardWidthInBlocks := int(float64(gl.gameboard.Width) / blockSize)
-	gameboardHeightInBlocks := int(float64(gl.gameboard.Height) / blockSize)
-	for _, block := range piece.Blocks {
-		newX := piece.X + block.X + offsetX
-		newY := piece.Y + block.Y + offsetY
-		if newX < 0 || newX >= gameboardWidthInBlocks || newY >= gameboardHeightInBlocks {
-			return false
-		}
-		for _, placedBlock := range gl.placedBlocks {
-			if placedBlock.BlockType != NeutralBlock && placedBlock.X == newX && placedBlock.Y == newY {
-				return false
-			}
-		}
-	}
-	return true
+// IncomingGarbage returns the versus garbage waiting to rise into the
+// board.
+func (gl *GameLogic) IncomingGarbage() int {
+	return gl.game.IncomingGarbage()
 }
 
-func (gl *GameLogic) PlacePiece(piece *TetrisPiece) {
-	if piece == nil {
-		return
-	}
-	for _, block := range piece.Blocks {
-		placedBlock := Block{
-			X:         piece.X + block.X,
-			Y:         piece.Y + block.Y,
-			BlockType: block.BlockType,
-		}
-		gl.placedBlocks = append(gl.placedBlocks, placedBlock)
-	}
-	if gl.dustCallback != nil {
-		blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-		bottomY := piece.Y
-		for _, block := range piece.Blocks {
-			if piece.Y+block.Y > bottomY {
-				bottomY = piece.Y + block.Y
-			}
-		}
-		worldX := float64(gl.gameboard.X) + float64(piece.X)*blockSize + blockSize/2
-		worldY := float64(gl.gameboard.Y) + float64(bottomY+1)*blockSize
-		gl.dustCallback(worldX, worldY)
-	}
+func (gl *GameLogic) Seed() int64 {
+	return gl.game.RNG().Seed()
+}
+
+// Tick returns how many ticks the game has run.
+func (gl *GameLogic) Tick() int {
+	return gl.game.Tick()
+}
+
+func (gl *GameLogic) Score() int {
+	return gl.game.Score
+}
+
+func (gl *GameLogic) Level() int {
+	return gl.game.Level()
+}
+
+func (gl *GameLogic) BlocksCleared() int {
+	return gl.game.BlocksCleared()
+}
+
+// Pieces returns how many pieces have been placed.
+func (gl *GameLogic) Pieces() int {
+	return gl.game.Pieces()
+}
+
+func (gl *GameLogic) Scoring() engine.ScoringState {
+	return gl.game.Scoring()
+}
+
+func (gl *GameLogic) IsGameOver() bool {
+	return gl.game.IsOver()
+}
+
+func (gl *GameLogic) EndCause() engine.EndCause {
+	return gl.game.EndCause()
+}
+
+func (gl *GameLogic) Config() engine.Config {
+	return gl.game.Config()
+}
+
+func (gl *GameLogic) Mode() engine.GameMode {
+	return gl.game.Mode()
+}
+
+// ModeStatus returns the mode's heading and progress for the HUD.
+func (gl *GameLogic) ModeStatus() (label, value string) {
+	return gl.game.Mode().Status(gl.game)
 }
 
 func (gl *GameLogic) GetPlacedBlocks() []Block {
-	return gl.placedBlocks
+	return gl.game.Board.GetPlacedBlocks()
 }
 
-func (gl *GameLogic) SpawnNewPiece(pieceType PieceType) *TetrisPiece {
-	blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-	gameboardWidthInBlocks := int(float64(gl.gameboard.Width) / blockSize)
-	centerX := gameboardWidthInBlocks / 2
-	return gl.blockManager.CreateTetrisPiece(pieceType, centerX, 0)
+func (gl *GameLogic) GetStormWarnings() []engine.StormWarning {
+	return gl.game.Board.GetStormWarnings()
 }
 
-func (gl *GameLogic) TryRotatePiece(piece *TetrisPiece, pieceType PieceType) bool {
-	if piece == nil {
-		return false
-	}
-	originalRotation := piece.Rotation
-	originalBlocks := make([]Block, len(piece.Blocks))
-	copy(originalBlocks, piece.Blocks)
-	gl.blockManager.RotatePiece(piece, pieceType)
-	if !gl.IsValidPosition(piece, 0, 0) {
-		piece.Rotation = originalRotation
-		piece.Blocks = originalBlocks
-		return false
-	}
-	return true
+func (gl *GameLogic) TryRotatePiece(piece *TetrisPiece, turn engine.Turn) bool {
+	return gl.game.Board.TryRotatePiece(piece, turn)
 }
 
 func (gl *GameLogic) TryMovePiece(piece *TetrisPiece, deltaX, deltaY int) bool {
-	if piece == nil {
-		return false
-	}
-	if gl.IsValidPosition(piece, deltaX, deltaY) {
-		piece.X += deltaX
-		piece.Y += deltaY
-		return true
-	}
-	return false
+	return gl.game.Board.TryMovePiece(piece, deltaX, deltaY)
 }
 
 func (gl *GameLogic) CalculateDropPosition(piece *TetrisPiece) *TetrisPiece {
-	if piece == nil {
-		return nil
-	}
-	shadowPiece := &TetrisPiece{
-		X:        piece.X,
-		Y:        piece.Y,
-		Rotation: piece.Rotation,
-		Blocks:   make([]Block, len(piece.Blocks)),
-	}
-	for i, block := range piece.Blocks {
-		shadowPiece.Blocks[i] = Block{
-			X:         block.X,
-			Y:         block.Y,
-			BlockType: block.BlockType,
-		}
-	}
-	for gl.IsValidPosition(shadowPiece, 0, 1) {
-		shadowPiece.Y++
-	}
-	return shadowPiece
+	return gl.game.Board.CalculateDropPosition(piece)
 }
 
-func (gl *GameLogic) IsGameOver() bool {
-	for _, block := range gl.placedBlocks {
-		if block.Y <= 0 && block.BlockType != NeutralBlock {
-			return true
-		}
-	}
-	return false
+// View returns a read-only copy of the game for controllers.
+func (gl *GameLogic) View() *engine.View {
+	return gl.game.View()
 }
 
-func (gl *GameLogic) CheckAndProcessReactions() int {
-	totalScore := 0
-	for {
-		blocksToRemove := gl.findBlocksToRemove()
-		if len(blocksToRemove) == 0 {
-			break
-		}
-		reactionScore := gl.calculateReactionScore(len(blocksToRemove))
-		totalScore += reactionScore
-		gl.removeBlocks(blocksToRemove)
-		gl.processBlockFalling()
-	}
-	return totalScore
+func (gl *GameLogic) Snapshot() (*engine.SaveState, error) {
+	return gl.game.Snapshot()
 }
 
-func (gl *GameLogic) calculateReactionScore(blocksRemoved int) int {
-	if blocksRemoved < 4 {
-		return 0
-	}
-	score := 10
-	for i := 4; i < blocksRemoved; i++ {
-		score *= 2
-	}
-	return score
+// Step advances the game by one simulation tick, then announces any change
+// in score and the end of the game. Events raised during the tick are held
+// until the engine has finished with the board and delivered together.
+func (gl *GameLogic) Step(input engine.Input) {
+	before := gl.game.Score
+	gl.game.Step(input)
+	gl.announce(before)
 }
 
-func (gl *GameLogic) findBlocksToRemove() []Block {
-	var blocksToRemove []Block
-	rowMap := make(map[int][]Block)
-	for _, block := range gl.placedBlocks {
-		rowMap[block.Y] = append(rowMap[block.Y], block)
-	}
-	for _, rowBlocks := range rowMap {
-		if len(rowBlocks) < 3 {
-			continue
-		}
-		for i := 0; i < len(rowBlocks); i++ {
-			for j := i + 1; j < len(rowBlocks); j++ {
-				if rowBlocks[i].X > rowBlocks[j].X {
-					rowBlocks[i], rowBlocks[j] = rowBlocks[j], rowBlocks[i]
-				}
-			}
-		}
-		clusters := gl.findClustersInRow(rowBlocks)
-		for _, cluster := range clusters {
-			if len(cluster) >= 3 {
-				zeroSumBlocks := gl.findZeroSumSubsequence(cluster)
-				blocksToRemove = append(blocksToRemove, zeroSumBlocks...)
-			}
-		}
+// announce publishes what changed since the score was before, then delivers
+// every event held.
+func (gl *GameLogic) announce(before int) {
+	if gl.game.Score != before {
+		gl.events.Publish(ScoreChangedEvent{
+			Score: gl.game.Score,
+			Delta: gl.game.Score - before,
+		})
 	}
-	return blocksToRemove
-}
-
-func (gl *GameLogic) findClustersInRow(rowBlocks []Block) [][]Block {
-	var clusters [][]Block
-	var currentCluster []Block
-	for i, block := range rowBlocks {
-		if block.BlockType == NeutralBlock {
-			if len(currentCluster) >= 3 {
-				clusters = append(clusters, currentCluster)
-			}
-			currentCluster = nil
-		} else if i > 0 && block.X != rowBlocks[i-1].X+1 {
-			if len(currentCluster) >= 3 {
-				clusters = append(clusters, currentCluster)
-			}
-			currentCluster = []Block{block}
-		} else {
-			currentCluster = append(currentCluster, block)
-		}
-	}
-	if len(currentCluster) >= 3 {
-		clusters = append(clusters, currentCluster)
-	}
-	return clusters
-}
-
-func (gl *GameLogic) findZeroSumSubsequence(cluster []Block) []Block {
-	for length := len(cluster); length >= 3; length-- {
-		for start := 0; start <= len(cluster)-length; start++ {
-			subsequence := cluster[start : start+length]
-			sum := 0
-			for _, block := range subsequence {
-				switch block.BlockType {
-				case PositiveBlock:
-					sum += 1
-				case NegativeBlock:
-					sum -= 1
-				}
-			}
-			if sum == 0 {
-				return subsequence
-			}
-		}
+	if gl.game.IsOver() && !gl.wasOver {
+		gl.wasOver = true
+		gl.events.Publish(GameOverEvent{
+			Score: gl.game.Score,
+			Level: gl.game.Level(),
+		})
 	}
-	return nil
+	gl.events.Flush()
 }
 
-func (gl *GameLogic) removeBlocks(blocksToRemove []Block) {
-	if len(blocksToRemove) == 0 {
-		return
-	}
-	if gl.audioCallback != nil {
-		gl.audioCallback(len(blocksToRemove))
-	}
-	removeMap := make(map[string]bool)
-	for _, block := range blocksToRemove {
-		key := fmt.Sprintf("%d,%d", block.X, block.Y)
-		removeMap[key] = true
-	}
-	var remainingBlocks []Block
-	for _, block := range gl.placedBlocks {
-		key := fmt.Sprintf("%d,%d", block.X, block.Y)
-		if !removeMap[key] {
-			remainingBlocks = append(remainingBlocks, block)
-		} else {
-			if gl.explosionCallback != nil {
-				blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-				worldX := float64(gl.gameboard.X) + float64(block.X)*blockSize + blockSize/2
-				worldY := float64(gl.gameboard.Y) + float64(block.Y)*blockSize + blockSize/2
-				gl.explosionCallback(worldX, worldY, block.BlockType)
-			}
-		}
-	}
-	gl.placedBlocks = remainingBlocks
+func (gl *GameLogic) GetBlockRenderTransform(block *Block, alpha float64) (float64, float64, float64, float64) {
+	return gl.game.Board.GetBlockRenderTransform(block, alpha)
 }
 
-func (gl *GameLogic) processBlockFalling() {
-	for i := 0; i < len(gl.placedBlocks); i++ {
-		for j := i + 1; j < len(gl.placedBlocks); j++ {
-			if gl.placedBlocks[i].Y < gl.placedBlocks[j].Y {
-				gl.placedBlocks[i], gl.placedBlocks[j] = gl.placedBlocks[j], gl.placedBlocks[i]
-			}
-		}
-	}
-	for i := range gl.placedBlocks {
-		block := &gl.placedBlocks[i]
-		if !block.IsFalling {
-			gl.StartBlockFall(block)
-		}
-	}
+// gridToWorld returns the screen position of the centre of a grid cell.
+func (gl *GameLogic) gridToWorld(x, y int) (float64, float64) {
+	blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
+	worldX := float64(gl.gameboard.X) + float64(x)*blockSize + blockSize/2
+	worldY := float64(gl.gameboard.Y) + float64(y)*blockSize + blockSize/2
+	return worldX, worldY
 }
 
-func (gl *GameLogic) UpdateWobblingBlocks(deltaTime float64) bool {
-	anyBlocksFinished := false
-	for i := range gl.placedBlocks {
-		block := &gl.placedBlocks[i]
-		if block.IsWobbling {
-			block.WobbleTime += deltaTime
-			block.WobblePhase += deltaTime * WobbleFrequency * 2 * math.Pi
-			if block.WobbleTime >= WobbleDuration {
-				block.ShowPowSprite = false
-				anyBlocksFinished = true
-			}
-		}
-	}
-	return anyBlocksFinished
-}
-
-func (gl *GameLogic) RemoveFinishedWobblingBlocks() int {
-	var blocksToRemove []Block
-	var remainingBlocks []Block
-	for _, block := range gl.placedBlocks {
-		if block.IsWobbling && block.WobbleTime >= WobbleDuration {
-			block.ShowPowSprite = false
-			blocksToRemove = append(blocksToRemove, block)
-		} else {
-			remainingBlocks = append(remainingBlocks, block)
-		}
+func (gl *GameLogic) onBlocksRemoved(blocks []Block) {
+	removed := make([]RemovedBlock, len(blocks))
+	for i, block := range blocks {
+		worldX, worldY := gl.gridToWorld(block.X, block.Y)
+		removed[i] = RemovedBlock{Position: Position{X: worldX, Y: worldY}, BlockType: block.BlockType}
 	}
-	if len(blocksToRemove) == 0 {
-		return 0
-	}
-	if gl.audioCallback != nil {
-		gl.audioCallback(len(blocksToRemove))
-	}
-	for _, block := range blocksToRemove {
-		if gl.explosionCallback != nil {
-			blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-			worldX := float64(gl.gameboard.X) + float64(block.X)*blockSize + blockSize/2
-			worldY := float64(gl.gameboard.Y) + float64(block.Y)*blockSize + blockSize/2
-			gl.explosionCallback(worldX, worldY, block.BlockType)
-		}
-	}
-	gl.placedBlocks = remainingBlocks
-	return len(blocksToRemove)
+	gl.events.Publish(ReactionFinishedEvent{
+		Count:  len(blocks),
+		Blocks: removed,
+	})
 }
 
-func (gl *GameLogic) StartBlockWobbling(blocksToWobble []Block) {
-	if len(blocksToWobble) == 0 {
-		return
+func (gl *GameLogic) onReactionStarted(blocks []Block, reaction engine.Reaction) {
+	positions := make([]Position, len(blocks))
+	for i, block := range blocks {
+		worldX, worldY := gl.gridToWorld(block.X, block.Y)
+		positions[i] = Position{X: worldX, Y: worldY}
 	}
-	wobbleMap := make(map[string]bool)
-	for _, block := range blocksToWobble {
-		key := fmt.Sprintf("%d,%d", block.X, block.Y)
-		wobbleMap[key] = true
-	}
-	for i := range gl.placedBlocks {
-		block := &gl.placedBlocks[i]
-		key := fmt.Sprintf("%d,%d", block.X, block.Y)
-		if wobbleMap[key] && !block.IsWobbling {
-			block.IsWobbling = true
-			block.WobbleTime = 0
-			block.WobblePhase = 0
-			block.ShowPowSprite = true
+	gl.events.Publish(ReactionStartedEvent{
+		Count:         len(blocks),
+		Points:        reaction.Points,
+		Rows:          reaction.Rows,
+		Chain:         reaction.Chain,
+		Combo:         reaction.Combo,
+		StormAssisted: reaction.StormAssisted,
+		Positions:     positions,
+	})
+}
+
+func (gl *GameLogic) onPiecePlaced(piece *TetrisPiece) {
+	blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
+	bottomY := piece.Y
+	for _, block := range piece.Blocks {
+		if piece.Y+block.Y > bottomY {
+			bottomY = piece.Y + block.Y
 		}
 	}
+	worldX := float64(gl.gameboard.X) + float64(piece.X)*blockSize + blockSize/2
+	worldY := float64(gl.gameboard.Y) + float64(bottomY+1)*blockSize
+	gl.events.Publish(PieceLockedEvent{
+		Piece:    *piece.Copy(),
+		Position: Position{X: worldX, Y: worldY},
+	})
 }
 
-func (gl *GameLogic) CheckForNewReactions() int {
-	blocksToWobble := gl.findNonWobblingBlocksToRemove()
-	if len(blocksToWobble) == 0 {
-		return 0
-	}
-	gl.StartBlockWobbling(blocksToWobble)
-	return gl.calculateReactionScore(len(blocksToWobble))
+func (gl *GameLogic) onNeutralSpawned(block Block) {
+	worldX, worldY := gl.gridToWorld(block.X, block.Y)
+	gl.events.Publish(NeutralDroppedEvent{
+		Position: Position{X: worldX, Y: worldY},
+	})
 }
 
-func (gl *GameLogic) findNonWobblingBlocksToRemove() []Block {
-	var blocksToRemove []Block
-	rowMap := make(map[int][]Block)
-	for _, block := range gl.placedBlocks {
-		if !block.IsWobbling {
-			rowMap[block.Y] = append(rowMap[block.Y], block)
-		}
-	}
-	for _, rowBlocks := range rowMap {
-		if len(rowBlocks) < 3 {
-			continue
-		}
-		for i := 0; i < len(rowBlocks); i++ {
-			for j := i + 1; j < len(rowBlocks); j++ {
-				if rowBlocks[i].X > rowBlocks[j].X {
-					rowBlocks[i], rowBlocks[j] = rowBlocks[j], rowBlocks[i]
-				}
-			}
-		}
-		clusters := gl.findClustersInRow(rowBlocks)
-		for _, cluster := range clusters {
-			if len(cluster) >= 3 {
-				zeroSumBlocks := gl.findZeroSumSubsequence(cluster)
-				blocksToRemove = append(blocksToRemove, zeroSumBlocks...)
-			}
-		}
-	}
-	return blocksToRemove
-}
-
-// All storm-related methods have been removed from this file. See storm.go for their implementations.
-
-func (gl *GameLogic) UpdateFallingBlocks(deltaTime float64) bool {
-	anyBlocksLanded := false
-	for i := range gl.placedBlocks {
-		block := &gl.placedBlocks[i]
-		if block.IsFalling {
-			fallDistance := block.FallTargetY - block.FallStartY
-			if fallDistance > 0 {
-				block.FallProgress += deltaTime * FallSpeed / fallDistance
-				if block.FallProgress >= 1.0 {
-					block.FallProgress = 1.0
-					block.Y = int(block.FallTargetY)
-					block.IsFalling = false
-					anyBlocksLanded = true
-				}
-			} else {
-				block.IsFalling = false
-				anyBlocksLanded = true
-			}
-		}
-	}
-	return anyBlocksLanded
+func (gl *GameLogic) onStormIgnited(column int) {
+	gl.events.Publish(StormIgnitedEvent{Column: column})
 }
 
-func (gl *GameLogic) GetBlockRenderPosition(block *Block) (float64, float64) {
-	if block.IsArcing {
-		x, y, _, _ := gl.GetBlockArcPosition(block)
-		return x, y
-	} else if block.IsFalling {
-		currentY := block.FallStartY + (block.FallTargetY-block.FallStartY)*block.FallProgress
-		return float64(block.X), currentY
-	}
-	return float64(block.X), float64(block.Y)
+func (gl *GameLogic) onPieceSpawned(piece *TetrisPiece) {
+	gl.events.Publish(PieceSpawnedEvent{Piece: *piece.Copy()})
 }
 
-func (gl *GameLogic) GetBlockRenderTransform(block *Block) (float64, float64, float64, float64) {
-	if block.IsArcing {
-		return gl.GetBlockArcPosition(block)
-	} else if block.IsFalling {
-		currentY := block.FallStartY + (block.FallTargetY-block.FallStartY)*block.FallProgress
-		return float64(block.X), currentY, 0.0, 1.0
-	}
-	return float64(block.X), float64(block.Y), 0.0, 1.0
+func (gl *GameLogic) onPieceMoved(piece *TetrisPiece) {
+	gl.events.Publish(PieceMovedEvent{Piece: *piece.Copy()})
 }
 
-func (gl *GameLogic) StartBlockArc(block *Block, startX, startY, targetX, targetY float64) {
-	block.IsArcing = true
-	block.ArcStartX = startX
-	block.ArcStartY = startY
-	block.ArcTargetX = targetX
-	block.ArcTargetY = targetY
-	block.ArcProgress = 0.0
-	block.ArcRotation = 0.0
-	block.ArcScale = MinArcScale
+func (gl *GameLogic) onPieceRotated(piece *TetrisPiece) {
+	gl.events.Publish(PieceRotatedEvent{Piece: *piece.Copy()})
 }
 
-func (gl *GameLogic) GetBlockArcPosition(block *Block) (float64, float64, float64, float64) {
-	if !block.IsArcing {
-		return float64(block.X), float64(block.Y), 0.0, 1.0
-	}
-	t := block.ArcProgress
-	currentX := block.ArcStartX + (block.ArcTargetX-block.ArcStartX)*t
-	linearY := block.ArcStartY + (block.ArcTargetY-block.ArcStartY)*t
-	arcOffset := ArcHeight * 4 * t * (1 - t)
-	currentY := linearY - arcOffset
-	currentRotation := block.ArcRotation + MaxRotation*t
-	currentScale := MinArcScale + (1.0-MinArcScale)*t
-	return currentX, currentY, currentRotation, currentScale
-}
-
-func (gl *GameLogic) UpdateArcingBlocks(deltaTime float64) bool {
-	anyBlocksFinishedArcing := false
-	for i := range gl.placedBlocks {
-		block := &gl.placedBlocks[i]
-		if block.IsArcing {
-			block.ArcProgress += deltaTime * ArcSpeed
-			if block.ArcProgress >= 1.0 {
-				block.ArcProgress = 1.0
-				blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-				gameboardHeightInBlocks := int(float64(gl.gameboard.Height) / blockSize)
-				targetColumn := int(block.ArcTargetX)
-				finalY := gameboardHeightInBlocks - 1
-				for _, placedBlock := range gl.placedBlocks {
-					if &placedBlock != block && placedBlock.X == targetColumn && placedBlock.Y < finalY {
-						finalY = placedBlock.Y - 1
-					}
-				}
-				if finalY < 0 {
-					finalY = 0
-				}
-				block.X = targetColumn
-				block.Y = finalY
-				block.IsArcing = false
-				block.ArcScale = 1.0
-				block.ArcRotation = 0.0
-				anyBlocksFinishedArcing = true
-			}
-		}
-	}
-	return anyBlocksFinishedArcing
+func (gl *GameLogic) onHardDrop(dropHeight int) {
+	gl.events.Publish(HardDropEvent{DropHeight: dropHeight})
 }
 
-func (gl *GameLogic) StartBlockFall(block *Block) {
-	blockSize := gl.blockManager.GetScaledBlockSize(gl.gameboard.Width, gl.gameboard.Height)
-	gameboardHeightInBlocks := int(float64(gl.gameboard.Height) / blockSize)
-	targetY := block.Y
-	for newY := block.Y + 1; newY < gameboardHeightInBlocks; newY++ {
-		occupied := false
-		for _, placedBlock := range gl.placedBlocks {
-			if &placedBlock == block {
-				continue
-			}
-			checkY := placedBlock.Y
-			if placedBlock.IsFalling {
-				checkY = int(placedBlock.FallTargetY)
-			}
-			if placedBlock.X == block.X && checkY == newY {
-				occupied = true
-				break
-			}
-		}
-		if occupied {
-			break
-		}
-		targetY = newY
-	}
-	block.IsFalling = true
-	block.FallStartY = float64(block.Y)
-	block.FallTargetY = float64(targetY)
-	block.FallProgress = 0
+func (gl *GameLogic) onGarbageRaised(rows int) {
+	gl.events.Publish(GarbageRaisedEvent{Rows: rows})
 }
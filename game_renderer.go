@@ -115,6 +115,64 @@ func (gr *GameRenderer) RenderScore(screen *ebiten.Image, currentScore int) {
 	text.Draw(screen, scoreText, gr.scoreFont, gr.scoreOp)
 }
 
+// RenderStatus draws the mode's progress beside the score: the level in a
+// classic game, or what a timed or goal-driven mode has left.
+func (gr *GameRenderer) RenderStatus(screen *ebiten.Image, label, value string) {
+	margin := 10
+	statusX := gr.gameboard.X + gr.gameboard.Width + 180
+	statusY := max(margin, gr.gameboard.Y-15)
+	gr.labelOp.GeoM.Reset()
+	gr.labelOp.GeoM.Translate(float64(statusX), float64(statusY))
+	gr.labelOp.ColorScale.Reset()
+	gr.labelOp.ColorScale.ScaleWithColor(color.RGBA{200, 200, 255, 255})
+	text.Draw(screen, label, gr.scoreLabelFont, gr.labelOp)
+	gr.scoreOp.GeoM.Reset()
+	gr.scoreOp.GeoM.Translate(float64(statusX), float64(statusY+25))
+	gr.scoreOp.ColorScale.Reset()
+	gr.scoreOp.ColorScale.ScaleWithColor(color.RGBA{100, 255, 180, 255})
+	text.Draw(screen, value, gr.scoreFont, gr.scoreOp)
+}
+
+// RenderChainAndCombo draws the running chain and combo under the hold
+// slot.
+func (gr *GameRenderer) RenderChainAndCombo(screen *ebiten.Image, chain, combo int) {
+	blockSize := gr.blockManager.GetScaledBlockSize(gr.gameboard.Width, gr.gameboard.Height)
+	previewBlockSize := blockSize * 0.6
+
+	x := float64(gr.gameboard.X) - 20 - previewBlockSize*4
+	y := float64(gr.gameboard.Y+100) + previewBlockSize*3 + 20
+	if x < 0 {
+		return
+	}
+
+	gr.RenderLabel(screen, fmt.Sprintf("CHAIN %d", chain), x, y)
+	gr.RenderLabel(screen, fmt.Sprintf("COMBO %d", combo), x, y+25)
+}
+
+// RenderLevelLabel draws the level as a line under the chain and combo,
+// for layouts with no room beside the score.
+func (gr *GameRenderer) RenderLevelLabel(screen *ebiten.Image, level int) {
+	blockSize := gr.blockManager.GetScaledBlockSize(gr.gameboard.Width, gr.gameboard.Height)
+	previewBlockSize := blockSize * 0.6
+
+	x := float64(gr.gameboard.X) - 20 - previewBlockSize*4
+	y := float64(gr.gameboard.Y+100) + previewBlockSize*3 + 70
+	if x < 0 {
+		return
+	}
+
+	gr.RenderLabel(screen, fmt.Sprintf("LEVEL %d", level), x, y)
+}
+
+// RenderLabel draws a small heading such as the one over the score.
+func (gr *GameRenderer) RenderLabel(screen *ebiten.Image, label string, x, y float64) {
+	gr.labelOp.GeoM.Reset()
+	gr.labelOp.GeoM.Translate(x, y)
+	gr.labelOp.ColorScale.Reset()
+	gr.labelOp.ColorScale.ScaleWithColor(color.RGBA{200, 200, 255, 255})
+	text.Draw(screen, label, gr.scoreLabelFont, gr.labelOp)
+}
+
 func (gr *GameRenderer) RenderDropShadow(screen *ebiten.Image, shadowPiece *TetrisPiece) {
 	if shadowPiece == nil {
 		return
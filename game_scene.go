@@ -2,51 +2,70 @@ package main
 
 import (
 	"image/color"
-	"math/rand"
+	"log"
 	"time"
+	"union/engine"
 
-	stopwatch "github.com/RAshkettle/Stopwatch"
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 const (
 	GameboardWidth  = 192
 	GameboardHeight = 320
-	FallInterval    = 1
 )
 
+// Attract mode: the bot's pace, and how long a demo runs before going back
+// to the title.
+const (
+	demoBotPace     = 6
+	demoLengthTicks = 60 * engine.TicksPerSecond
+)
+
+// puzzleSeed seeds every puzzle game, so the storms a solution sets off
+// throw their blocks the same way each time.
+const puzzleSeed = 1
+
 type GameScene struct {
-	sceneManager    *SceneManager
-	gameboard       *Gameboard
-	blockManager    *BlockManager
-	gameLogic       *GameLogic
-	inputHandler    *InputHandler
-	renderer        *GameRenderer
-	particleSystem  *ParticleSystem
+	*PlayField
+	sceneManager *SceneManager
+	// controller decides each tick's input: the player's InputHandler, or
+	// a bot in attract mode.
+	controller      engine.Controller
 	audioManager    *AudioManager
-	screenShake     *ScreenShake
-	scorePopups     *ScorePopupSystem
 	pauseController *PauseController
-	gameState       *GameState
-	currentPiece    *TetrisPiece
-	currentType     PieceType
-	nextPiece       *TetrisPiece
-	nextType        PieceType
-	fallTimer       *stopwatch.Stopwatch
-	CurrentScore    int
 	lastUpdateTime  time.Time
 
-	tempImage     *ebiten.Image
-	particleImage *ebiten.Image
-	blocksImage   *ebiten.Image
-
-	shakeOp    *ebiten.DrawImageOptions
-	particleOp *ebiten.DrawImageOptions
-	blocksOp   *ebiten.DrawImageOptions
+	// playback drives the game in place of the keyboard when set; otherwise
+	// every tick of input is kept in recording. Resumed games have neither,
+	// as their opening ticks were never seen.
+	playback     *engine.Replay
+	playbackTick int
+	recording    *engine.Replay
+
+	// demo marks an attract-mode game, which any input ends and which is
+	// neither saved nor scored.
+	demo bool
+	// trial marks a puzzle being tried out from the editor, whose solves
+	// don't count as progress. Other puzzles record theirs under
+	// progressKey.
+	trial       bool
+	progressKey string
 }
 
 func (g *GameScene) Update() error {
-	g.pauseController.Update()
+	if g.demo && (g.demoInterrupted() || g.gameLogic.Tick() >= demoLengthTicks) {
+		g.sceneManager.TransitionTo(SceneTitleScreen)
+		return nil
+	}
+
+	input, ok := g.nextInput()
+	if !ok {
+		g.endGame()
+		return nil
+	}
+
+	g.pauseController.Update(input.Pause)
 
 	now := time.Now()
 	if g.lastUpdateTime.IsZero() {
@@ -57,173 +76,134 @@ func (g *GameScene) Update() error {
 
 	g.updateVisualEffects(dt)
 
-	if g.gameState.IsPaused {
+	// A resignation is stepped even while paused, as that is the only time
+	// it is offered.
+	if g.gameState.IsPaused && !input.Resign {
 		return nil
 	}
 
-	g.fallTimer.Update()
-
-	g.updateWobblingBlocks(dt)
-
-	shouldPlacePiece := g.inputHandler.HandleInput(g.currentPiece, g.currentType)
-
-	if shouldPlacePiece && g.currentPiece != nil {
-		g.placePieceAndCheckReactions()
-		return nil
-	}
-
-	if g.fallTimer.IsDone() {
-		if g.currentPiece != nil {
-			if g.gameLogic.TryMovePiece(g.currentPiece, 0, 1) {
-			} else {
-				g.placePieceAndCheckReactions()
-				return nil
-			}
-		}
-		g.fallTimer.Reset()
-		g.fallTimer.Start()
-	}
+	g.step(input, now)
 
 	return nil
 }
 
-func (g *GameScene) Draw(screen *ebiten.Image) {
-	shakeX, shakeY := g.screenShake.GetOffset()
-
-	screenW, screenH := screen.Bounds().Dx(), screen.Bounds().Dy()
-	if g.tempImage == nil || g.tempImage.Bounds().Dx() != screenW || g.tempImage.Bounds().Dy() != screenH {
-		g.tempImage = ebiten.NewImage(screenW, screenH)
-		g.particleImage = ebiten.NewImage(screenW, screenH)
-	}
-
-	g.tempImage.Clear()
-
-	var shadowPiece *TetrisPiece
-	if g.currentPiece != nil {
-		shadowPiece = g.gameLogic.CalculateDropPosition(g.currentPiece)
-	}
-
-	g.renderGameWithShadow(g.tempImage, shadowPiece)
-	g.renderer.RenderScore(g.tempImage, g.CurrentScore)
-	g.renderNextPiecePreview(g.tempImage)
-
-	if g.scorePopups != nil {
-		g.scorePopups.Draw(g.tempImage)
+// nextInput returns this tick's input, from the replay being played back or
+// from the controller, and records it. It reports false once a replay has run
+// out of input.
+func (g *GameScene) nextInput() (engine.Input, bool) {
+	if g.playback != nil {
+		if g.playbackTick >= len(g.playback.Inputs) {
+			return engine.Input{}, false
+		}
+		input := g.playback.Inputs[g.playbackTick]
+		g.playbackTick++
+		return input, true
 	}
 
-	g.shakeOp.GeoM.Reset()
-	g.shakeOp.GeoM.Translate(shakeX, shakeY)
-	screen.DrawImage(g.tempImage, g.shakeOp)
-
-	if g.particleSystem != nil {
-		g.particleOp.GeoM.Reset()
-		g.particleOp.GeoM.Translate(shakeX, shakeY)
-
-		g.particleImage.Clear()
-		g.particleSystem.Draw(g.particleImage)
-		screen.DrawImage(g.particleImage, g.particleOp)
+	input := g.controller.Input(g.gameLogic.View())
+	// Resigning is only offered from the pause screen. Zen games end no
+	// other way.
+	input.Resign = input.Resign && g.gameState.IsPaused
+	if g.recording != nil {
+		g.recording.Record(input)
 	}
-
-	g.pauseController.Draw(screen)
+	return input, true
 }
 
-func (g *GameScene) renderGameWithShadow(screen *ebiten.Image, shadowPiece *TetrisPiece) {
-	screen.Fill(color.RGBA{15, 20, 30, 255})
-
-	g.gameboard.Draw(screen)
-
-	blockSize := g.blockManager.GetScaledBlockSize(g.gameboard.Width, g.gameboard.Height)
+// demoInterrupted reports whether the player has pressed anything to end
+// a demo.
+func (g *GameScene) demoInterrupted() bool {
+	return len(inpututil.AppendJustPressedKeys(nil)) > 0 ||
+		inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) ||
+		inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) ||
+		g.sceneManager.settings.Controls.AnyJustPressed()
+}
 
-	if g.blocksImage == nil || g.blocksImage.Bounds().Dx() != g.gameboard.Width || g.blocksImage.Bounds().Dy() != g.gameboard.Height {
-		g.blocksImage = ebiten.NewImage(g.gameboard.Width, g.gameboard.Height)
+func (g *GameScene) endGame() {
+	if g.demo {
+		g.sceneManager.TransitionTo(SceneTitleScreen)
+		return
 	}
-
-	g.blocksImage.Clear()
-
-	for _, block := range g.gameLogic.GetPlacedBlocks() {
-		renderX, renderY, rotation, scale := g.gameLogic.GetBlockRenderTransform(&block)
-		worldX := renderX * blockSize
-		worldY := renderY * blockSize
-
-		if block.IsArcing || rotation != 0.0 || scale != 1.0 {
-			g.blockManager.DrawBlockTransformed(g.blocksImage, block, worldX, worldY, rotation, scale, blockSize)
-		} else {
-			g.blockManager.DrawBlock(g.blocksImage, block, worldX, worldY, blockSize)
-		}
+	if g.recording != nil {
+		g.sceneManager.saveRecording(g.recording)
 	}
-
-	if shadowPiece != nil && g.currentPiece != nil && shadowPiece.Y > g.currentPiece.Y {
-		for _, block := range shadowPiece.Blocks {
-			worldX := float64(shadowPiece.X+block.X) * blockSize
-			worldY := float64(shadowPiece.Y+block.Y) * blockSize
-			g.blockManager.DrawShadowBlock(g.blocksImage, block, worldX, worldY, blockSize)
+	puzzle := g.gameLogic.Config().Puzzle
+	if g.playback == nil && puzzle == nil {
+		if err := deleteSavedGame(); err != nil {
+			log.Printf("Warning: Could not remove saved game: %v", err)
 		}
 	}
-	if g.currentPiece != nil {
-		for _, block := range g.currentPiece.Blocks {
-			worldX := float64(g.currentPiece.X+block.X) * blockSize
-			worldY := float64(g.currentPiece.Y+block.Y) * blockSize
-			g.blockManager.DrawBlock(g.blocksImage, block, worldX, worldY, blockSize)
-		}
+	scoring := g.gameLogic.Scoring()
+	result := GameResult{
+		Entry: engine.ScoreEntry{
+			Score:     g.gameLogic.Score(),
+			Level:     g.gameLogic.Level(),
+			Ticks:     g.gameLogic.Tick(),
+			BestChain: scoring.MaxChain,
+			BestCombo: scoring.MaxCombo,
+			Seed:      g.gameLogic.Seed(),
+		},
+		Config:      g.gameLogic.Config(),
+		End:         g.gameLogic.EndCause(),
+		Cleared:     g.gameLogic.BlocksCleared(),
+		Pieces:      g.gameLogic.Pieces(),
+		Trial:       g.trial,
+		Stats:       *g.stats,
+		ProgressKey: g.progressKey,
 	}
-
-	warnings := g.gameLogic.GetStormWarnings()
-	for _, warning := range warnings {
-		worldX := float64(warning.Column) * blockSize
-		worldY := float64(warning.HighestBlockY) * blockSize
-		g.blockManager.DrawWarningSprite(g.blocksImage, worldX, worldY, warning.WarningTime, blockSize, warning.Column, g.gameboard.Width)
+	if g.playback == nil && !g.trial && result.End == engine.Solved {
+		result.Best = recordSolve(g.progressKey, result.Pieces)
 	}
-
-	g.blocksOp.GeoM.Reset()
-	g.blocksOp.GeoM.Translate(float64(g.gameboard.X), float64(g.gameboard.Y))
-	screen.DrawImage(g.blocksImage, g.blocksOp)
+	ranked := g.playback == nil && g.gameLogic.Mode().Ranked(result.End)
+	g.sceneManager.TransitionToEndScreen(result, ranked)
 }
 
-func (g *GameScene) renderNextPiecePreview(screen *ebiten.Image) {
-	if g.nextPiece == nil {
-		return
+// recordSolve notes a solved puzzle in the progress file under key,
+// reporting whether it took fewer moves than ever before.
+func recordSolve(key string, moves int) bool {
+	progress, err := loadPuzzleProgress()
+	if err != nil {
+		log.Printf("Warning: Could not load puzzle progress: %v", err)
+		return false
 	}
-
-	screenWidth, _ := screen.Bounds().Dx(), screen.Bounds().Dy()
-
-	previewX := float64(g.gameboard.X + g.gameboard.Width + 20)
-	previewY := float64(g.gameboard.Y + 100)
-
-	blockSize := g.blockManager.GetScaledBlockSize(g.gameboard.Width, g.gameboard.Height)
-	previewBlockSize := blockSize * 0.6
-
-	if previewX+previewBlockSize*4 < float64(screenWidth) {
-		for _, block := range g.nextPiece.Blocks {
-			worldX := previewX + float64(block.X)*previewBlockSize
-			worldY := previewY + float64(block.Y)*previewBlockSize
-
-			g.blockManager.DrawBlock(screen, block, worldX, worldY, previewBlockSize)
-		}
+	if !progress.Record(key, moves) {
+		return false
 	}
+	if err := savePuzzleProgress(progress); err != nil {
+		log.Printf("Warning: Could not save puzzle progress: %v", err)
+	}
+	return true
 }
 
-func (g *GameScene) spawnNewPiece() {
-	if g.nextPiece != nil {
-		g.currentType = g.nextType
-		g.currentPiece = g.copyPieceForGameplay(g.nextPiece)
-	} else {
-		pieceTypes := []PieceType{IPiece, OPiece, TPiece, SPiece, ZPiece, JPiece, LPiece}
-		g.currentType = pieceTypes[rand.Intn(len(pieceTypes))]
-		g.currentPiece = g.gameLogic.SpawnNewPiece(g.currentType)
+// saveProgress writes the game in progress so it can be continued from the
+// title screen. Replays, demos, puzzles and finished games are not saved.
+func (g *GameScene) saveProgress() {
+	if g.playback != nil || g.demo || g.gameLogic.Config().Puzzle != nil || g.gameLogic.IsGameOver() {
+		return
 	}
-
-	g.generateNextPiece()
-
-	if g.currentPiece != nil && !g.gameLogic.IsValidPositionIgnoreNeutral(g.currentPiece, 0, 0) {
-		g.sceneManager.TransitionToEndScreen(g.CurrentScore)
+	state, err := g.gameLogic.Snapshot()
+	if err == nil {
+		err = saveGame(state)
+	}
+	if err != nil {
+		log.Printf("Warning: Could not save game: %v", err)
 	}
 }
 
-func (g *GameScene) generateNextPiece() {
-	pieceTypes := []PieceType{IPiece, OPiece, TPiece, SPiece, ZPiece, JPiece, LPiece}
-	g.nextType = pieceTypes[rand.Intn(len(pieceTypes))]
+func (g *GameScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{15, 20, 30, 255})
+	g.PlayField.Draw(screen)
+
+	g.pauseController.Draw(screen)
+	if g.gameState.IsPaused && g.playback == nil {
+		w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+		hint := "Press " + g.sceneManager.settings.Controls.Describe(ActionResign) + " to end the game"
+		drawCentredText(screen, hint, pauseSubtitleFont, w/2, h/2+80, color.RGBA{200, 200, 200, 255})
+	}
 
-	g.nextPiece = g.blockManager.CreateTetrisPiece(g.nextType, 0, 0)
+	if g.demo {
+		g.renderer.RenderLabel(screen, "DEMO - press any key", float64(g.gameboard.X), float64(g.gameboard.Y-30))
+	}
 }
 
 func (g *GameScene) Layout(outerWidth, outerHeight int) (int, int) {
@@ -232,185 +212,67 @@ func (g *GameScene) Layout(outerWidth, outerHeight int) (int, int) {
 }
 
 func NewGameScene(sm *SceneManager) *GameScene {
-	fallTimer := stopwatch.NewStopwatch(FallInterval * time.Second)
-	fallTimer.Start()
-
-	gameboard := NewGameboard(GameboardWidth, GameboardHeight)
-	blockManager := NewBlockManager()
-	gameLogic := NewGameLogic(gameboard, blockManager)
-	audioManager := NewAudioManager()
-	inputHandler := NewInputHandler(gameLogic, audioManager)
-	renderer := NewGameRenderer(gameboard, blockManager)
-	particleSystem := NewParticleSystem()
-	screenShake := NewScreenShake()
-	scorePopups := NewScorePopupSystem()
-	gameState := NewGameState()
-	pauseController := NewPauseController(gameState, audioManager)
-
-	err := audioManager.Initialize()
-	if err != nil {
-		println("Warning: Could not initialize audio:", err.Error())
-	}
-
-	g := &GameScene{
-		sceneManager:    sm,
-		gameboard:       gameboard,
-		blockManager:    blockManager,
-		gameLogic:       gameLogic,
-		inputHandler:    inputHandler,
-		renderer:        renderer,
-		particleSystem:  particleSystem,
-		audioManager:    audioManager,
-		screenShake:     screenShake,
-		scorePopups:     scorePopups,
-		pauseController: pauseController,
-		gameState:       gameState,
-		fallTimer:       fallTimer,
-		CurrentScore:    0,
-		lastUpdateTime:  time.Now(),
-
-		shakeOp:    &ebiten.DrawImageOptions{},
-		particleOp: &ebiten.DrawImageOptions{},
-		blocksOp:   &ebiten.DrawImageOptions{},
-	}
-
-	gameLogic.SetExplosionCallback(func(worldX, worldY float64, blockType BlockType) {
-		particleSystem.AddExplosion(worldX, worldY, blockType)
-	})
-
-	gameLogic.SetAudioCallback(func(blocksRemoved int) {
-		audioManager.PlayBlockBreakMultiple(blocksRemoved)
-
-		intensity := float64(blocksRemoved) * 2.0
-		duration := 0.2 + float64(blocksRemoved)*0.05
-		screenShake.StartShake(intensity, duration)
-	})
-
-	gameLogic.SetDustCallback(func(worldX, worldY float64) {
-		particleSystem.AddDustCloud(worldX, worldY)
-	})
-
-	gameLogic.SetHardDropCallback(func(dropHeight int) {
-		intensity := 1.0 + float64(dropHeight)*0.5
-		duration := 0.1
-		screenShake.StartShake(intensity, duration)
-	})
-
-	g.generateNextPiece()
-
-	g.spawnNewPiece()
-
-	audioManager.StartBackgroundMusic()
-
+	g := newGameScene(sm, engine.NewGame(engine.NewRNG(sm.NextGameSeed()), sm.config))
+	g.recording = engine.NewReplay(g.gameLogic.Seed(), sm.config)
 	return g
 }
 
-func (g *GameScene) copyPieceForGameplay(piece *TetrisPiece) *TetrisPiece {
-	blocksCopy := make([]Block, len(piece.Blocks))
-	copy(blocksCopy, piece.Blocks)
-
-	blockSize := g.blockManager.GetScaledBlockSize(g.gameboard.Width, g.gameboard.Height)
-	gameboardWidthInBlocks := int(float64(g.gameboard.Width) / blockSize)
-	centerX := gameboardWidthInBlocks / 2
-
-	return &TetrisPiece{
-		Blocks:   blocksCopy,
-		X:        centerX,
-		Y:        0,
-		Rotation: piece.Rotation,
-	}
+// NewDemoGameScene is an attract-mode game played by the reference bot. It
+// is always a classic game, whatever mode the player has chosen.
+func NewDemoGameScene(sm *SceneManager) *GameScene {
+	config := sm.config
+	config.Mode = engine.ClassicMode
+	g := newGameScene(sm, engine.NewGame(engine.NewRNG(sm.NextGameSeed()), config))
+	g.controller = engine.NewBot(engine.DefaultBotWeights(), demoBotPace)
+	g.demo = true
+	return g
 }
 
-func (g *GameScene) updateVisualEffects(dt float64) {
-	if g.particleSystem != nil {
-		g.particleSystem.Update(dt)
-	}
-
-	if g.screenShake != nil {
-		g.screenShake.Update(dt)
-	}
-
-	if g.scorePopups != nil {
-		g.scorePopups.Update(dt)
-	}
+// NewPuzzleGameScene plays p under the player's handling settings.
+func NewPuzzleGameScene(sm *SceneManager, p *engine.Puzzle) *GameScene {
+	config := sm.config
+	config.Puzzle = p
+	g := newGameScene(sm, engine.NewGame(engine.NewRNG(puzzleSeed), config))
+	g.recording = engine.NewReplay(puzzleSeed, config)
+	return g
 }
 
-func (g *GameScene) updateWobblingBlocks(dt float64) {
-	anyBlocksFinishedArcing := g.gameLogic.UpdateArcingBlocks(dt)
-
-	anyBlocksLanded := g.gameLogic.UpdateFallingBlocks(dt)
-
-	anyBlocksFinished := g.gameLogic.UpdateWobblingBlocks(dt)
-
-	g.gameLogic.UpdateElectricalStorms(dt)
-	g.gameLogic.ClearInvalidStorms()
-
-	newNeutralBlocks := g.gameLogic.UpdateStormTimers(dt)
-	for _, neutralBlock := range newNeutralBlocks {
-		g.gameLogic.AddNeutralBlock(neutralBlock)
-
-		blockSize := g.blockManager.GetScaledBlockSize(g.gameboard.Width, g.gameboard.Height)
-		worldX := float64(g.gameboard.X) + float64(neutralBlock.X)*blockSize + blockSize/2
-		worldY := float64(g.gameboard.Y) + float64(neutralBlock.Y)*blockSize + blockSize/2
-		g.particleSystem.AddDustCloud(worldX, worldY)
-	}
-
-	if len(newNeutralBlocks) > 0 {
-		g.gameLogic.processBlockFalling()
-	}
-
-	if anyBlocksLanded || anyBlocksFinishedArcing {
-		reactionScore := g.gameLogic.CheckForNewReactions()
-		g.gameLogic.CheckForElectricalStorms()
-
-		if reactionScore > 0 {
-			g.CurrentScore += reactionScore
-		}
-	}
-
-	if anyBlocksFinished {
-		removedCount := g.gameLogic.RemoveFinishedWobblingBlocks()
-
-		if removedCount > 0 {
-			g.gameLogic.processBlockFalling()
-
-			reactionScore := g.gameLogic.CheckForNewReactions()
-			g.gameLogic.CheckForElectricalStorms()
-
-			if reactionScore > 0 {
-				g.CurrentScore += reactionScore
-
-				popupX := float64(g.gameboard.X + g.gameboard.Width/2)
-				popupY := float64(g.gameboard.Y + g.gameboard.Height/3)
-				g.scorePopups.AddScorePopup(popupX, popupY, reactionScore)
-			}
-		}
-	}
+// NewReplayGameScene plays r back through a fresh game with the keyboard
+// ignored.
+func NewReplayGameScene(sm *SceneManager, r *engine.Replay) *GameScene {
+	g := newGameScene(sm, engine.NewGame(engine.NewRNG(r.Seed), r.Config))
+	g.playback = r
+	return g
 }
 
-func (g *GameScene) placePieceAndCheckReactions() {
-	if g.currentPiece == nil {
-		return
+// NewResumedGameScene continues a saved game.
+func NewResumedGameScene(sm *SceneManager, state *engine.SaveState) (*GameScene, error) {
+	game, err := engine.RestoreGame(state)
+	if err != nil {
+		return nil, err
 	}
+	return newGameScene(sm, game), nil
+}
 
-	g.gameLogic.PlacePiece(g.currentPiece)
-
-	reactionScore := g.gameLogic.CheckForNewReactions()
-
-	g.gameLogic.CheckForElectricalStorms()
-
-	if reactionScore > 0 {
-		g.CurrentScore += reactionScore
+func newGameScene(sm *SceneManager, game *engine.Game) *GameScene {
+	c := NewGameComponents(game, sm.audioManager, sm.settings)
 
-		popupX := float64(g.gameboard.X + g.gameboard.Width/2)
-		popupY := float64(g.gameboard.Y + g.gameboard.Height/3)
-		g.scorePopups.AddScorePopup(popupX, popupY, reactionScore)
+	g := &GameScene{
+		PlayField:       newPlayField(c),
+		sceneManager:    sm,
+		controller:      c.InputHandler,
+		audioManager:    c.AudioManager,
+		pauseController: c.PauseController,
+		lastUpdateTime:  time.Now(),
 	}
 
-	if g.gameLogic.IsGameOver() {
-		g.sceneManager.TransitionToEndScreen(g.CurrentScore)
-		return
-	}
+	// Leaving the scene comes last, once everything else has heard the
+	// game end.
+	SubscribeWithPriority(c.EventSystem, PriorityLow, func(GameOverEvent) {
+		g.endGame()
+	})
 
-	g.spawnNewPiece()
+	g.audioManager.StartBackgroundMusic()
+
+	return g
 }
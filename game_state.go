@@ -1,13 +1,16 @@
 package main
 
-import "time"
-
 type GameState struct {
 	IsPaused     bool
 	Score        int
 	Level        int
 	LinesCleared int
-	LastUpdate   time.Time
+	Chain        int
+	Combo        int
+	// ModeLabel and ModeValue are the mode's progress, such as the time
+	// left in an ultra game.
+	ModeLabel string
+	ModeValue string
 }
 
 func NewGameState() *GameState {
@@ -16,7 +19,6 @@ func NewGameState() *GameState {
 		Score:        0,
 		Level:        1,
 		LinesCleared: 0,
-		LastUpdate:   time.Now(),
 	}
 }
 
@@ -27,14 +29,3 @@ func (gs *GameState) TogglePause() {
 func (gs *GameState) AddScore(points int) {
 	gs.Score += points
 }
-
-func (gs *GameState) GetDeltaTime() float64 {
-	now := time.Now()
-	if gs.LastUpdate.IsZero() {
-		gs.LastUpdate = now
-		return 0
-	}
-	dt := now.Sub(gs.LastUpdate).Seconds()
-	gs.LastUpdate = now
-	return dt
-}
@@ -0,0 +1,46 @@
+package main
+
+// GameStats tallies what happened over a game, as heard on the event bus.
+type GameStats struct {
+	PiecesPlaced    int
+	HardDrops       int
+	Reactions       int
+	BlocksCleared   int
+	BiggestReaction int
+	BestChain       int
+	BestCombo       int
+	StormAssists    int
+	StormsIgnited   int
+	NeutralsDropped int
+}
+
+func NewGameStats(events *EventSystem) *GameStats {
+	stats := &GameStats{}
+
+	Subscribe(events, func(PieceLockedEvent) {
+		stats.PiecesPlaced++
+	})
+	Subscribe(events, func(HardDropEvent) {
+		stats.HardDrops++
+	})
+	Subscribe(events, func(data ReactionStartedEvent) {
+		stats.Reactions++
+		stats.BiggestReaction = max(stats.BiggestReaction, data.Count)
+		stats.BestChain = max(stats.BestChain, data.Chain)
+		stats.BestCombo = max(stats.BestCombo, data.Combo)
+		if data.StormAssisted {
+			stats.StormAssists++
+		}
+	})
+	Subscribe(events, func(data ReactionFinishedEvent) {
+		stats.BlocksCleared += data.Count
+	})
+	Subscribe(events, func(StormIgnitedEvent) {
+		stats.StormsIgnited++
+	})
+	Subscribe(events, func(NeutralDroppedEvent) {
+		stats.NeutralsDropped++
+	})
+
+	return stats
+}
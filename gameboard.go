@@ -3,6 +3,7 @@ package main
 import (
 	_ "embed"
 	"fmt"
+	"image"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -39,15 +40,21 @@ func NewGameboard(baseWidth, baseHeight int) *Gameboard {
 }
 
 func (gb *Gameboard) UpdateScale(screenWidth, screenHeight int) {
-	scaleX := float64(screenWidth) / 320.0
-	scaleY := float64(screenHeight) / 320.0
+	gb.UpdateScaleIn(image.Rect(0, 0, screenWidth, screenHeight))
+}
+
+// UpdateScaleIn sizes the board to fit area, centred across it, as
+// UpdateScale does for the whole screen.
+func (gb *Gameboard) UpdateScaleIn(area image.Rectangle) {
+	scaleX := float64(area.Dx()) / 320.0
+	scaleY := float64(area.Dy()) / 320.0
 	scale := min(scaleX, scaleY)
 
 	gb.Width = int(float64(gb.baseWidth) * scale)
 	gb.Height = int(float64(gb.baseHeight) * scale)
 
-	gb.X = (screenWidth - gb.Width) / 2
-	gb.Y = 0
+	gb.X = area.Min.X + (area.Dx()-gb.Width)/2
+	gb.Y = area.Min.Y
 }
 
 func (gb *Gameboard) Draw(screen *ebiten.Image) {
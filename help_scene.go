@@ -92,12 +92,7 @@ func (h *HelpScene) Draw(screen *ebiten.Image) {
 		},
 		{
 			title: "CONTROLS:",
-			lines: []string{
-				"WASD or Arrow Keys: Move piece",
-				"Space: Rotate piece",
-				"P: Pause game",
-				"H: Toggle this help (from title screen)",
-			},
+			lines: h.controlLines(),
 		},
 	}
 
@@ -138,6 +133,17 @@ func (h *HelpScene) Draw(screen *ebiten.Image) {
 	text.Draw(screen, footerText, h.helpFont, footerOp)
 }
 
+// controlLines describes the current bindings, which the player may have
+// changed from the settings.
+func (h *HelpScene) controlLines() []string {
+	controls := h.sceneManager.settings.Controls
+	lines := make([]string, 0, len(Actions)+1)
+	for _, action := range Actions {
+		lines = append(lines, action.Label()+": "+controls.Describe(action))
+	}
+	return append(lines, "H: Toggle this help (from title screen)")
+}
+
 func (h *HelpScene) Update() error {
 	hPressed := ebiten.IsKeyPressed(ebiten.KeyH)
 	if hPressed && !h.prevHPressed {
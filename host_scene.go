@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"log"
+	"net"
+	"strings"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// netResult is how an attempt to start a networked match turned out.
+type netResult struct {
+	session *engine.NetSession
+	err     error
+}
+
+// HostScene waits for an opponent to join over the network and starts the
+// match when they do, dealt under this machine's rules.
+type HostScene struct {
+	sceneManager *SceneManager
+	titleFont    *text.GoTextFace
+	textFont     *text.GoTextFace
+	listener     net.Listener
+	// addresses are this machine's LAN addresses, to tell the opponent.
+	addresses []string
+	results   chan netResult
+	// err is why listening failed, or why the last opponent couldn't join.
+	err error
+}
+
+func (hs *HostScene) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		hs.cancel()
+		hs.sceneManager.TransitionTo(SceneTitleScreen)
+		return nil
+	}
+
+	select {
+	case r, ok := <-hs.results:
+		if !ok {
+			return nil
+		}
+		if r.err != nil {
+			hs.err = r.err
+			return nil
+		}
+		hs.listener.Close()
+		hs.sceneManager.StartNetVersus(r.session)
+	default:
+	}
+	return nil
+}
+
+// accept takes opponents until one completes the handshake. One that
+// doesn't, say for running another version, is reported and the wait goes
+// on.
+func (hs *HostScene) accept(seed int64, config engine.Config) {
+	defer close(hs.results)
+	for {
+		conn, err := hs.listener.Accept()
+		if err != nil {
+			// The listener was closed: the host gave up waiting.
+			return
+		}
+		session, err := engine.HostNetMatch(conn, seed, config, engine.DefaultInputDelay)
+		if err != nil {
+			conn.Close()
+			log.Printf("Warning: Opponent from %v could not join: %v", conn.RemoteAddr(), err)
+			hs.results <- netResult{err: err}
+			continue
+		}
+		hs.results <- netResult{session: session}
+		return
+	}
+}
+
+// cancel stops waiting for an opponent. One who joins at the last moment
+// is hung up on.
+func (hs *HostScene) cancel() {
+	if hs.listener == nil {
+		return
+	}
+	hs.listener.Close()
+	go func() {
+		for r := range hs.results {
+			if r.session != nil {
+				r.session.Close()
+				return
+			}
+		}
+	}()
+}
+
+func (hs *HostScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{10, 15, 25, 255})
+	w := screen.Bounds().Dx()
+	normal := color.RGBA{180, 180, 200, 255}
+
+	drawCentredText(screen, "HOST A MATCH", hs.titleFont, w/2, 60, color.RGBA{220, 220, 255, 255})
+
+	y := 160
+	if hs.listener == nil {
+		drawCentredText(screen, "Could not listen for opponents:", hs.textFont, w/2, y, color.RGBA{255, 120, 120, 255})
+		drawCentredText(screen, hs.err.Error(), hs.textFont, w/2, y+30, normal)
+	} else {
+		drawCentredText(screen, fmt.Sprintf("Waiting for an opponent on port %d...", engine.DefaultNetPort), hs.textFont, w/2, y, normal)
+		if len(hs.addresses) > 0 {
+			drawCentredText(screen, "They can join at "+strings.Join(hs.addresses, " or "), hs.textFont, w/2, y+30, color.RGBA{255, 255, 100, 255})
+		}
+		if hs.err != nil {
+			drawCentredText(screen, "Last attempt failed: "+hs.err.Error(), hs.textFont, w/2, y+80, color.RGBA{255, 120, 120, 255})
+		}
+	}
+	drawCentredText(screen, "Escape: back", hs.textFont, w/2, y+140, color.RGBA{150, 150, 170, 255})
+}
+
+func (hs *HostScene) Layout(outerWidth, outerHeight int) (int, int) {
+	return outerWidth, outerHeight
+}
+
+// NewHostScene starts listening for an opponent straight away.
+func NewHostScene(sm *SceneManager) *HostScene {
+	titleFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	textFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	hs := &HostScene{
+		sceneManager: sm,
+		titleFont:    &text.GoTextFace{Source: titleFontSource, Size: 36},
+		textFont:     &text.GoTextFace{Source: textFontSource, Size: 18},
+		addresses:    lanAddresses(),
+		results:      make(chan netResult, 1),
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", engine.DefaultNetPort))
+	if err != nil {
+		hs.err = err
+		return hs
+	}
+	hs.listener = listener
+	go hs.accept(sm.NextGameSeed(), sm.config)
+	return hs
+}
+
+// lanAddresses returns this machine's IPv4 addresses other than loopback.
+func lanAddresses() []string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var found []string
+	for _, addr := range addrs {
+		ip, ok := addr.(*net.IPNet)
+		if ok && !ip.IP.IsLoopback() && ip.IP.To4() != nil {
+			found = append(found, ip.IP.String())
+		}
+	}
+	return found
+}
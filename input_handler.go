@@ -1,113 +1,49 @@
 package main
 
 import (
-	"time"
+	"union/engine"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
+// InputHandler is the Controller for a human player: it turns the player's
+// bindings into the logical actions the engine steps on. Timing such as
+// auto-repeat lives in the engine so it runs on ticks.
 type InputHandler struct {
-	gameLogic        *GameLogic
-	audioManager     *AudioManager
-	leftRepeatTimer  time.Time
-	rightRepeatTimer time.Time
-	downRepeatTimer  time.Time
-	initialDelay     time.Duration
-	repeatDelay      time.Duration
+	// controls and pads are looked up every tick, as the bindings are
+	// shared with the settings scenes and gamepads come and go.
+	controls func() Controls
+	pads     func() []ebiten.GamepadID
 }
 
-func NewInputHandler(gameLogic *GameLogic, audioManager *AudioManager) *InputHandler {
+func NewInputHandler(settings *Settings) *InputHandler {
 	return &InputHandler{
-		gameLogic:    gameLogic,
-		audioManager: audioManager,
-		initialDelay: 200 * time.Millisecond,
-		repeatDelay:  100 * time.Millisecond,
+		controls: func() Controls { return settings.Controls },
+		pads:     standardGamepads,
 	}
 }
 
-func (ih *InputHandler) HandleInput(currentPiece *TetrisPiece, currentType PieceType) bool {
-	if currentPiece == nil {
-		return false
-	}
-
-	now := time.Now()
-	shouldPlace := false
-
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		ih.gameLogic.TryRotatePiece(currentPiece, currentType)
-	}
-
-	leftPressed := ebiten.IsKeyPressed(ebiten.KeyA) || ebiten.IsKeyPressed(ebiten.KeyArrowLeft)
-	if leftPressed {
-		if inpututil.IsKeyJustPressed(ebiten.KeyA) || inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
-			if ih.gameLogic.TryMovePiece(currentPiece, -1, 0) {
-				if ih.audioManager != nil {
-					ih.audioManager.PlaySwooshSound()
-				}
-			}
-			ih.leftRepeatTimer = now.Add(ih.initialDelay)
-		} else if now.After(ih.leftRepeatTimer) {
-			if ih.gameLogic.TryMovePiece(currentPiece, -1, 0) {
-				if ih.audioManager != nil {
-					ih.audioManager.PlaySwooshSound()
-				}
-			}
-			ih.leftRepeatTimer = now.Add(ih.repeatDelay)
-		}
-	}
-
-	rightPressed := ebiten.IsKeyPressed(ebiten.KeyD) || ebiten.IsKeyPressed(ebiten.KeyArrowRight)
-	if rightPressed {
-		if inpututil.IsKeyJustPressed(ebiten.KeyD) || inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
-			if ih.gameLogic.TryMovePiece(currentPiece, 1, 0) {
-				if ih.audioManager != nil {
-					ih.audioManager.PlaySwooshSound()
-				}
-			}
-			ih.rightRepeatTimer = now.Add(ih.initialDelay)
-		} else if now.After(ih.rightRepeatTimer) {
-			if ih.gameLogic.TryMovePiece(currentPiece, 1, 0) {
-				if ih.audioManager != nil {
-					ih.audioManager.PlaySwooshSound()
-				}
-			}
-			ih.rightRepeatTimer = now.Add(ih.repeatDelay)
-		}
-	}
-
-	downPressed := ebiten.IsKeyPressed(ebiten.KeyS) || ebiten.IsKeyPressed(ebiten.KeyArrowDown)
-	if downPressed {
-		if inpututil.IsKeyJustPressed(ebiten.KeyS) || inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
-			if ih.gameLogic.TryMovePiece(currentPiece, 0, 1) {
-				if ih.audioManager != nil {
-					ih.audioManager.PlaySwooshSound()
-				}
-			} else {
-				shouldPlace = true
-				dropHeight := 1
-				ih.triggerHardDropShake(dropHeight)
-			}
-			ih.downRepeatTimer = now.Add(50 * time.Millisecond)
-		} else if now.After(ih.downRepeatTimer) {
-			if ih.gameLogic.TryMovePiece(currentPiece, 0, 1) {
-				if ih.audioManager != nil {
-					ih.audioManager.PlaySwooshSound()
-				}
-			} else {
-				shouldPlace = true
-				dropHeight := 1
-				ih.triggerHardDropShake(dropHeight)
-			}
-			ih.downRepeatTimer = now.Add(50 * time.Millisecond)
-		}
+// NewPlayerInputHandler reads one versus player's bindings, with only
+// their own gamepad.
+func NewPlayerInputHandler(settings *Settings, player int) *InputHandler {
+	return &InputHandler{
+		controls: func() Controls { return settings.VersusControls[player] },
+		pads:     func() []ebiten.GamepadID { return playerGamepad(player) },
 	}
-
-	return shouldPlace
 }
 
-func (ih *InputHandler) triggerHardDropShake(dropHeight int) {
-	if ih.gameLogic.hardDropCallback != nil {
-		ih.gameLogic.hardDropCallback(dropHeight)
+func (ih *InputHandler) Input(*engine.View) engine.Input {
+	controls, pads := ih.controls(), ih.pads()
+	return engine.Input{
+		Left:      controls.PressedOn(ActionMoveLeft, pads),
+		Right:     controls.PressedOn(ActionMoveRight, pads),
+		SoftDrop:  controls.PressedOn(ActionSoftDrop, pads),
+		HardDrop:  controls.PressedOn(ActionHardDrop, pads),
+		Rotate:    controls.PressedOn(ActionRotateCW, pads),
+		RotateCCW: controls.PressedOn(ActionRotateCCW, pads),
+		Rotate180: controls.PressedOn(ActionRotate180, pads),
+		Hold:      controls.PressedOn(ActionHold, pads),
+		Pause:     controls.PressedOn(ActionPause, pads),
+		Resign:    controls.PressedOn(ActionResign, pads),
 	}
 }
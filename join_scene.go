@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"log"
+	"net"
+	"strconv"
+	"time"
+	"unicode"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const (
+	// maxAddressLength bounds the typed host address.
+	maxAddressLength = 64
+	dialTimeout      = 5 * time.Second
+)
+
+// JoinScene takes the address of a host and joins their match.
+type JoinScene struct {
+	sceneManager *SceneManager
+	titleFont    *text.GoTextFace
+	textFont     *text.GoTextFace
+	address      []rune
+	// results is set while connecting.
+	results chan netResult
+	err     error
+}
+
+func (js *JoinScene) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		js.cancel()
+		js.sceneManager.TransitionTo(SceneTitleScreen)
+		return nil
+	}
+
+	if js.results != nil {
+		select {
+		case r := <-js.results:
+			js.results = nil
+			if r.err != nil {
+				js.err = r.err
+				return nil
+			}
+			js.remember()
+			js.sceneManager.StartNetVersus(r.session)
+		default:
+		}
+		return nil
+	}
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if unicode.IsPrint(r) && !unicode.IsSpace(r) && len(js.address) < maxAddressLength {
+			js.address = append(js.address, r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(js.address) > 0 {
+		js.address = js.address[:len(js.address)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && len(js.address) > 0 {
+		js.connect()
+	}
+	return nil
+}
+
+// connect dials the host in the background, as it can take a while.
+func (js *JoinScene) connect() {
+	js.err = nil
+	results := make(chan netResult, 1)
+	js.results = results
+	addr := hostAddress(string(js.address))
+	go func() {
+		conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+		if err != nil {
+			results <- netResult{err: err}
+			return
+		}
+		session, err := engine.JoinNetMatch(conn)
+		if err != nil {
+			conn.Close()
+		}
+		results <- netResult{session: session, err: err}
+	}()
+}
+
+// cancel abandons a connection in progress, hanging up if it gets through.
+func (js *JoinScene) cancel() {
+	if js.results == nil {
+		return
+	}
+	results := js.results
+	js.results = nil
+	go func() {
+		if r := <-results; r.session != nil {
+			r.session.Close()
+		}
+	}()
+}
+
+// remember keeps the address to offer next time.
+func (js *JoinScene) remember() {
+	js.sceneManager.settings.NetAddress = string(js.address)
+	if err := saveSettings(js.sceneManager.settings); err != nil {
+		log.Printf("Warning: Could not save settings: %v", err)
+	}
+}
+
+// hostAddress adds the default port to an address given without one.
+func hostAddress(address string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(address, strconv.Itoa(engine.DefaultNetPort))
+}
+
+func (js *JoinScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{10, 15, 25, 255})
+	w := screen.Bounds().Dx()
+
+	drawCentredText(screen, "JOIN A MATCH", js.titleFont, w/2, 60, color.RGBA{220, 220, 255, 255})
+	drawCentredText(screen, "Host address:", js.textFont, w/2, 160, color.RGBA{180, 180, 200, 255})
+
+	address := string(js.address)
+	if js.results == nil && (time.Now().UnixMilli()/500)%2 == 0 {
+		address += "_"
+	}
+	drawCentredText(screen, address, js.titleFont, w/2, 200, color.RGBA{255, 255, 100, 255})
+
+	switch {
+	case js.results != nil:
+		drawCentredText(screen, "Connecting...", js.textFont, w/2, 280, color.RGBA{180, 180, 200, 255})
+	case js.err != nil:
+		drawCentredText(screen, "Could not join: "+js.err.Error(), js.textFont, w/2, 280, color.RGBA{255, 120, 120, 255})
+	}
+	drawCentredText(screen, "Enter: join   Escape: back", js.textFont, w/2, 340, color.RGBA{150, 150, 170, 255})
+}
+
+func (js *JoinScene) Layout(outerWidth, outerHeight int) (int, int) {
+	return outerWidth, outerHeight
+}
+
+// NewJoinScene offers the address last joined.
+func NewJoinScene(sm *SceneManager) *JoinScene {
+	titleFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	textFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	return &JoinScene{
+		sceneManager: sm,
+		titleFont:    &text.GoTextFace{Source: titleFontSource, Size: 36},
+		textFont:     &text.GoTextFace{Source: textFontSource, Size: 18},
+		address:      []rune(sm.settings.NetAddress),
+	}
+}
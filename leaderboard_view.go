@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// LeaderboardView draws a leaderboard table, optionally picking out one
+// entry such as the one just added.
+type LeaderboardView struct {
+	font *text.GoTextFace
+	op   *text.DrawOptions
+}
+
+func NewLeaderboardView() *LeaderboardView {
+	fontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	return &LeaderboardView{
+		font: &text.GoTextFace{
+			Source: fontSource,
+			Size:   16,
+		},
+		op: &text.DrawOptions{},
+	}
+}
+
+// Draw lays table's entries out in columns centred on the screen, starting
+// at y. highlight is the rank to pick out, or -1 for none.
+func (lv *LeaderboardView) Draw(screen *ebiten.Image, l *engine.Leaderboard, table string, highlight int, y float64) {
+	entries := l.Entries(table)
+	// Tables ranked by time show it to the hundredth, as that is what
+	// separates the entries.
+	timed := engine.RankedByTime(table)
+	w := screen.Bounds().Dx()
+	left := float64(w)/2 - 250
+
+	header := []string{"#", "NAME", "SCORE", "LEVEL", "TIME", "CHAIN", "COMBO"}
+	lv.drawRow(screen, header, left, y, color.RGBA{200, 200, 255, 255})
+
+	if len(entries) == 0 {
+		lv.drawRow(screen, []string{"", "No scores yet"}, left, y+24, color.RGBA{150, 150, 170, 255})
+		return
+	}
+	for i, entry := range entries {
+		row := []string{
+			fmt.Sprintf("%d", i+1),
+			entry.Name,
+			fmt.Sprintf("%d", entry.Score),
+			fmt.Sprintf("%d", entry.Level),
+			engine.FormatTicks(entry.Ticks, timed),
+			fmt.Sprintf("%d", entry.BestChain),
+			fmt.Sprintf("%d", entry.BestCombo),
+		}
+		rowColor := color.RGBA{180, 180, 200, 255}
+		if i == highlight {
+			rowColor = color.RGBA{255, 255, 100, 255}
+		}
+		lv.drawRow(screen, row, left, y+float64(i+1)*22, rowColor)
+	}
+}
+
+var leaderboardColumns = []float64{0, 40, 190, 290, 350, 420, 480}
+
+func (lv *LeaderboardView) drawRow(screen *ebiten.Image, cells []string, left, y float64, clr color.Color) {
+	for i, cell := range cells {
+		lv.op.GeoM.Reset()
+		lv.op.GeoM.Translate(left+leaderboardColumns[i], y)
+		lv.op.ColorScale.Reset()
+		lv.op.ColorScale.ScaleWithColor(clr)
+		text.Draw(screen, cell, lv.font, lv.op)
+	}
+}
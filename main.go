@@ -1,24 +1,104 @@
 package main
 
 import (
-	"math/rand"
-	"time"
+	"flag"
+	"fmt"
+	"log"
+	"union/engine"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
-	// Seed random number generator
-	rand.Seed(time.Now().UnixNano())
+	seed := flag.Int64("seed", 0, "seed for piece, charge and storm randomness (0 picks a new seed every game)")
+	recordPath := flag.String("record", "", "save a replay of each game to this file when it ends")
+	replayPath := flag.String("replay", "", "play back a replay file instead of reading the keyboard")
+	randomizer := flag.String("randomizer", string(engine.SevenBag), fmt.Sprintf("how pieces are dealt: one of %v", engine.RandomizerKinds))
+	charges := flag.String("charges", string(engine.IndependentCharges), fmt.Sprintf("how piece charges are rolled: one of %v", engine.ChargeKinds))
+	mode := flag.String("mode", string(engine.ClassicMode), fmt.Sprintf("what the game is played for: one of %v", engine.ModeKinds))
+	preview := flag.Int("preview", engine.DefaultConfig().Preview, fmt.Sprintf("how many upcoming pieces to show (%d-%d)", engine.MinPreview, engine.MaxPreview))
+	flag.Parse()
+
+	settings, err := loadSettings()
+	if err != nil {
+		log.Printf("Warning: Could not load settings, using defaults: %v", err)
+		defaults := DefaultSettings()
+		settings = &defaults
+	}
+
+	// The settings pick the rules unless a flag overrides them for this run.
+	var rules ruleFlags
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["randomizer"] {
+		if rules.randomizer, err = engine.ParseRandomizer(*randomizer); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if set["charges"] {
+		if rules.charges, err = engine.ParseCharges(*charges); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if set["mode"] {
+		if rules.mode, err = engine.ParseMode(*mode); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if set["preview"] {
+		if *preview < engine.MinPreview || *preview > engine.MaxPreview {
+			log.Fatalf("preview must be between %d and %d", engine.MinPreview, engine.MaxPreview)
+		}
+		rules.preview = *preview
+	}
+	config := rules.applyTo(settings.ApplyTo(engine.DefaultConfig()))
 
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Un-ion")
-	ebiten.SetWindowSize(1200, 800)
+	ebiten.SetWindowSize(settings.WindowWidth, settings.WindowHeight)
+	ebiten.SetTPS(engine.TicksPerSecond)
+	ebiten.SetWindowClosingHandled(true)
 
-	sceneManager := NewSceneManager()
+	sceneManager := NewSceneManager(*seed, config, settings)
+	sceneManager.SetRuleFlags(rules)
+	sceneManager.SetRecordPath(*recordPath)
 
-	err := ebiten.RunGame(sceneManager)
-	if err != nil {
+	if *replayPath != "" {
+		replay, err := engine.LoadReplay(*replayPath)
+		if err != nil {
+			log.Fatalf("Could not load replay %s: %v", *replayPath, err)
+		}
+		sceneManager.PlayReplay(replay)
+	}
+
+	if err := ebiten.RunGame(sceneManager); err != nil {
 		panic(err)
 	}
 }
+
+// ruleFlags are the rules given on the command line. They hold for the
+// whole run, whatever the settings are changed to.
+type ruleFlags struct {
+	randomizer engine.RandomizerKind
+	charges    engine.ChargeKind
+	mode       engine.ModeKind
+	// preview is 0 when the flag wasn't given.
+	preview int
+}
+
+// applyTo returns config with the rules given on the command line in place.
+func (f ruleFlags) applyTo(config engine.Config) engine.Config {
+	if f.randomizer != "" {
+		config.Randomizer = f.randomizer
+	}
+	if f.charges != "" {
+		config.Charges = f.charges
+	}
+	if f.mode != "" {
+		config.Mode = f.mode
+	}
+	if f.preview != 0 {
+		config.Preview = f.preview
+	}
+	return config
+}
@@ -3,9 +3,10 @@ package main
 import (
 	"bytes"
 	"image/color"
+	"slices"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"golang.org/x/image/font/gofont/goregular"
 )
@@ -24,17 +25,27 @@ var pauseSubtitleFont = &text.GoTextFace{
 type PauseController struct {
 	gameState    *GameState
 	audioManager *AudioManager
+	pauseWasHeld bool
+	// inputs are the players who can pause, whose bindings the resume
+	// hint names.
+	inputs []*InputHandler
 }
 
-func NewPauseController(gameState *GameState, audioManager *AudioManager) *PauseController {
+func NewPauseController(gameState *GameState, audioManager *AudioManager, inputs ...*InputHandler) *PauseController {
 	return &PauseController{
 		gameState:    gameState,
 		audioManager: audioManager,
+		inputs:       inputs,
 	}
 }
 
-func (pc *PauseController) Update() {
-	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+// Update toggles pause when the pause control goes down. It takes the
+// control's state rather than reading the keyboard so replays can pause too.
+func (pc *PauseController) Update(pauseHeld bool) {
+	justPressed := pauseHeld && !pc.pauseWasHeld
+	pc.pauseWasHeld = pauseHeld
+
+	if justPressed {
 		pc.gameState.TogglePause()
 
 		if pc.gameState.IsPaused {
@@ -67,7 +78,7 @@ func (pc *PauseController) Draw(screen *ebiten.Image) {
 	pausedOp.ColorScale.ScaleWithColor(color.RGBA{220, 220, 255, 255})
 	text.Draw(screen, pausedText, pauseTitleFont, pausedOp)
 
-	resumeText := "Press P to Resume"
+	resumeText := "Press " + pc.pauseKeys() + " to Resume"
 	resumeAdvance, _ := text.Measure(resumeText, pauseSubtitleFont, 0)
 	resumeX := centerX - int(resumeAdvance)/2
 	resumeY := centerY + 40
@@ -76,3 +87,14 @@ func (pc *PauseController) Draw(screen *ebiten.Image) {
 	resumeOp.ColorScale.ScaleWithColor(color.RGBA{200, 200, 200, 255})
 	text.Draw(screen, resumeText, pauseSubtitleFont, resumeOp)
 }
+
+// pauseKeys names the controls that resume the game.
+func (pc *PauseController) pauseKeys() string {
+	var keys []string
+	for _, ih := range pc.inputs {
+		if k := ih.controls().Describe(ActionPause); !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return strings.Join(keys, " or ")
+}
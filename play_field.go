@@ -0,0 +1,285 @@
+package main
+
+import (
+	"image/color"
+	"time"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// PlayField is one player's board on screen: the game being played and
+// everything that draws it, from the blocks and HUD to the particles and
+// shake. The single-player scene has one; versus has two side by side.
+type PlayField struct {
+	gameboard      *Gameboard
+	blockManager   *BlockManager
+	gameLogic      *GameLogic
+	renderer       *GameRenderer
+	particleSystem *ParticleSystem
+	screenShake    *ScreenShake
+	scorePopups    *ScorePopupSystem
+	gameState      *GameState
+	stats          *GameStats
+	lastTickTime   time.Time
+
+	// versus narrows the HUD to fit half the window and adds the garbage
+	// meter.
+	versus bool
+
+	tempImage     *ebiten.Image
+	particleImage *ebiten.Image
+	blocksImage   *ebiten.Image
+
+	shakeOp    *ebiten.DrawImageOptions
+	particleOp *ebiten.DrawImageOptions
+	blocksOp   *ebiten.DrawImageOptions
+}
+
+func newPlayField(c *GameComponents) *PlayField {
+	pf := &PlayField{
+		gameboard:      c.Gameboard,
+		blockManager:   c.BlockManager,
+		gameLogic:      c.GameLogic,
+		renderer:       c.Renderer,
+		particleSystem: c.ParticleSystem,
+		screenShake:    c.ScreenShake,
+		scorePopups:    c.ScorePopups,
+		gameState:      c.GameState,
+		stats:          c.Stats,
+
+		shakeOp:    &ebiten.DrawImageOptions{},
+		particleOp: &ebiten.DrawImageOptions{},
+		blocksOp:   &ebiten.DrawImageOptions{},
+	}
+	pf.syncGameState()
+	return pf
+}
+
+// step advances the game by one tick of input.
+func (pf *PlayField) step(input engine.Input, now time.Time) {
+	pf.gameLogic.Step(input)
+	pf.lastTickTime = now
+	pf.syncGameState()
+}
+
+// syncGameState copies the engine's progress into the GameState for the
+// HUD. LinesCleared counts neutralized blocks, as Un-ion has no lines.
+func (pf *PlayField) syncGameState() {
+	pf.gameState.Score = pf.gameLogic.Score()
+	pf.gameState.Level = pf.gameLogic.Level()
+	pf.gameState.LinesCleared = pf.gameLogic.BlocksCleared()
+	scoring := pf.gameLogic.Scoring()
+	pf.gameState.Chain = scoring.Chain
+	pf.gameState.Combo = scoring.Combo
+	pf.gameState.ModeLabel, pf.gameState.ModeValue = pf.gameLogic.ModeStatus()
+}
+
+func (pf *PlayField) updateVisualEffects(dt float64) {
+	if pf.particleSystem != nil {
+		pf.particleSystem.Update(dt)
+	}
+
+	if pf.screenShake != nil {
+		pf.screenShake.Update(dt)
+	}
+
+	if pf.scorePopups != nil {
+		pf.scorePopups.Update(dt)
+	}
+}
+
+// tickAlpha returns how far the current frame sits between the last
+// simulation tick and the next, for interpolating motion.
+func (pf *PlayField) tickAlpha() float64 {
+	if pf.gameState.IsPaused || pf.lastTickTime.IsZero() {
+		return 0
+	}
+	alpha := time.Since(pf.lastTickTime).Seconds() / engine.TickSeconds
+	if alpha > 1 {
+		return 1
+	}
+	return alpha
+}
+
+// Draw draws the board and its HUD over whatever is already on screen.
+func (pf *PlayField) Draw(screen *ebiten.Image) {
+	shakeX, shakeY := pf.screenShake.GetOffset()
+
+	screenW, screenH := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if pf.tempImage == nil || pf.tempImage.Bounds().Dx() != screenW || pf.tempImage.Bounds().Dy() != screenH {
+		pf.tempImage = ebiten.NewImage(screenW, screenH)
+		pf.particleImage = ebiten.NewImage(screenW, screenH)
+	}
+
+	pf.tempImage.Clear()
+
+	currentPiece := pf.gameLogic.CurrentPiece()
+
+	var shadowPiece *TetrisPiece
+	if currentPiece != nil {
+		shadowPiece = pf.gameLogic.CalculateDropPosition(currentPiece)
+	}
+
+	pf.renderGameWithShadow(pf.tempImage, currentPiece, shadowPiece)
+	pf.renderer.RenderScore(pf.tempImage, pf.gameState.Score)
+	pf.renderNextPiecePreview(pf.tempImage)
+	pf.renderHoldPiece(pf.tempImage)
+	pf.renderer.RenderChainAndCombo(pf.tempImage, pf.gameState.Chain, pf.gameState.Combo)
+	if pf.versus {
+		pf.renderer.RenderLevelLabel(pf.tempImage, pf.gameState.Level)
+		pf.renderGarbageMeter(pf.tempImage)
+	} else {
+		pf.renderer.RenderStatus(pf.tempImage, pf.gameState.ModeLabel, pf.gameState.ModeValue)
+		if pf.gameLogic.Mode().Kind() != engine.ClassicMode {
+			// The mode's progress has taken the level's place.
+			pf.renderer.RenderLevelLabel(pf.tempImage, pf.gameState.Level)
+		}
+	}
+
+	if pf.scorePopups != nil {
+		pf.scorePopups.Draw(pf.tempImage)
+	}
+
+	pf.shakeOp.GeoM.Reset()
+	pf.shakeOp.GeoM.Translate(shakeX, shakeY)
+	screen.DrawImage(pf.tempImage, pf.shakeOp)
+
+	if pf.particleSystem != nil {
+		pf.particleOp.GeoM.Reset()
+		pf.particleOp.GeoM.Translate(shakeX, shakeY)
+
+		pf.particleImage.Clear()
+		pf.particleSystem.Draw(pf.particleImage)
+		screen.DrawImage(pf.particleImage, pf.particleOp)
+	}
+}
+
+func (pf *PlayField) renderGameWithShadow(screen *ebiten.Image, currentPiece, shadowPiece *TetrisPiece) {
+	pf.gameboard.Draw(screen)
+
+	blockSize := pf.blockManager.GetScaledBlockSize(pf.gameboard.Width, pf.gameboard.Height)
+	alpha := pf.tickAlpha()
+
+	if pf.blocksImage == nil || pf.blocksImage.Bounds().Dx() != pf.gameboard.Width || pf.blocksImage.Bounds().Dy() != pf.gameboard.Height {
+		pf.blocksImage = ebiten.NewImage(pf.gameboard.Width, pf.gameboard.Height)
+	}
+
+	pf.blocksImage.Clear()
+
+	for _, block := range pf.gameLogic.GetPlacedBlocks() {
+		renderX, renderY, rotation, scale := pf.gameLogic.GetBlockRenderTransform(&block, alpha)
+		worldX := renderX * blockSize
+		worldY := renderY * blockSize
+
+		if block.IsArcing || rotation != 0.0 || scale != 1.0 {
+			pf.blockManager.DrawBlockTransformed(pf.blocksImage, block, worldX, worldY, rotation, scale, blockSize)
+		} else {
+			pf.blockManager.DrawBlock(pf.blocksImage, block, worldX, worldY, blockSize)
+		}
+	}
+
+	if shadowPiece != nil && currentPiece != nil && shadowPiece.Y > currentPiece.Y {
+		for _, block := range shadowPiece.Blocks {
+			worldX := float64(shadowPiece.X+block.X) * blockSize
+			worldY := float64(shadowPiece.Y+block.Y) * blockSize
+			pf.blockManager.DrawShadowBlock(pf.blocksImage, block, worldX, worldY, blockSize)
+		}
+	}
+	if currentPiece != nil {
+		for _, block := range currentPiece.Blocks {
+			worldX := float64(currentPiece.X+block.X) * blockSize
+			worldY := float64(currentPiece.Y+block.Y) * blockSize
+			pf.blockManager.DrawBlock(pf.blocksImage, block, worldX, worldY, blockSize)
+		}
+	}
+
+	warnings := pf.gameLogic.GetStormWarnings()
+	for _, warning := range warnings {
+		worldX := float64(warning.Column) * blockSize
+		worldY := float64(warning.HighestBlockY) * blockSize
+		pf.blockManager.DrawWarningSprite(pf.blocksImage, worldX, worldY, engine.TicksToSeconds(warning.WarningTicks), blockSize, warning.Column, pf.gameboard.Width)
+	}
+
+	pf.blocksOp.GeoM.Reset()
+	pf.blocksOp.GeoM.Translate(float64(pf.gameboard.X), float64(pf.gameboard.Y))
+	screen.DrawImage(pf.blocksImage, pf.blocksOp)
+}
+
+func (pf *PlayField) renderNextPiecePreview(screen *ebiten.Image) {
+	queue := pf.gameLogic.Queue()
+	if len(queue) == 0 {
+		return
+	}
+
+	screenWidth, _ := screen.Bounds().Dx(), screen.Bounds().Dy()
+
+	previewX := float64(pf.gameboard.X + pf.gameboard.Width + 20)
+	previewY := float64(pf.gameboard.Y + 100)
+
+	blockSize := pf.blockManager.GetScaledBlockSize(pf.gameboard.Width, pf.gameboard.Height)
+	previewBlockSize := blockSize * 0.6
+
+	if previewX+previewBlockSize*4 >= float64(screenWidth) {
+		return
+	}
+
+	pf.renderer.RenderLabel(screen, "NEXT", previewX, previewY-25)
+	for i, piece := range queue {
+		// Each piece gets three rows: two for the piece and one of space.
+		slotY := previewY + float64(i)*previewBlockSize*3
+		for _, block := range piece.Blocks {
+			worldX := previewX + float64(block.X)*previewBlockSize
+			worldY := slotY + float64(block.Y)*previewBlockSize
+
+			pf.blockManager.DrawBlock(screen, block, worldX, worldY, previewBlockSize)
+		}
+	}
+}
+
+// renderHoldPiece draws the hold slot to the left of the board, dimmed while
+// it can't be used.
+func (pf *PlayField) renderHoldPiece(screen *ebiten.Image) {
+	blockSize := pf.blockManager.GetScaledBlockSize(pf.gameboard.Width, pf.gameboard.Height)
+	previewBlockSize := blockSize * 0.6
+
+	holdX := float64(pf.gameboard.X) - 20 - previewBlockSize*4
+	holdY := float64(pf.gameboard.Y + 100)
+	if holdX < 0 {
+		return
+	}
+
+	pf.renderer.RenderLabel(screen, "HOLD", holdX, holdY-25)
+	held := pf.gameLogic.HeldPiece()
+	if held == nil {
+		return
+	}
+	for _, block := range held.Blocks {
+		worldX := holdX + float64(block.X)*previewBlockSize
+		worldY := holdY + float64(block.Y)*previewBlockSize
+
+		if pf.gameLogic.CanHold() {
+			pf.blockManager.DrawBlock(screen, block, worldX, worldY, previewBlockSize)
+		} else {
+			pf.blockManager.DrawShadowBlock(screen, block, worldX, worldY, previewBlockSize)
+		}
+	}
+}
+
+// renderGarbageMeter draws the garbage waiting to rise as a red bar up the
+// left edge of the board, a block tall for every row.
+func (pf *PlayField) renderGarbageMeter(screen *ebiten.Image) {
+	rows := pf.gameLogic.IncomingGarbage()
+	if rows == 0 {
+		return
+	}
+	blockSize := pf.blockManager.GetScaledBlockSize(pf.gameboard.Width, pf.gameboard.Height)
+	height := float64(rows) * blockSize
+	if height > float64(pf.gameboard.Height) {
+		height = float64(pf.gameboard.Height)
+	}
+	x := float32(pf.gameboard.X) - 6
+	y := float32(float64(pf.gameboard.Y+pf.gameboard.Height) - height)
+	vector.DrawFilledRect(screen, x, y, 4, float32(height), color.RGBA{230, 60, 60, 255}, false)
+}
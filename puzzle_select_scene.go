@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// puzzlesShown is how many puzzles fit in the list at once; it scrolls to
+// show the rest.
+const puzzlesShown = 16
+
+// PuzzleSelectScene lists the puzzles, built in and saved from the editor,
+// marking the ones already solved. It starts the one picked, or opens it in
+// the editor.
+type PuzzleSelectScene struct {
+	sceneManager *SceneManager
+	titleFont    *text.GoTextFace
+	itemFont     *text.GoTextFace
+	helpFont     *text.GoTextFace
+	puzzles      []*engine.Puzzle
+	// builtin counts the puzzles at the start of the list that ship with
+	// the game.
+	builtin  int
+	progress *engine.PuzzleProgress
+	selected int
+	// err is why some puzzles couldn't be loaded.
+	err error
+}
+
+func (ps *PuzzleSelectScene) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		ps.sceneManager.TransitionTo(SceneTitleScreen)
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		ps.sceneManager.StartEditor(nil)
+		return nil
+	}
+	if len(ps.puzzles) == 0 {
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		ps.selected = (ps.selected + len(ps.puzzles) - 1) % len(ps.puzzles)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		ps.selected = (ps.selected + 1) % len(ps.puzzles)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		ps.sceneManager.StartPuzzle(ps.puzzles[ps.selected], ps.progressKey(ps.selected))
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		ps.sceneManager.StartEditor(ps.puzzles[ps.selected])
+	}
+	return nil
+}
+
+func (ps *PuzzleSelectScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{10, 15, 25, 255})
+	w := screen.Bounds().Dx()
+
+	solved := 0
+	for i := range ps.puzzles {
+		if _, ok := ps.progress.Solved[ps.progressKey(i)]; ok {
+			solved++
+		}
+	}
+	drawCentredText(screen, "PUZZLES", ps.titleFont, w/2, 40, color.RGBA{220, 220, 255, 255})
+	drawCentredText(screen, fmt.Sprintf("%d of %d solved", solved, len(ps.puzzles)), ps.helpFont, w/2, 95, color.RGBA{150, 200, 255, 255})
+
+	nameX := float64(w)/2 - 220
+	statusX := float64(w)/2 + 80
+	y := 130.0
+	first := max(ps.selected-puzzlesShown+1, 0)
+	for i := first; i < len(ps.puzzles) && i < first+puzzlesShown; i++ {
+		p := ps.puzzles[i]
+		if i == ps.builtin {
+			ps.drawText(screen, "YOUR LEVELS", nameX, y, color.RGBA{150, 200, 255, 255})
+			y += 28
+		}
+		clr := color.Color(color.RGBA{180, 180, 200, 255})
+		if i == ps.selected {
+			clr = color.RGBA{255, 255, 100, 255}
+			ps.drawText(screen, ">", nameX-20, y, clr)
+		}
+		ps.drawText(screen, fmt.Sprintf("%d. %s", i+1, p.Name), nameX, y, clr)
+
+		status := fmt.Sprintf("%d moves", p.MoveLimit())
+		if best, ok := ps.progress.Solved[ps.progressKey(i)]; ok {
+			status = fmt.Sprintf("SOLVED in %d", best)
+			if i != ps.selected {
+				clr = color.RGBA{150, 255, 150, 255}
+			}
+		}
+		ps.drawText(screen, status, statusX, y, clr)
+		y += 28
+	}
+
+	drawCentredText(screen, "Up/Down: choose   Enter: play   E: edit   N: new level   Escape: back", ps.helpFont, w/2, int(y)+30, color.RGBA{150, 150, 170, 255})
+	if ps.err != nil {
+		drawCentredText(screen, "Could not load every puzzle: "+ps.err.Error(), ps.helpFont, w/2, int(y)+60, color.RGBA{255, 120, 120, 255})
+	}
+}
+
+// progressKey returns what the i'th puzzle's solve record is kept under.
+func (ps *PuzzleSelectScene) progressKey(i int) string {
+	return puzzleProgressKey(ps.puzzles[i], i >= ps.builtin)
+}
+
+func (ps *PuzzleSelectScene) drawText(screen *ebiten.Image, s string, x, y float64, clr color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, ps.itemFont, op)
+}
+
+func (ps *PuzzleSelectScene) Layout(outerWidth, outerHeight int) (int, int) {
+	return outerWidth, outerHeight
+}
+
+// NewPuzzleSelectScene loads the puzzles and the player's progress through
+// them, opening on the first one not yet solved.
+func NewPuzzleSelectScene(sm *SceneManager) *PuzzleSelectScene {
+	titleFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	itemFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	helpFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	ps := &PuzzleSelectScene{
+		sceneManager: sm,
+		titleFont:    &text.GoTextFace{Source: titleFontSource, Size: 36},
+		itemFont:     &text.GoTextFace{Source: itemFontSource, Size: 20},
+		helpFont:     &text.GoTextFace{Source: helpFontSource, Size: 16},
+	}
+
+	builtin, err := loadBuiltinPuzzles()
+	user, userErr := loadUserPuzzles()
+	ps.puzzles = append(builtin, user...)
+	ps.builtin = len(builtin)
+	ps.err = errors.Join(err, userErr)
+	progress, err := loadPuzzleProgress()
+	if err != nil {
+		log.Printf("Warning: Could not load puzzle progress: %v", err)
+		progress = engine.NewPuzzleProgress()
+	}
+	ps.progress = progress
+
+	for i := range ps.puzzles {
+		if _, ok := progress.Solved[ps.progressKey(i)]; !ok {
+			ps.selected = i
+			break
+		}
+	}
+	return ps
+}
@@ -1,6 +1,13 @@
 package main
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"log"
+	"reflect"
+	"time"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
 
 type SceneType int
 
@@ -9,6 +16,15 @@ const (
 	SceneGame
 	SceneEndScreen
 	SceneHelp // Add help scene type
+	SceneSettings
+	SceneControls
+	SceneDemo
+	SceneVersus
+	SceneHost
+	SceneJoin
+	ScenePuzzleSelect
+	ScenePuzzle
+	SceneEditor
 )
 
 type Scene interface {
@@ -18,15 +34,43 @@ type Scene interface {
 }
 
 type SceneManager struct {
-	currentScene Scene
-	sceneType    SceneType
-	titleScene   *TitleScene
-	gameScene    *GameScene
-	endScene     *EndScene
-	helpScene    *HelpScene
+	currentScene  Scene
+	sceneType     SceneType
+	titleScene    *TitleScene
+	gameScene     *GameScene
+	demoScene     *GameScene
+	versusScene   *VersusScene
+	hostScene     *HostScene
+	joinScene     *JoinScene
+	puzzleSelect  *PuzzleSelectScene
+	puzzleScene   *GameScene
+	editorScene   *EditorScene
+	endScene      *EndScene
+	helpScene     *HelpScene
+	settingsScene *SettingsScene
+	controlsScene *ControlsScene
+	audioManager  *AudioManager
+	settings      *Settings
+	seed          int64
+	config        engine.Config
+	recordPath    string
+	// rules are the rules the command line fixed for the run.
+	rules ruleFlags
+	// windowSize is the size last asked of the window, so applying other
+	// settings doesn't undo the player resizing it by hand.
+	windowSize [2]int
 }
 
 func (sm *SceneManager) Update() error {
+	if ebiten.IsWindowBeingClosed() {
+		switch sm.currentScene {
+		case sm.gameScene:
+			sm.gameScene.saveProgress()
+		case sm.versusScene:
+			sm.versusScene.close()
+		}
+		return ebiten.Termination
+	}
 	return sm.currentScene.Update()
 }
 
@@ -37,15 +81,30 @@ func (sm *SceneManager) Draw(screen *ebiten.Image) {
 func (sm *SceneManager) Layout(outerWidth, outerHeight int) (int, int) {
 	return sm.currentScene.Layout(outerWidth, outerHeight)
 }
-func NewSceneManager() *SceneManager {
+
+// NewSceneManager starts at the title screen. Games follow config; settings
+// holds the player's preferences, which the settings scene edits in place.
+func NewSceneManager(seed int64, config engine.Config, settings *Settings) *SceneManager {
 	sm := &SceneManager{
-		sceneType: SceneTitleScreen,
+		sceneType:  SceneTitleScreen,
+		seed:       seed,
+		config:     config,
+		settings:   settings,
+		windowSize: [2]int{settings.WindowWidth, settings.WindowHeight},
 	}
 
+	sm.audioManager = NewAudioManager()
+	if err := sm.audioManager.Initialize(); err != nil {
+		log.Printf("Warning: Could not initialize audio: %v", err)
+	}
+	sm.audioManager.SetVolumes(settings.MusicVolume, settings.EffectsVolume)
+
 	sm.titleScene = NewTitleScene(sm)
 	sm.gameScene = NewGameScene(sm)
-	sm.endScene = NewEndScene(sm, 0)
+	sm.endScene = NewEndScene(sm, GameResult{}, false)
 	sm.helpScene = NewHelpScene(sm)
+	sm.settingsScene = NewSettingsScene(sm)
+	sm.controlsScene = NewControlsScene(sm)
 
 	sm.currentScene = sm.titleScene
 
@@ -59,6 +118,8 @@ func (sm *SceneManager) TransitionTo(sceneType SceneType) {
 	case SceneTitleScreen:
 		sm.currentScene = sm.titleScene
 		sm.titleScene.prevHPressed = true
+		sm.titleScene.idleTicks = 0
+		sm.titleScene.canContinue = hasSavedGame()
 	case SceneGame:
 		sm.currentScene = sm.gameScene
 	case SceneEndScreen:
@@ -66,15 +127,183 @@ func (sm *SceneManager) TransitionTo(sceneType SceneType) {
 	case SceneHelp:
 		sm.currentScene = sm.helpScene
 		sm.helpScene.prevHPressed = true
+	case SceneSettings:
+		sm.currentScene = sm.settingsScene
+	case SceneControls:
+		sm.currentScene = sm.controlsScene
+	case SceneDemo:
+		sm.currentScene = sm.demoScene
+	case SceneVersus:
+		sm.currentScene = sm.versusScene
+	case SceneHost:
+		sm.currentScene = sm.hostScene
+	case SceneJoin:
+		sm.currentScene = sm.joinScene
+	case ScenePuzzleSelect:
+		sm.currentScene = sm.puzzleSelect
+	case ScenePuzzle:
+		sm.currentScene = sm.puzzleScene
+	case SceneEditor:
+		sm.currentScene = sm.editorScene
 	}
 }
 
-func (sm *SceneManager) TransitionToEndScreen(finalScore int) {
+// ApplySettings puts the current settings into effect: volumes and window
+// size at once, gameplay choices from the next game.
+func (sm *SceneManager) ApplySettings() {
+	s := sm.settings
+	sm.audioManager.SetVolumes(s.MusicVolume, s.EffectsVolume)
+	if size := [2]int{s.WindowWidth, s.WindowHeight}; size != sm.windowSize {
+		sm.windowSize = size
+		ebiten.SetWindowSize(s.WindowWidth, s.WindowHeight)
+	}
+
+	config := sm.rules.applyTo(s.ApplyTo(sm.config))
+	if !reflect.DeepEqual(config, sm.config) {
+		sm.config = config
+		// The game waiting behind the title screen was dealt under the
+		// old rules.
+		if sm.currentScene != sm.gameScene {
+			sm.gameScene = NewGameScene(sm)
+		}
+	}
+}
+
+// TransitionToEndScreen shows the result of a finished game. Only ranked
+// games are offered a place on the leaderboard.
+func (sm *SceneManager) TransitionToEndScreen(result GameResult, ranked bool) {
 	sm.sceneType = SceneEndScreen
-	sm.endScene = NewEndScene(sm, finalScore)
+	sm.endScene = NewEndScene(sm, result, ranked)
 	sm.currentScene = sm.endScene
 }
 
+// SetRuleFlags keeps the rules given on the command line in force over
+// the settings.
+func (sm *SceneManager) SetRuleFlags(rules ruleFlags) {
+	sm.rules = rules
+}
+
+// SetRecordPath makes every game played from now on save its replay to path
+// when it ends.
+func (sm *SceneManager) SetRecordPath(path string) {
+	sm.recordPath = path
+}
+
+// PlayReplay starts a game that is driven by r instead of the keyboard.
+func (sm *SceneManager) PlayReplay(r *engine.Replay) {
+	sm.gameScene = NewReplayGameScene(sm, r)
+	sm.TransitionTo(SceneGame)
+}
+
+// StartDemo starts an attract-mode game with the bot playing. The game the
+// title screen would start is left waiting.
+func (sm *SceneManager) StartDemo() {
+	sm.demoScene = NewDemoGameScene(sm)
+	sm.TransitionTo(SceneDemo)
+}
+
+// StartVersus starts a new two-player match on one screen.
+func (sm *SceneManager) StartVersus() {
+	sm.versusScene = NewVersusScene(sm)
+	sm.TransitionTo(SceneVersus)
+}
+
+// StartHosting waits for an opponent to join a networked match.
+func (sm *SceneManager) StartHosting() {
+	sm.hostScene = NewHostScene(sm)
+	sm.TransitionTo(SceneHost)
+}
+
+// StartJoining asks where to find a networked match to join.
+func (sm *SceneManager) StartJoining() {
+	sm.joinScene = NewJoinScene(sm)
+	sm.TransitionTo(SceneJoin)
+}
+
+// StartNetVersus plays a networked match once both players are connected.
+func (sm *SceneManager) StartNetVersus(session *engine.NetSession) {
+	sm.versusScene = NewNetVersusScene(sm, session)
+	sm.TransitionTo(SceneVersus)
+}
+
+// StartPuzzleSelect lists the puzzles to choose from.
+func (sm *SceneManager) StartPuzzleSelect() {
+	sm.puzzleSelect = NewPuzzleSelectScene(sm)
+	sm.TransitionTo(ScenePuzzleSelect)
+}
+
+// StartPuzzle plays p from the start, recording a solve under key. Like a
+// demo, it leaves the game the title screen would start waiting.
+func (sm *SceneManager) StartPuzzle(p *engine.Puzzle, key string) {
+	sm.puzzleScene = NewPuzzleGameScene(sm, p)
+	sm.puzzleScene.progressKey = key
+	sm.TransitionTo(ScenePuzzle)
+}
+
+// StartEditor opens the level editor on a copy of p, or on a new level if
+// p is nil.
+func (sm *SceneManager) StartEditor(p *engine.Puzzle) {
+	sm.editorScene = NewEditorScene(sm, p)
+	sm.TransitionTo(SceneEditor)
+}
+
+// TryPuzzle plays p as it stands in the editor, which the game goes back
+// to when it ends.
+func (sm *SceneManager) TryPuzzle(p *engine.Puzzle) {
+	sm.puzzleScene = NewPuzzleGameScene(sm, p)
+	sm.puzzleScene.trial = true
+	sm.TransitionTo(ScenePuzzle)
+}
+
+// LeavePuzzle goes back to where the puzzle just played was chosen: the
+// editor for a trial, otherwise the list of puzzles.
+func (sm *SceneManager) LeavePuzzle(trial bool) {
+	if trial && sm.editorScene != nil {
+		sm.TransitionTo(SceneEditor)
+		return
+	}
+	sm.StartPuzzleSelect()
+}
+
+// ContinueSavedGame resumes the game saved when the window was last closed
+// mid-game. A save that can't be resumed is discarded.
+func (sm *SceneManager) ContinueSavedGame() {
+	state, err := loadSavedGame()
+	if err == nil && state == nil {
+		return
+	}
+	var scene *GameScene
+	if err == nil {
+		scene, err = NewResumedGameScene(sm, state)
+	}
+	if err != nil {
+		log.Printf("Warning: Could not continue saved game: %v", err)
+		deleteSavedGame()
+		sm.titleScene.canContinue = false
+		return
+	}
+	sm.gameScene = scene
+	sm.TransitionTo(SceneGame)
+}
+
+func (sm *SceneManager) saveRecording(r *engine.Replay) {
+	if sm.recordPath == "" {
+		return
+	}
+	if err := engine.SaveReplay(sm.recordPath, r); err != nil {
+		log.Printf("Warning: Could not save replay: %v", err)
+	}
+}
+
 func (sm *SceneManager) GetCurrentSceneType() SceneType {
 	return sm.sceneType
 }
+
+// NextGameSeed returns the seed for the next game: the one given on the
+// command line, or a fresh one from the clock when none was given.
+func (sm *SceneManager) NextGameSeed() int64 {
+	if sm.seed != 0 {
+		return sm.seed
+	}
+	return time.Now().UnixNano()
+}
@@ -17,8 +17,11 @@ type ScorePopup struct {
 	Life    float64
 	MaxLife float64
 	Score   int
-	Alpha   float64
-	Scale   float64
+	// Label is shown in place of the score when set, for callouts such
+	// as "CHAIN x3".
+	Label string
+	Alpha float64
+	Scale float64
 }
 
 type ScorePopupSystem struct {
@@ -54,6 +57,12 @@ func (sps *ScorePopupSystem) AddScorePopup(x, y float64, score int) {
 	sps.popups = append(sps.popups, popup)
 }
 
+// AddLabelPopup floats a callout such as "CHAIN x3" up from x, y.
+func (sps *ScorePopupSystem) AddLabelPopup(x, y float64, label string) {
+	sps.AddScorePopup(x, y, 0)
+	sps.popups[len(sps.popups)-1].Label = label
+}
+
 func (sps *ScorePopupSystem) Update(dt float64) {
 	for i := len(sps.popups) - 1; i >= 0; i-- {
 		popup := &sps.popups[i]
@@ -102,6 +111,10 @@ func (sps *ScorePopupSystem) Draw(screen *ebiten.Image) {
 
 		alpha := uint8(popup.Alpha * 255)
 		textColor := color.RGBA{255, 255, 0, alpha}
+		if popup.Label != "" {
+			scoreText = popup.Label
+			textColor = color.RGBA{255, 150, 50, alpha}
+		}
 
 		if popup.Scale > 0.7 {
 			op := &text.DrawOptions{}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"union/engine"
+)
+
+// SettingsVersion is the version of the settings file. Bump it when a
+// setting changes meaning; new settings alone don't need it, as settings a
+// file predates keep their defaults.
+const SettingsVersion = 1
+
+// Settings are the player's preferences, kept in the config file and edited
+// from the settings scene.
+type Settings struct {
+	Version int `json:"version"`
+
+	MusicVolume   float64 `json:"musicVolume"`
+	EffectsVolume float64 `json:"effectsVolume"`
+
+	Handling engine.Handling `json:"handling"`
+
+	WindowWidth  int `json:"windowWidth"`
+	WindowHeight int `json:"windowHeight"`
+	// ShakeIntensity scales every screen shake; 0 turns shaking off.
+	ShakeIntensity float64     `json:"shakeIntensity"`
+	Particles      bool        `json:"particles"`
+	Blocks         BlockTuning `json:"blocks"`
+	// Timing is how long reactions, falls and storms take. Unlike Blocks
+	// it changes the rules, so it is only set by editing the file.
+	Timing engine.Timing `json:"timing"`
+
+	Randomizer engine.RandomizerKind `json:"randomizer"`
+	// Charges is how pieces are charged in any mode ModeCharges doesn't
+	// choose for.
+	Charges     engine.ChargeKind                     `json:"charges"`
+	ModeCharges map[engine.ModeKind]engine.ChargeKind `json:"modeCharges,omitempty"`
+	Preview     int                                   `json:"preview"`
+
+	Mode engine.ModeKind `json:"mode"`
+	// SprintGoal and UltraSeconds pick which sprint and ultra are played,
+	// from sprintGoals and ultraLimits.
+	SprintGoal   int `json:"sprintGoal"`
+	UltraSeconds int `json:"ultraSeconds"`
+
+	Controls Controls `json:"controls"`
+	// VersusControls are the two players' bindings in local versus.
+	VersusControls [2]Controls `json:"versusControls"`
+
+	// NetAddress is the host last joined over the network.
+	NetAddress string `json:"netAddress"`
+}
+
+// The sprints and ultras on offer, each with a leaderboard table of its own.
+var (
+	sprintGoals = []int{40, engine.DefaultSprintGoal, 200}
+	ultraLimits = []int{120, engine.DefaultUltraSeconds}
+)
+
+// nearestOption returns the option closest to v, so a hand-edited file
+// can't make up a sprint or ultra with no place on the leaderboard.
+func nearestOption(options []int, v int) int {
+	best := options[0]
+	for _, option := range options {
+		if abs(option-v) < abs(best-v) {
+			best = option
+		}
+	}
+	return best
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// modeName is how a mode is named on screen.
+func modeName(kind engine.ModeKind) string {
+	return strings.ToUpper(string(kind))
+}
+
+// modeSummary names the mode config plays, with its goal or time limit.
+func modeSummary(config engine.Config) string {
+	switch config.Mode {
+	case engine.SprintMode:
+		return fmt.Sprintf("%s %d", modeName(config.Mode), config.SprintGoal)
+	case engine.UltraMode:
+		return fmt.Sprintf("%s %s", modeName(config.Mode), engine.FormatTicks(config.UltraSeconds*engine.TicksPerSecond, false))
+	case "":
+		return modeName(engine.ClassicMode)
+	}
+	return modeName(config.Mode)
+}
+
+func DefaultSettings() Settings {
+	config := engine.DefaultConfig()
+	return Settings{
+		Version:        SettingsVersion,
+		MusicVolume:    BackgroundMusicVolume,
+		EffectsVolume:  SoundEffectVolume,
+		Handling:       config.Handling,
+		WindowWidth:    1200,
+		WindowHeight:   800,
+		ShakeIntensity: 1.0,
+		Particles:      true,
+		Blocks:         DefaultBlockTuning(),
+		Timing:         config.Timing,
+		Randomizer:     config.Randomizer,
+		Charges:        config.Charges,
+		Preview:        config.Preview,
+		Mode:           config.Mode,
+		SprintGoal:     config.SprintGoal,
+		UltraSeconds:   config.UltraSeconds,
+		Controls:       DefaultControls(),
+		VersusControls: DefaultVersusControls(),
+	}
+}
+
+// ApplyTo returns config with the gameplay settings in place.
+func (s *Settings) ApplyTo(config engine.Config) engine.Config {
+	config.Handling = s.Handling
+	config.Randomizer = s.Randomizer
+	config.Charges = s.ChargesFor(s.Mode)
+	config.Preview = s.Preview
+	config.Mode = s.Mode
+	config.SprintGoal = s.SprintGoal
+	config.UltraSeconds = s.UltraSeconds
+	config.Timing = s.Timing
+	return config
+}
+
+// ChargesFor returns how pieces are charged in games of mode.
+func (s *Settings) ChargesFor(mode engine.ModeKind) engine.ChargeKind {
+	if kind, ok := s.ModeCharges[mode]; ok {
+		return kind
+	}
+	return s.Charges
+}
+
+// SetChargesFor chooses how pieces are charged in games of mode.
+func (s *Settings) SetChargesFor(mode engine.ModeKind, kind engine.ChargeKind) {
+	if s.ModeCharges == nil {
+		s.ModeCharges = make(map[engine.ModeKind]engine.ChargeKind)
+	}
+	s.ModeCharges[mode] = kind
+}
+
+func WriteSettings(w io.Writer, s *Settings) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Limits on the handling settings, in ticks.
+const (
+	maxDelayTicks    = 30
+	maxRepeatTicks   = 20
+	maxSoftDropTicks = 10
+)
+
+// Limits on how hard the screen and blocks shake, and how fast a storm's
+// warning flickers. Volumes run from 0 to 1.
+const (
+	maxShakeIntensity   = 2.0
+	maxBlockShake       = 6.0
+	minWarningFrequency = 5.0
+	maxWarningFrequency = 40.0
+)
+
+// ReadSettings reads a settings file over the defaults and checks that the
+// gameplay choices are ones this build knows. Numbers out of range are
+// brought back within it. Actions missing from the file, such as ones added
+// since it was saved, keep their default bindings.
+func ReadSettings(r io.Reader) (*Settings, error) {
+	s := DefaultSettings()
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Version != SettingsVersion {
+		return nil, fmt.Errorf("unsupported settings version %d", s.Version)
+	}
+	if _, err := engine.ParseRandomizer(string(s.Randomizer)); err != nil {
+		return nil, err
+	}
+	if _, err := engine.ParseCharges(string(s.Charges)); err != nil {
+		return nil, err
+	}
+	for mode, kind := range s.ModeCharges {
+		if _, err := engine.ParseMode(string(mode)); err != nil {
+			return nil, err
+		}
+		if _, err := engine.ParseCharges(string(kind)); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := engine.ParseMode(string(s.Mode)); err != nil {
+		return nil, err
+	}
+	if err := s.Timing.Validate(); err != nil {
+		return nil, err
+	}
+	s.SprintGoal = nearestOption(sprintGoals, s.SprintGoal)
+	s.UltraSeconds = nearestOption(ultraLimits, s.UltraSeconds)
+	s.Preview = clampInt(s.Preview, engine.MinPreview, engine.MaxPreview)
+	s.MusicVolume = clampFloat(s.MusicVolume, 0, 1)
+	s.EffectsVolume = clampFloat(s.EffectsVolume, 0, 1)
+	s.ShakeIntensity = clampFloat(s.ShakeIntensity, 0, maxShakeIntensity)
+	s.Blocks.WobbleIntensity = clampFloat(s.Blocks.WobbleIntensity, 0, maxBlockShake)
+	s.Blocks.StormIntensity = clampFloat(s.Blocks.StormIntensity, 0, maxBlockShake)
+	s.Blocks.WarningIntensity = clampFloat(s.Blocks.WarningIntensity, 0, maxBlockShake)
+	s.Blocks.WarningFrequency = clampFloat(s.Blocks.WarningFrequency, minWarningFrequency, maxWarningFrequency)
+	s.Handling.DelayTicks = clampInt(s.Handling.DelayTicks, 1, maxDelayTicks)
+	s.Handling.RepeatTicks = clampInt(s.Handling.RepeatTicks, 1, maxRepeatTicks)
+	s.Handling.SoftDropTicks = clampInt(s.Handling.SoftDropTicks, 1, maxSoftDropTicks)
+	smallest, largest := windowSizes[0], windowSizes[len(windowSizes)-1]
+	s.WindowWidth = clampInt(s.WindowWidth, smallest[0], largest[0])
+	s.WindowHeight = clampInt(s.WindowHeight, smallest[1], largest[1])
+	return &s, nil
+}
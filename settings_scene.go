@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"slices"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// windowSizes are the window sizes the settings offer.
+var windowSizes = [][2]int{
+	{960, 640},
+	{1200, 800},
+	{1440, 960},
+	{1600, 1000},
+	{1920, 1080},
+}
+
+// The settings list's layout. Rows that don't fit between the title and
+// the key hint scroll.
+const (
+	settingsTop           = 110.0
+	settingsRowHeight     = 26.0
+	settingsSectionHeight = 32.0
+	settingsHintHeight    = 50.0
+)
+
+// settingItem is one row of the settings scene. Rows with adjust change a
+// setting with left and right; rows with activate act on Enter.
+type settingItem struct {
+	// section starts a new group of rows under this heading.
+	section  string
+	label    string
+	value    func(s *Settings) string
+	adjust   func(s *Settings, delta int)
+	activate func(ss *SettingsScene)
+}
+
+type SettingsScene struct {
+	sceneManager *SceneManager
+	titleFont    *text.GoTextFace
+	itemFont     *text.GoTextFace
+	helpFont     *text.GoTextFace
+	items        []settingItem
+	selected     int
+}
+
+func (ss *SettingsScene) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		ss.leave()
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		ss.selected = (ss.selected + len(ss.items) - 1) % len(ss.items)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		ss.selected = (ss.selected + 1) % len(ss.items)
+	}
+
+	item := ss.items[ss.selected]
+	delta := 0
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		delta--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		delta++
+	}
+	if delta != 0 && item.adjust != nil {
+		item.adjust(ss.sceneManager.settings, delta)
+		ss.sceneManager.ApplySettings()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && item.activate != nil {
+		item.activate(ss)
+	}
+	return nil
+}
+
+// leave saves the settings and returns to the title screen.
+func (ss *SettingsScene) leave() {
+	if err := saveSettings(ss.sceneManager.settings); err != nil {
+		log.Printf("Warning: Could not save settings: %v", err)
+	}
+	ss.sceneManager.TransitionTo(SceneTitleScreen)
+}
+
+func (ss *SettingsScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{10, 15, 25, 255})
+	w := screen.Bounds().Dx()
+
+	titleText := "SETTINGS"
+	titleBounds, _ := text.Measure(titleText, ss.titleFont, 0)
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64((w-int(titleBounds))/2), 40)
+	op.ColorScale.ScaleWithColor(color.RGBA{220, 220, 255, 255})
+	text.Draw(screen, titleText, ss.titleFont, op)
+
+	labelX := float64(w)/2 - 220
+	valueX := float64(w)/2 + 80
+	y := settingsTop
+	first, last := ss.visibleRows(float64(screen.Bounds().Dy()) - settingsTop - settingsHintHeight)
+	for i := first; i <= last; i++ {
+		item := ss.items[i]
+		if section := ss.sectionOf(i); item.section != "" || i == first {
+			y += 10
+			ss.drawText(screen, section, ss.helpFont, labelX-20, y, color.RGBA{150, 200, 255, 255})
+			y += 22
+		}
+
+		clr := color.Color(color.RGBA{180, 180, 200, 255})
+		if i == ss.selected {
+			clr = color.RGBA{255, 255, 100, 255}
+			ss.drawText(screen, ">", ss.itemFont, labelX-20, y, clr)
+		}
+		ss.drawText(screen, item.label, ss.itemFont, labelX, y, clr)
+		if item.value != nil {
+			ss.drawText(screen, "< "+item.value(ss.sceneManager.settings)+" >", ss.itemFont, valueX, y, clr)
+		}
+		y += settingsRowHeight
+	}
+
+	hint := "Up/Down: choose   Left/Right: change   Enter: select   Escape: back"
+	hintBounds, _ := text.Measure(hint, ss.helpFont, 0)
+	ss.drawText(screen, hint, ss.helpFont, float64((w-int(hintBounds))/2), y+20, color.RGBA{150, 150, 170, 255})
+}
+
+// visibleRows returns the first and last rows that fit in height, scrolled
+// as little as keeps the selected row in view. A list scrolled part way
+// through a section repeats its heading at the top.
+func (ss *SettingsScene) visibleRows(height float64) (first, last int) {
+	rowHeight := func(i int) float64 {
+		if ss.items[i].section != "" || i == first {
+			return settingsRowHeight + settingsSectionHeight
+		}
+		return settingsRowHeight
+	}
+	used := 0.0
+	for i := 0; i <= ss.selected; i++ {
+		used += rowHeight(i)
+	}
+	for used > height && first < ss.selected {
+		used -= rowHeight(first)
+		first++
+		if ss.items[first].section == "" {
+			used += settingsSectionHeight
+		}
+	}
+	last = ss.selected
+	for last+1 < len(ss.items) && used+rowHeight(last+1) <= height {
+		last++
+		used += rowHeight(last)
+	}
+	return first, last
+}
+
+// sectionOf returns the heading row i is listed under.
+func (ss *SettingsScene) sectionOf(i int) string {
+	for ; i > 0 && ss.items[i].section == ""; i-- {
+	}
+	return ss.items[i].section
+}
+
+func (ss *SettingsScene) drawText(screen *ebiten.Image, s string, font *text.GoTextFace, x, y float64, clr color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, font, op)
+}
+
+func (ss *SettingsScene) Layout(outerWidth, outerHeight int) (int, int) {
+	return outerWidth, outerHeight
+}
+
+func NewSettingsScene(sm *SceneManager) *SettingsScene {
+	titleFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	titleFont := &text.GoTextFace{
+		Source: titleFontSource,
+		Size:   36,
+	}
+
+	itemFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	itemFont := &text.GoTextFace{
+		Source: itemFontSource,
+		Size:   18,
+	}
+
+	helpFontSource, _ := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	helpFont := &text.GoTextFace{
+		Source: helpFontSource,
+		Size:   14,
+	}
+
+	return &SettingsScene{
+		sceneManager: sm,
+		titleFont:    titleFont,
+		itemFont:     itemFont,
+		helpFont:     helpFont,
+		items:        newSettingItems(),
+	}
+}
+
+func newSettingItems() []settingItem {
+	return []settingItem{
+		{
+			section: "AUDIO",
+			label:   "Music volume",
+			value:   func(s *Settings) string { return percent(s.MusicVolume) },
+			adjust:  func(s *Settings, d int) { s.MusicVolume = stepFloat(s.MusicVolume, d, 0.05, 0, 1) },
+		},
+		{
+			label:  "Effects volume",
+			value:  func(s *Settings) string { return percent(s.EffectsVolume) },
+			adjust: func(s *Settings, d int) { s.EffectsVolume = stepFloat(s.EffectsVolume, d, 0.1, 0, 1) },
+		},
+		{
+			section: "HANDLING",
+			label:   "Auto-repeat delay (DAS)",
+			value:   func(s *Settings) string { return ticks(s.Handling.DelayTicks) },
+			adjust:  func(s *Settings, d int) { s.Handling.DelayTicks = stepInt(s.Handling.DelayTicks, d, 1, maxDelayTicks) },
+		},
+		{
+			label: "Auto-repeat rate (ARR)",
+			value: func(s *Settings) string { return ticks(s.Handling.RepeatTicks) },
+			adjust: func(s *Settings, d int) {
+				s.Handling.RepeatTicks = stepInt(s.Handling.RepeatTicks, d, 1, maxRepeatTicks)
+			},
+		},
+		{
+			label: "Soft drop rate",
+			value: func(s *Settings) string { return ticks(s.Handling.SoftDropTicks) },
+			adjust: func(s *Settings, d int) {
+				s.Handling.SoftDropTicks = stepInt(s.Handling.SoftDropTicks, d, 1, maxSoftDropTicks)
+			},
+		},
+		{
+			section: "VISUALS",
+			label:   "Window size",
+			value:   func(s *Settings) string { return fmt.Sprintf("%dx%d", s.WindowWidth, s.WindowHeight) },
+			adjust:  adjustWindowSize,
+		},
+		{
+			label: "Screen shake",
+			value: func(s *Settings) string { return percent(s.ShakeIntensity) },
+			adjust: func(s *Settings, d int) {
+				s.ShakeIntensity = stepFloat(s.ShakeIntensity, d, 0.25, 0, maxShakeIntensity)
+			},
+		},
+		{
+			label:  "Particles",
+			value:  func(s *Settings) string { return onOff(s.Particles) },
+			adjust: func(s *Settings, d int) { s.Particles = !s.Particles },
+		},
+		{
+			label: "Wobble strength",
+			value: func(s *Settings) string { return fmt.Sprintf("%.1f", s.Blocks.WobbleIntensity) },
+			adjust: func(s *Settings, d int) {
+				s.Blocks.WobbleIntensity = stepFloat(s.Blocks.WobbleIntensity, d, 0.5, 0, maxBlockShake)
+			},
+		},
+		{
+			label: "Storm jitter",
+			value: func(s *Settings) string { return fmt.Sprintf("%.1f", s.Blocks.StormIntensity) },
+			adjust: func(s *Settings, d int) {
+				s.Blocks.StormIntensity = stepFloat(s.Blocks.StormIntensity, d, 0.5, 0, maxBlockShake)
+			},
+		},
+		{
+			label: "Storm warning shake",
+			value: func(s *Settings) string { return fmt.Sprintf("%.1f", s.Blocks.WarningIntensity) },
+			adjust: func(s *Settings, d int) {
+				s.Blocks.WarningIntensity = stepFloat(s.Blocks.WarningIntensity, d, 0.5, 0, maxBlockShake)
+			},
+		},
+		{
+			label: "Storm warning speed",
+			value: func(s *Settings) string { return fmt.Sprintf("%.0f", s.Blocks.WarningFrequency) },
+			adjust: func(s *Settings, d int) {
+				s.Blocks.WarningFrequency = stepFloat(s.Blocks.WarningFrequency, d, 5, minWarningFrequency, maxWarningFrequency)
+			},
+		},
+		{
+			section: "GAMEPLAY",
+			label:   "Mode",
+			value:   func(s *Settings) string { return modeName(s.Mode) },
+			adjust:  func(s *Settings, d int) { s.Mode = cycle(engine.ModeKinds, s.Mode, d) },
+		},
+		{
+			label: "Charges in this mode",
+			value: func(s *Settings) string { return string(s.ChargesFor(s.Mode)) },
+			adjust: func(s *Settings, d int) {
+				s.SetChargesFor(s.Mode, cycle(engine.ChargeKinds, s.ChargesFor(s.Mode), d))
+			},
+		},
+		{
+			label:  "Sprint goal",
+			value:  func(s *Settings) string { return fmt.Sprintf("%d blocks", s.SprintGoal) },
+			adjust: func(s *Settings, d int) { s.SprintGoal = cycle(sprintGoals, s.SprintGoal, d) },
+		},
+		{
+			label:  "Ultra time limit",
+			value:  func(s *Settings) string { return engine.FormatTicks(s.UltraSeconds*engine.TicksPerSecond, false) },
+			adjust: func(s *Settings, d int) { s.UltraSeconds = cycle(ultraLimits, s.UltraSeconds, d) },
+		},
+		{
+			label:  "Randomizer",
+			value:  func(s *Settings) string { return string(s.Randomizer) },
+			adjust: func(s *Settings, d int) { s.Randomizer = cycle(engine.RandomizerKinds, s.Randomizer, d) },
+		},
+		{
+			label:  "Preview pieces",
+			value:  func(s *Settings) string { return fmt.Sprintf("%d", s.Preview) },
+			adjust: func(s *Settings, d int) { s.Preview = stepInt(s.Preview, d, engine.MinPreview, engine.MaxPreview) },
+		},
+		{
+			section: "CONTROLS",
+			label:   "Rebind controls",
+			activate: func(ss *SettingsScene) {
+				ss.sceneManager.TransitionTo(SceneControls)
+			},
+		},
+		{
+			label: "Reset to defaults",
+			activate: func(ss *SettingsScene) {
+				*ss.sceneManager.settings = DefaultSettings()
+				ss.sceneManager.ApplySettings()
+			},
+		},
+		{
+			label:    "Back",
+			activate: (*SettingsScene).leave,
+		},
+	}
+}
+
+func adjustWindowSize(s *Settings, delta int) {
+	current := slices.Index(windowSizes, [2]int{s.WindowWidth, s.WindowHeight})
+	if current < 0 {
+		current = 1
+	}
+	next := windowSizes[(current+delta+len(windowSizes))%len(windowSizes)]
+	s.WindowWidth, s.WindowHeight = next[0], next[1]
+}
+
+// stepFloat moves v by delta steps, kept within lo and hi and rounded to the
+// step so repeated presses don't drift.
+func stepFloat(v float64, delta int, step, lo, hi float64) float64 {
+	v = math.Round((v+float64(delta)*step)/step) * step
+	return clampFloat(v, lo, hi)
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	return math.Min(math.Max(v, lo), hi)
+}
+
+func stepInt(v, delta, lo, hi int) int {
+	return clampInt(v+delta, lo, hi)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// cycle steps through options from current, wrapping at either end.
+func cycle[T comparable](options []T, current T, delta int) T {
+	i := slices.Index(options, current)
+	return options[((i+delta)%len(options)+len(options))%len(options)]
+}
+
+func percent(v float64) string {
+	return fmt.Sprintf("%.0f%%", v*100)
+}
+
+func ticks(n int) string {
+	return fmt.Sprintf("%d ticks", n)
+}
+
+func onOff(on bool) string {
+	if on {
+		return "On"
+	}
+	return "Off"
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"union/assets"
+	"union/engine"
+)
+
+const (
+	dataDirName         = "un-ion"
+	saveFileName        = "savegame.json"
+	leaderboardFileName = "leaderboard.json"
+	settingsFileName    = "settings.json"
+	progressFileName    = "puzzles.json"
+	userPuzzlesDirName  = "puzzles"
+)
+
+// dataPath returns where a file of ours lives under the user's config
+// directory, creating the directory if needed.
+func dataPath(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, dataDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames it
+// into place, so a crash mid-write never leaves a truncated file behind.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func saveGame(state *engine.SaveState) error {
+	path, err := dataPath(saveFileName)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := engine.WriteSave(&buf, state); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// loadSavedGame returns the saved game, or nil if there is none.
+func loadSavedGame() (*engine.SaveState, error) {
+	path, err := dataPath(saveFileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return engine.ReadSave(f)
+}
+
+func hasSavedGame() bool {
+	path, err := dataPath(saveFileName)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+func deleteSavedGame() error {
+	path, err := dataPath(saveFileName)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// loadLeaderboard returns the saved leaderboard, or an empty one if none has
+// been saved yet.
+func loadLeaderboard() (*engine.Leaderboard, error) {
+	path, err := dataPath(leaderboardFileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return engine.NewLeaderboard(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return engine.ReadLeaderboard(f)
+}
+
+func saveLeaderboard(l *engine.Leaderboard) error {
+	path, err := dataPath(leaderboardFileName)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := engine.WriteLeaderboard(&buf, l); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// loadSettings returns the saved settings, or the defaults if none have been
+// saved yet.
+func loadSettings() (*Settings, error) {
+	path, err := dataPath(settingsFileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		settings := DefaultSettings()
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSettings(f)
+}
+
+func saveSettings(s *Settings) error {
+	path, err := dataPath(settingsFileName)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := WriteSettings(&buf, s); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// loadPuzzleProgress returns the puzzles solved so far, or no progress if
+// none has been saved yet.
+func loadPuzzleProgress() (*engine.PuzzleProgress, error) {
+	path, err := dataPath(progressFileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return engine.NewPuzzleProgress(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return engine.ReadPuzzleProgress(f)
+}
+
+func savePuzzleProgress(pp *engine.PuzzleProgress) error {
+	path, err := dataPath(progressFileName)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := engine.WritePuzzleProgress(&buf, pp); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// userProgressPrefix starts the progress keys of levels made in the editor,
+// so one named like a built-in level keeps a solve record of its own.
+const userProgressPrefix = "user/"
+
+// puzzleProgressKey returns what p's solve record is kept under; user
+// marks a level from the editor.
+func puzzleProgressKey(p *engine.Puzzle, user bool) string {
+	if user {
+		return userProgressPrefix + p.Name
+	}
+	return p.Name
+}
+
+// loadBuiltinPuzzles returns the puzzles that ship with the game, in the
+// order they are meant to be played.
+func loadBuiltinPuzzles() ([]*engine.Puzzle, error) {
+	return readPuzzles(assets.Puzzles)
+}
+
+// userPuzzleDir returns where levels made in the editor are kept, creating
+// it if needed.
+func userPuzzleDir() (string, error) {
+	dir, err := dataPath(userPuzzlesDirName)
+	if err != nil {
+		return "", err
+	}
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// loadUserPuzzles returns the levels saved from the editor, by file name.
+func loadUserPuzzles() ([]*engine.Puzzle, error) {
+	dir, err := userPuzzleDir()
+	if err != nil {
+		return nil, err
+	}
+	return readPuzzles(os.DirFS(dir))
+}
+
+// errPuzzleFileTaken is returned by saveUserPuzzle when p's file already
+// holds some other level, such as one whose name differs only in case or
+// punctuation.
+var errPuzzleFileTaken = errors.New("file holds another level")
+
+// saveUserPuzzle writes p to the editor's levels, in a file named after
+// it, and returns the file's path. It won't write over a different level
+// unless replace is set.
+func saveUserPuzzle(p *engine.Puzzle, replace bool) (string, error) {
+	dir, err := userPuzzleDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, puzzleFileName(p.Name))
+	if !replace {
+		if err := checkPuzzleFile(path, p.Name); err != nil {
+			return path, err
+		}
+	}
+	var buf bytes.Buffer
+	if err := engine.WritePuzzle(&buf, p); err != nil {
+		return "", err
+	}
+	return path, writeFileAtomic(path, buf.Bytes())
+}
+
+// checkPuzzleFile reports errPuzzleFileTaken if the file at path holds a
+// level not named name, or one that won't load.
+func checkPuzzleFile(path, name string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	other, err := engine.ReadPuzzle(f)
+	if err != nil {
+		return fmt.Errorf("%w that won't load", errPuzzleFileTaken)
+	}
+	if other.Name != name {
+		return fmt.Errorf("%w, %q", errPuzzleFileTaken, other.Name)
+	}
+	return nil
+}
+
+// puzzleFileName turns a puzzle's name into a file name: lower case, with
+// anything but letters and digits made a dash.
+func puzzleFileName(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, strings.TrimSpace(name))
+	return slug + ".json"
+}
+
+// readPuzzles reads every level in fsys. A level that won't load is
+// reported in the error, and the rest are still returned.
+func readPuzzles(fsys fs.FS) ([]*engine.Puzzle, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	var puzzles []*engine.Puzzle
+	var errs []error
+	for _, name := range names {
+		f, err := fsys.Open(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p, err := engine.ReadPuzzle(f)
+		f.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("puzzle %s: %w", name, err))
+			continue
+		}
+		puzzles = append(puzzles, p)
+	}
+	return puzzles, errors.Join(errs...)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"image/color"
+	"log"
+	"union/engine"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -17,13 +19,48 @@ type TitleScene struct {
 	helpFont     *text.GoTextFace
 	showHelp     bool
 	prevHPressed bool // for just-pressed logic
+	canContinue  bool // a saved game is waiting
+	// idleTicks counts updates without input, toward starting a demo.
+	idleTicks int
+
+	// scores is the leaderboard being shown, or nil while the title is.
+	scores *engine.Leaderboard
+	// scoresConfig picks the table shown, by its mode.
+	scoresConfig    engine.Config
+	leaderboardView *LeaderboardView
 }
 
 func (t *TitleScene) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{10, 15, 25, 255})
+	if t.scores != nil {
+		t.drawLeaderboard(screen)
+		return
+	}
 	t.drawTitleScreen(screen)
 }
 
+func (t *TitleScene) drawLeaderboard(screen *ebiten.Image) {
+	w := screen.Bounds().Dx()
+
+	titleText := "HIGH SCORES: " + modeSummary(t.scoresConfig)
+	titleBounds, _ := text.Measure(titleText, t.titleFont, 0)
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64((w-int(titleBounds))/2), 60)
+	op.ColorScale.ScaleWithColor(color.RGBA{220, 220, 255, 255})
+	text.Draw(screen, titleText, t.titleFont, op)
+
+	table := engine.LeaderboardTable(t.scoresConfig)
+	entries := t.scores.Entries(table)
+	t.leaderboardView.Draw(screen, t.scores, table, -1, 150)
+
+	backText := "Left/Right for other modes, L or Escape to go back"
+	backBounds, _ := text.Measure(backText, t.subtitleFont, 0)
+	op2 := &text.DrawOptions{}
+	op2.GeoM.Translate(float64((w-int(backBounds))/2), float64(190+max(len(entries), 1)*22))
+	op2.ColorScale.ScaleWithColor(color.RGBA{180, 180, 200, 255})
+	text.Draw(screen, backText, t.subtitleFont, op2)
+}
+
 func (t *TitleScene) drawTitleScreen(screen *ebiten.Image) {
 	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
 
@@ -49,21 +86,64 @@ func (t *TitleScene) drawTitleScreen(screen *ebiten.Image) {
 	op2.ColorScale.ScaleWithColor(color.RGBA{180, 180, 200, 255})
 	text.Draw(screen, subtitleText, t.subtitleFont, op2)
 
+	// Draw the mode the next game is played in
+	modeText := "Mode: " + modeSummary(t.sceneManager.config) + "  (M to change)"
+	modeBounds, _ := text.Measure(modeText, t.subtitleFont, 0)
+	promptY := subtitleY + 40
+
+	opMode := &text.DrawOptions{}
+	opMode.GeoM.Translate(float64((w-int(modeBounds))/2), float64(promptY))
+	opMode.ColorScale.ScaleWithColor(color.RGBA{255, 200, 100, 255})
+	text.Draw(screen, modeText, t.subtitleFont, opMode)
+
+	// Draw continue prompt
+	if t.canContinue {
+		continueText := "Press C to Continue"
+		continueBounds, _ := text.Measure(continueText, t.subtitleFont, 0)
+		continueX := (w - int(continueBounds)) / 2
+		promptY += 40
+
+		opContinue := &text.DrawOptions{}
+		opContinue.GeoM.Translate(float64(continueX), float64(promptY))
+		opContinue.ColorScale.ScaleWithColor(color.RGBA{150, 255, 150, 255})
+		text.Draw(screen, continueText, t.subtitleFont, opContinue)
+	}
+
 	// Draw help prompt
 	helpPrompt := "Press H for Help"
 	helpPromptBounds, _ := text.Measure(helpPrompt, t.subtitleFont, 0)
 	helpPromptX := (w - int(helpPromptBounds)) / 2
-	helpPromptY := subtitleY + 50 // More space from subtitle
+	helpPromptY := promptY + 50 // More space from subtitle
 
 	op3 := &text.DrawOptions{}
 	op3.GeoM.Translate(float64(helpPromptX), float64(helpPromptY))
 	op3.ColorScale.ScaleWithColor(color.RGBA{255, 255, 100, 255})
 	text.Draw(screen, helpPrompt, t.subtitleFont, op3)
 
+	scoresPrompt := "Press L for High Scores, O for Settings, V for Versus, P for Puzzles"
+	scoresPromptBounds, _ := text.Measure(scoresPrompt, t.subtitleFont, 0)
+	helpPromptY += 40
+
+	opScores := &text.DrawOptions{}
+	opScores.GeoM.Translate(float64((w-int(scoresPromptBounds))/2), float64(helpPromptY))
+	opScores.ColorScale.ScaleWithColor(color.RGBA{150, 200, 255, 255})
+	text.Draw(screen, scoresPrompt, t.subtitleFont, opScores)
+
+	netPrompt := "Online versus: N to Host, J to Join"
+	netPromptBounds, _ := text.Measure(netPrompt, t.subtitleFont, 0)
+	helpPromptY += 40
+
+	opNet := &text.DrawOptions{}
+	opNet.GeoM.Translate(float64((w-int(netPromptBounds))/2), float64(helpPromptY))
+	opNet.ColorScale.ScaleWithColor(color.RGBA{150, 200, 255, 255})
+	text.Draw(screen, netPrompt, t.subtitleFont, opNet)
+
+	bindings := t.sceneManager.settings.Controls
 	controls := []string{
 		"Quick Controls:",
-		"WASD/Arrow Keys: Move piece",
-		"Space: Rotate piece",
+		bindings.DescribeKeys(ActionMoveLeft) + ", " + bindings.DescribeKeys(ActionMoveRight) + ": Move piece",
+		bindings.DescribeKeys(ActionRotateCW) + ": Rotate piece",
+		bindings.DescribeKeys(ActionHardDrop) + ": Hard drop",
 	}
 
 	helpStartY := helpPromptY + 40
@@ -83,20 +163,79 @@ func (t *TitleScene) drawTitleScreen(screen *ebiten.Image) {
 	}
 }
 
+// attractDelayTicks is how long the title screen waits for input before
+// the bot starts a demo.
+const attractDelayTicks = 20 * engine.TicksPerSecond
+
 func (t *TitleScene) Update() error {
+	if t.idle() {
+		t.idleTicks++
+	} else {
+		t.idleTicks = 0
+	}
+	if t.scores == nil && t.idleTicks >= attractDelayTicks {
+		t.sceneManager.StartDemo()
+		return nil
+	}
+
+	if t.scores != nil {
+		if inpututil.IsKeyJustPressed(ebiten.KeyL) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			t.scores = nil
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+			t.scoresConfig.Mode = cycle(engine.ModeKinds, t.scoresConfig.Mode, -1)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+			t.scoresConfig.Mode = cycle(engine.ModeKinds, t.scoresConfig.Mode, 1)
+		}
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		t.showLeaderboard()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		t.cycleMode()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		t.sceneManager.TransitionTo(SceneSettings)
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		t.sceneManager.StartVersus()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		t.sceneManager.StartPuzzleSelect()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		t.sceneManager.StartHosting()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		t.sceneManager.StartJoining()
+		return nil
+	}
+
 	hPressed := ebiten.IsKeyPressed(ebiten.KeyH)
 	if hPressed && !t.prevHPressed {
 		t.sceneManager.TransitionTo(SceneHelp)
 	}
 	t.prevHPressed = hPressed
 
-	if ebiten.IsKeyPressed(ebiten.KeySpace) ||
-		ebiten.IsKeyPressed(ebiten.KeyEnter) ||
-		ebiten.IsKeyPressed(ebiten.KeyEscape) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyA) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyS) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyD) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyW) {
+	if t.canContinue && inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		t.sceneManager.ContinueSavedGame()
+		return nil
+	}
+
+	// Just-pressed, so the Escape or Enter that left a menu for the title
+	// doesn't also start a game.
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEnter) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEscape) ||
+		t.sceneManager.settings.Controls.AnyJustPressed() {
 		t.sceneManager.TransitionTo(SceneGame)
 		return nil
 	}
@@ -110,6 +249,35 @@ func (t *TitleScene) Update() error {
 	return nil
 }
 
+// idle reports whether nothing is being pressed.
+func (t *TitleScene) idle() bool {
+	return len(inpututil.AppendPressedKeys(nil)) == 0 &&
+		!ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) &&
+		!ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) &&
+		!t.sceneManager.settings.Controls.AnyJustPressed()
+}
+
+// showLeaderboard opens on the table for the mode being played.
+func (t *TitleScene) showLeaderboard() {
+	scores, err := loadLeaderboard()
+	if err != nil {
+		log.Printf("Warning: Could not load leaderboard: %v", err)
+		return
+	}
+	t.scores = scores
+	t.scoresConfig = t.sceneManager.config
+}
+
+// cycleMode moves on to the next mode and keeps it for next time.
+func (t *TitleScene) cycleMode() {
+	settings := t.sceneManager.settings
+	settings.Mode = cycle(engine.ModeKinds, settings.Mode, 1)
+	t.sceneManager.ApplySettings()
+	if err := saveSettings(settings); err != nil {
+		log.Printf("Warning: Could not save settings: %v", err)
+	}
+}
+
 func (t *TitleScene) Layout(outerWidth, outerHeight int) (int, int) {
 	return outerWidth, outerHeight
 }
@@ -134,10 +302,12 @@ func NewTitleScene(sm *SceneManager) *TitleScene {
 	}
 
 	return &TitleScene{
-		sceneManager: sm,
-		titleFont:    titleFont,
-		subtitleFont: subtitleFont,
-		helpFont:     helpFont,
-		showHelp:     false,
+		sceneManager:    sm,
+		titleFont:       titleFont,
+		subtitleFont:    subtitleFont,
+		helpFont:        helpFont,
+		showHelp:        false,
+		canContinue:     hasSavedGame(),
+		leaderboardView: NewLeaderboardView(),
 	}
 }
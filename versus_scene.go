@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"time"
+	"union/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// versusLabelHeight is the strip above each board kept for the player's
+// name.
+const versusLabelHeight = 30
+
+// versusWaitTicks is how long the opponent's input can be late before the
+// scene says it is waiting for them.
+const versusWaitTicks = engine.TicksPerSecond / 2
+
+// VersusScene is a two-player match, each player with their own board:
+// both on this machine with their own bindings, or one here and one across
+// the network. Versus games are neither saved nor scored.
+type VersusScene struct {
+	sceneManager *SceneManager
+	match        *engine.Versus
+	fields       [2]*PlayField
+	inputs       [2]*InputHandler
+	// pauseController pauses the first player's game; the second follows
+	// it, so either player can pause both.
+	pauseController *PauseController
+	lastUpdateTime  time.Time
+
+	// session is set for a networked match, where only the local board is
+	// played from here, with the solo bindings.
+	session *engine.NetSession
+	// netErr is why a networked match stopped early.
+	netErr error
+	// waitingTicks counts updates spent waiting on the opponent's input.
+	waitingTicks int
+}
+
+func NewVersusScene(sm *SceneManager) *VersusScene {
+	return newVersusScene(sm, engine.NewVersus(sm.NextGameSeed(), sm.config))
+}
+
+// NewNetVersusScene plays session's match against the opponent on the
+// other end of it.
+func NewNetVersusScene(sm *SceneManager, session *engine.NetSession) *VersusScene {
+	vs := newVersusScene(sm, session.Match)
+	vs.session = session
+	vs.inputs[session.Local] = NewInputHandler(sm.settings)
+	session.Step = vs.step
+	return vs
+}
+
+func newVersusScene(sm *SceneManager, match *engine.Versus) *VersusScene {
+	vs := &VersusScene{
+		sceneManager:   sm,
+		match:          match,
+		lastUpdateTime: time.Now(),
+	}
+	for i, game := range vs.match.Games {
+		c := NewGameComponents(game, sm.audioManager, sm.settings)
+		vs.fields[i] = newPlayField(c)
+		vs.fields[i].versus = true
+		vs.inputs[i] = NewPlayerInputHandler(sm.settings, i)
+		if i == 0 {
+			vs.pauseController = c.PauseController
+		}
+	}
+	vs.pauseController.inputs = vs.inputs[:]
+	sm.audioManager.StartBackgroundMusic()
+	return vs
+}
+
+func (vs *VersusScene) Update() error {
+	now := time.Now()
+	dt := now.Sub(vs.lastUpdateTime).Seconds()
+	vs.lastUpdateTime = now
+	for _, field := range vs.fields {
+		field.updateVisualEffects(dt)
+	}
+	if vs.session != nil {
+		vs.updateNet()
+		return nil
+	}
+
+	if vs.match.Over() {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			vs.sceneManager.StartVersus()
+			return nil
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			vs.sceneManager.TransitionTo(SceneTitleScreen)
+			return nil
+		}
+	}
+
+	var inputs [2]engine.Input
+	for i, field := range vs.fields {
+		inputs[i] = vs.inputs[i].Input(field.gameLogic.View())
+		// A match is quit from the pause screen rather than resigned by one
+		// side.
+		inputs[i].Resign = false
+	}
+
+	if !vs.match.Over() {
+		vs.pauseController.Update(inputs[0].Pause || inputs[1].Pause)
+		vs.fields[1].gameState.IsPaused = vs.fields[0].gameState.IsPaused
+	}
+	paused := vs.fields[0].gameState.IsPaused
+	if paused && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		vs.sceneManager.audioManager.ResumeBackgroundMusic()
+		vs.sceneManager.TransitionTo(SceneTitleScreen)
+		return nil
+	}
+
+	if paused || vs.match.Over() {
+		return nil
+	}
+	vs.step(inputs)
+	return nil
+}
+
+// updateNet plays the local board and waits on the opponent for theirs.
+func (vs *VersusScene) updateNet() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) ||
+		(vs.match.Over() || vs.netErr != nil) && inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		vs.close()
+		vs.sceneManager.TransitionTo(SceneTitleScreen)
+		return
+	}
+	if vs.match.Over() || vs.netErr != nil {
+		return
+	}
+
+	local := vs.session.Local
+	advanced, err := vs.session.Update(vs.inputs[local].Input(vs.fields[local].gameLogic.View()))
+	if err != nil {
+		log.Printf("Warning: Network match stopped: %v", err)
+		vs.netErr = err
+	}
+	if advanced {
+		vs.waitingTicks = 0
+	} else {
+		vs.waitingTicks++
+	}
+}
+
+// step plays a tick on both boards. The games are stepped through
+// GameLogic rather than match.Step so each publishes its events for its
+// own effects.
+func (vs *VersusScene) step(inputs [2]engine.Input) {
+	now := time.Now()
+	for i, field := range vs.fields {
+		field.step(inputs[i], now)
+	}
+	vs.match.Exchange()
+}
+
+// close hangs up a networked match.
+func (vs *VersusScene) close() {
+	if vs.session != nil {
+		vs.session.Close()
+	}
+}
+
+func (vs *VersusScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{15, 20, 30, 255})
+	for i, field := range vs.fields {
+		field.Draw(screen)
+		field.renderer.RenderLabel(screen, vs.playerName(i), float64(field.gameboard.X), 5)
+	}
+
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	switch {
+	case vs.netErr != nil:
+		vs.drawOverlay(screen, "CONNECTION LOST", vs.netErr.Error()+"   Escape: title")
+	case vs.match.Over():
+		vs.drawOverlay(screen, vs.result(), vs.resultHint())
+	case vs.session != nil:
+		if vs.waitingTicks >= versusWaitTicks {
+			drawCentredText(screen, "Waiting for opponent...", pauseSubtitleFont, w/2, h/2, color.RGBA{200, 200, 200, 255})
+		}
+	default:
+		vs.pauseController.Draw(screen)
+		if vs.fields[0].gameState.IsPaused {
+			drawCentredText(screen, "Escape to quit", pauseSubtitleFont, w/2, h/2+80, color.RGBA{200, 200, 200, 255})
+		}
+	}
+}
+
+func (vs *VersusScene) playerName(i int) string {
+	if vs.session == nil {
+		return fmt.Sprintf("PLAYER %d", i+1)
+	}
+	if i == vs.session.Local {
+		return "YOU"
+	}
+	return "OPPONENT"
+}
+
+func (vs *VersusScene) result() string {
+	winner := vs.match.Winner()
+	switch {
+	case winner < 0:
+		return "DRAW"
+	case vs.session == nil:
+		return fmt.Sprintf("PLAYER %d WINS", winner+1)
+	case winner == vs.session.Local:
+		return "YOU WIN"
+	}
+	return "YOU LOSE"
+}
+
+// resultHint offers a rematch on one machine. Online, both players would
+// have to agree, so they go back to the title and host again.
+func (vs *VersusScene) resultHint() string {
+	if vs.session != nil {
+		return "Escape: title"
+	}
+	return "Enter: rematch   Escape: title"
+}
+
+// drawOverlay dims both boards under a message.
+func (vs *VersusScene) drawOverlay(screen *ebiten.Image, title, hint string) {
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	overlay := ebiten.NewImage(w, h)
+	overlay.Fill(color.RGBA{0, 0, 0, 128})
+	screen.DrawImage(overlay, nil)
+
+	drawCentredText(screen, title, pauseTitleFont, w/2, h/2-30, color.RGBA{255, 255, 100, 255})
+	drawCentredText(screen, hint, pauseSubtitleFont, w/2, h/2+40, color.RGBA{200, 200, 200, 255})
+}
+
+// drawCentredText draws s centred on x with its top at y.
+func drawCentredText(screen *ebiten.Image, s string, font *text.GoTextFace, x, y int, clr color.Color) {
+	advance, _ := text.Measure(s, font, 0)
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x)-advance/2, float64(y))
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, font, op)
+}
+
+// Layout gives each player half the window, below a strip for their name.
+func (vs *VersusScene) Layout(outerWidth, outerHeight int) (int, int) {
+	half := outerWidth / 2
+	vs.fields[0].gameboard.UpdateScaleIn(image.Rect(0, versusLabelHeight, half, outerHeight))
+	vs.fields[1].gameboard.UpdateScaleIn(image.Rect(half, versusLabelHeight, outerWidth, outerHeight))
+	return outerWidth, outerHeight
+}